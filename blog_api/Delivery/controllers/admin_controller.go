@@ -3,6 +3,9 @@ package controllers
 import (
 	"blog_api/Delivery/dtos"
 	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"math"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,10 +13,24 @@ import (
 
 type AdminController struct {
 	adminUseCase contracts_usecases.IAdminUseCase
+	auditUseCase contracts_usecases.IAuditUseCase
+	loginGuard   contracts_usecases.ILoginGuardUseCase
 }
 
-func NewAdminController(adminUseCase contracts_usecases.IAdminUseCase) *AdminController {
-	return &AdminController{adminUseCase: adminUseCase}
+func NewAdminController(adminUseCase contracts_usecases.IAdminUseCase, auditUseCase contracts_usecases.IAuditUseCase, loginGuard contracts_usecases.ILoginGuardUseCase) *AdminController {
+	return &AdminController{adminUseCase: adminUseCase, auditUseCase: auditUseCase, loginGuard: loginGuard}
+}
+
+// records a successful action on a user with its before/after state
+func (ac *AdminController) auditUserAction(c *gin.Context, action, targetUserID string, before map[string]interface{}, details map[string]interface{}) {
+	recordAudit(ac.auditUseCase, c, models.AuditEntry{
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		TargetID:   targetUserID,
+		Before:     before,
+		After:      ac.auditUseCase.SnapshotUser(targetUserID),
+		Details:    details,
+	})
 }
 
 
@@ -21,6 +38,7 @@ func (ac *AdminController) PromoteUser(c *gin.Context) {
 	adminID := c.GetString("user_id") 
 	targetUserID := c.Param("userID")
 
+	before := ac.auditUseCase.SnapshotUser(targetUserID)
 	err := ac.adminUseCase.PromoteUser(adminID, targetUserID)
 	if err != nil {
 		statusCode := http.StatusBadRequest
@@ -32,6 +50,7 @@ func (ac *AdminController) PromoteUser(c *gin.Context) {
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
 	}
+	ac.auditUserAction(c, models.AuditUserPromote, targetUserID, before, nil)
 	c.JSON(http.StatusOK, dtos.PromoteUserResponseDTO{
 		Message: "User promoted to admin successfully",
 		UserID:  targetUserID,
@@ -44,6 +63,7 @@ func (ac *AdminController) DemoteUser(c *gin.Context) {
 	adminID := c.GetString("user_id") 
 	targetUserID := c.Param("userID")
 
+	before := ac.auditUseCase.SnapshotUser(targetUserID)
 	err := ac.adminUseCase.DemoteUser(adminID, targetUserID)
 	if err != nil {
 		statusCode := http.StatusBadRequest
@@ -55,9 +75,167 @@ func (ac *AdminController) DemoteUser(c *gin.Context) {
 		c.JSON(statusCode, gin.H{"error": err.Error()})
 		return
 	}
+	ac.auditUserAction(c, models.AuditUserDemote, targetUserID, before, nil)
 	c.JSON(http.StatusOK, dtos.DemoteUserResponseDTO{
 		Message: "User demoted to user successfully",
 		UserID:  targetUserID,
 		NewRole: "user",
 	})
 }
+
+// sets a user's role to any non-admin role
+func (ac *AdminController) AssignRole(c *gin.Context) {
+	adminID := c.GetString("user_id")
+	targetUserID := c.Param("userID")
+
+	var assignDTO dtos.AssignRoleDTO
+	if err := c.ShouldBindJSON(&assignDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	before := ac.auditUseCase.SnapshotUser(targetUserID)
+	err := ac.adminUseCase.AssignRole(adminID, targetUserID, assignDTO.Role)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "only admins can change user roles" {
+			statusCode = http.StatusForbidden
+		} else if err.Error() == "acting admin not found" || err.Error() == "target user not found" || err.Error() == "role not found" {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+	ac.auditUserAction(c, models.AuditUserAssignRole, targetUserID, before, nil)
+	c.JSON(http.StatusOK, dtos.AssignRoleResponseDTO{
+		Message: "User role updated successfully",
+		UserID:  targetUserID,
+		NewRole: assignDTO.Role,
+	})
+}
+
+// lists users with search, filters and pagination
+func (ac *AdminController) ListUsers(c *gin.Context) {
+	var queryDTO dtos.AdminUserQueryDTO
+	if err := c.ShouldBindQuery(&queryDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	query := &models.UserQuery{
+		Search:         queryDTO.Search,
+		IsActive:       queryDTO.Active,
+		IncludeDeleted: queryDTO.IncludeDeleted,
+		Page:           queryDTO.Page,
+		PageSize:       queryDTO.PageSize,
+	}
+	users, total, err := ac.adminUseCase.ListUsers(query, queryDTO.Role)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "role not found" {
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]dtos.AdminUserResponseDTO, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, dtos.AdminUserResponseDTO{
+			ID:               u.ID,
+			Username:         u.Username,
+			Email:            u.Email,
+			FirstName:        u.FirstName,
+			LastName:         u.LastName,
+			RoleID:           u.RoleID,
+			IsActive:         u.IsActive,
+			EmailVerified:    u.EmailVerified,
+			TwoFactorEnabled: u.TwoFactorEnabled,
+			CreatedAt:        u.CreatedAt,
+			DeletedAt:        u.DeletedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"users": resp,
+		"pagination": dtos.AdminUserPaginationDTO{
+			TotalPages:  int(math.Ceil(float64(total) / float64(query.PageSize))),
+			CurrentPage: query.Page,
+			TotalUsers:  total,
+			PageSize:    query.PageSize,
+		},
+	})
+}
+
+func (ac *AdminController) DeactivateUser(c *gin.Context) {
+	targetUserID := c.Param("userID")
+	before := ac.auditUseCase.SnapshotUser(targetUserID)
+	if err := ac.adminUseCase.DeactivateUser(c.GetString("user_id"), targetUserID); err != nil {
+		c.JSON(userManagementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ac.auditUserAction(c, models.AuditUserDeactivate, targetUserID, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "User deactivated successfully", "user_id": targetUserID})
+}
+
+func (ac *AdminController) ReactivateUser(c *gin.Context) {
+	targetUserID := c.Param("userID")
+	before := ac.auditUseCase.SnapshotUser(targetUserID)
+	if err := ac.adminUseCase.ReactivateUser(c.GetString("user_id"), targetUserID); err != nil {
+		c.JSON(userManagementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ac.auditUserAction(c, models.AuditUserReactivate, targetUserID, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "User reactivated successfully", "user_id": targetUserID})
+}
+
+// lifts a lockout from repeated failed sign-ins or password reset requests
+func (ac *AdminController) UnlockUser(c *gin.Context) {
+	targetUserID := c.Param("userID")
+	if err := ac.loginGuard.Unlock(targetUserID); err != nil {
+		if errors.Is(err, contracts_usecases.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+	recordAudit(ac.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditUserUnlock,
+		TargetType: models.AuditTargetUser,
+		TargetID:   targetUserID,
+	})
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully", "user_id": targetUserID})
+}
+
+// deletes a user; ?mode=hard removes their content, the default soft delete hides it
+func (ac *AdminController) DeleteUser(c *gin.Context) {
+	targetUserID := c.Param("userID")
+	mode := c.DefaultQuery("mode", "soft")
+	if mode != "soft" && mode != "hard" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be soft or hard"})
+		return
+	}
+
+	before := ac.auditUseCase.SnapshotUser(targetUserID)
+	if err := ac.adminUseCase.DeleteUser(c.GetString("user_id"), targetUserID, mode == "hard"); err != nil {
+		c.JSON(userManagementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ac.auditUserAction(c, models.AuditUserDelete, targetUserID, before, map[string]interface{}{"mode": mode})
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully", "user_id": targetUserID, "mode": mode})
+}
+
+func userManagementErrorStatus(err error) int {
+	switch err.Error() {
+	case "only admins can manage users":
+		return http.StatusForbidden
+	case "acting admin not found", "target user not found":
+		return http.StatusNotFound
+	case "admin cannot manage their own account", "demote the admin before managing their account",
+		"user is already deactivated", "user is already active", "user is already deleted",
+		"cannot reactivate a deleted user":
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
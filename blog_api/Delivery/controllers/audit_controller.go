@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditUseCase contracts_usecases.IAuditUseCase
+}
+
+func NewAuditController(auditUseCase contracts_usecases.IAuditUseCase) *AuditController {
+	return &AuditController{auditUseCase: auditUseCase}
+}
+
+// lists audit entries filtered by actor, target, action and time range
+func (ac *AuditController) ListEntries(c *gin.Context) {
+	query, err := bindAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, total, err := ac.auditUseCase.QueryEntries(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": toAuditResponses(entries),
+		"pagination": dtos.AuditPaginationDTO{
+			TotalPages:   int(math.Ceil(float64(total) / float64(query.PageSize))),
+			CurrentPage:  query.Page,
+			TotalEntries: total,
+			PageSize:     query.PageSize,
+		},
+	})
+}
+
+// exports matching audit entries as a CSV or JSON download
+func (ac *AuditController) ExportEntries(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+	query, err := bindAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := ac.auditUseCase.ExportEntries(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export audit log"})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=audit_log."+format)
+	if format == "json" {
+		c.JSON(http.StatusOK, toAuditResponses(entries))
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"id", "created_at", "actor_id", "action", "target_type", "target_id", "ip", "user_agent", "before", "after", "details"})
+	for _, e := range entries {
+		_ = w.Write([]string{
+			e.ID,
+			e.CreatedAt.UTC().Format(time.RFC3339),
+			e.ActorID,
+			e.Action,
+			e.TargetType,
+			e.TargetID,
+			e.IP,
+			e.UserAgent,
+			csvJSON(e.Before),
+			csvJSON(e.After),
+			csvJSON(e.Details),
+		})
+	}
+	w.Flush()
+}
+
+func bindAuditQuery(c *gin.Context) (*models.AuditQuery, error) {
+	var queryDTO dtos.AuditQueryDTO
+	if err := c.ShouldBindQuery(&queryDTO); err != nil {
+		return nil, errors.New("Invalid query parameters")
+	}
+
+	query := &models.AuditQuery{
+		ActorID:  queryDTO.Actor,
+		TargetID: queryDTO.Target,
+		Action:   queryDTO.Action,
+		Page:     queryDTO.Page,
+		PageSize: queryDTO.PageSize,
+	}
+	if queryDTO.From != "" {
+		from, err := time.Parse(time.RFC3339, queryDTO.From)
+		if err != nil {
+			return nil, errors.New("from must be an RFC3339 timestamp")
+		}
+		query.From = &from
+	}
+	if queryDTO.To != "" {
+		to, err := time.Parse(time.RFC3339, queryDTO.To)
+		if err != nil {
+			return nil, errors.New("to must be an RFC3339 timestamp")
+		}
+		query.To = &to
+	}
+	return query, nil
+}
+
+func toAuditResponses(entries []models.AuditEntry) []dtos.AuditEntryResponseDTO {
+	resp := make([]dtos.AuditEntryResponseDTO, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, dtos.AuditEntryResponseDTO{
+			ID:         e.ID,
+			ActorID:    e.ActorID,
+			Action:     e.Action,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			Before:     e.Before,
+			After:      e.After,
+			Details:    e.Details,
+			IP:         e.IP,
+			UserAgent:  e.UserAgent,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	return resp
+}
+
+func csvJSON(v map[string]interface{}) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// appends an audit entry for the current request; failures are logged, never surfaced
+func recordAudit(auditUseCase contracts_usecases.IAuditUseCase, c *gin.Context, entry models.AuditEntry) {
+	if entry.ActorID == "" {
+		entry.ActorID = c.GetString("user_id")
+	}
+	entry.IP = c.ClientIP()
+	entry.UserAgent = c.Request.UserAgent()
+	if err := auditUseCase.Record(&entry); err != nil {
+		log.Printf("failed to record audit entry %s: %v", entry.Action, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"blog_api/Domain/contracts/services"
 	usecases "blog_api/Domain/contracts/usecases"
 	"blog_api/Domain/models"
+	"errors"
 	"io"
 	"math"
 	"net/http"
@@ -72,6 +73,10 @@ func (bc *BlogController) CreateBlog(c *gin.Context) {
 
 
 	if err := bc.blogUseCase.CreateBlog(domainBlog,userID); err != nil {
+		if errors.Is(err, usecases.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create blog"})
 		return
 	}
@@ -138,7 +143,7 @@ func (bc *BlogController) DeleteBlogHandler(c *gin.Context){
 	blogID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	err := bc.blogUseCase.DeleteBlog(blogID,userID)
+	err := bc.blogUseCase.DeleteBlog(blogID,userID,c.GetString("role"))
 	if err != nil{
 		c.JSON(http.StatusBadGateway,gin.H{
 			"error":err.Error()})
@@ -198,3 +203,16 @@ func (ct *BlogController) DislikeBlog(c *gin.Context){
 
 
 }
+
+func (ct *BlogController) ShareBlog(c *gin.Context) {
+	err := ct.blogUseCase.ShareBlog(c.GetString("user_id"), c.Param("id"))
+	if errors.Is(err, usecases.ErrBlogNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share blog"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Blog shared successfully"})
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"blog_api/Delivery/dtos"
 	"blog_api/Domain/contracts/usecases"
+	"errors"
 	"log"
 	"net/http"
 
@@ -24,14 +25,22 @@ func (ct *CommentController) CreateComment(c *gin.Context){
 	userID := c.GetString("user_id")
 	blogID := c.Param("id")
 	var comment dtos.CommentDTO
-	log.Println("the userID and the blogID %s and %s ",userID,blogID)
+	log.Printf("the userID and the blogID %s and %s ",userID,blogID)
 
 	if err := c.ShouldBindJSON(&comment); err != nil{
 		c.JSON(http.StatusBadRequest,gin.H{"error":"Invalid request"})
 		return
 	}
 
-	err := ct.commentUseCase.CreateComment(blogID,userID,comment.Content)
+	err := ct.commentUseCase.CreateComment(blogID,userID,comment.Content,comment.ParentID)
+	if errors.Is(err, usecases.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden,gin.H{"error":err.Error()})
+		return
+	}
+	if errors.Is(err, usecases.ErrParentCommentNotFound) {
+		c.JSON(http.StatusNotFound,gin.H{"error":err.Error()})
+		return
+	}
 	if err != nil{
 		c.JSON(http.StatusBadRequest,gin.H{"error":err.Error()})
 		return
@@ -42,13 +51,17 @@ func (ct *CommentController) CreateComment(c *gin.Context){
 
 func (ct *CommentController) UpdateComment(c *gin.Context){
 	commentID := c.Param("id")
-	// userID := c.Param("userID")
+	userID := c.GetString("user_id")
 	var comment dtos.CommentDTO
 	if err := c.ShouldBindJSON(&comment); err != nil{
 		c.JSON(http.StatusBadRequest,gin.H{"error":"Invalid request"})
 		return
 	}
-	err := ct.commentUseCase.UpdateComment(commentID,comment.Content)
+	err := ct.commentUseCase.UpdateComment(commentID,userID,comment.Content)
+	if errors.Is(err, usecases.ErrNotCommentAuthor) {
+		c.JSON(http.StatusForbidden,gin.H{"error":err.Error()})
+		return
+	}
 	if err != nil{
 		c.JSON(http.StatusBadRequest,gin.H{"error":err.Error()})
 		return
@@ -60,7 +73,11 @@ func (ct *CommentController) UpdateComment(c *gin.Context){
 
 func (ct *CommentController) DeleteComment(c *gin.Context){
 	commentID := c.Param("id")
-	err := ct.commentUseCase.DeleteComment(commentID)
+	err := ct.commentUseCase.DeleteComment(commentID,c.GetString("user_id"),c.GetString("role"))
+	if errors.Is(err, usecases.ErrNotCommentAuthor) {
+		c.JSON(http.StatusForbidden,gin.H{"Error":err.Error()})
+		return
+	}
 	if err != nil{
 		c.JSON(http.StatusBadGateway,gin.H{"Error":err.Error()})
 		return
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DigestController struct {
+	digestUseCase contracts_usecases.IDigestUseCase
+}
+
+func NewDigestController(digestUseCase contracts_usecases.IDigestUseCase) *DigestController {
+	return &DigestController{digestUseCase: digestUseCase}
+}
+
+func (dc *DigestController) GetSettings(c *gin.Context) {
+	settings, err := dc.digestUseCase.GetSettings(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load digest settings"})
+		return
+	}
+	c.JSON(http.StatusOK, toDigestSettingsResponse(settings))
+}
+
+func (dc *DigestController) UpdateSettings(c *gin.Context) {
+	var req dtos.DigestSettingsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	settings, err := dc.digestUseCase.UpdateFrequency(c.GetString("user_id"), req.Frequency)
+	if err != nil {
+		c.JSON(digestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toDigestSettingsResponse(settings))
+}
+
+// shows what the next digest would contain
+func (dc *DigestController) Preview(c *gin.Context) {
+	digest, err := dc.digestUseCase.PreviewDigest(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build digest"})
+		return
+	}
+	c.JSON(http.StatusOK, dtos.DigestPreviewDTO{
+		Frequency:     digest.Frequency,
+		Since:         digest.Since,
+		FollowedPosts: toDigestItems(digest.FollowedPosts),
+		TrendingPosts: toDigestItems(digest.TrendingPosts),
+	})
+}
+
+// handles the emailed unsubscribe link; POST supports one-click unsubscribe from mail clients
+func (dc *DigestController) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+	if err := dc.digestUseCase.Unsubscribe(token); err != nil {
+		c.JSON(digestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "You have been unsubscribed from digest emails"})
+}
+
+func toDigestSettingsResponse(settings *models.DigestSettings) dtos.DigestSettingsResponseDTO {
+	return dtos.DigestSettingsResponseDTO{
+		Frequency:  settings.Frequency,
+		LastSentAt: settings.LastSentAt,
+	}
+}
+
+func toDigestItems(items []models.DigestItem) []dtos.DigestItemDTO {
+	out := make([]dtos.DigestItemDTO, 0, len(items))
+	for _, item := range items {
+		out = append(out, dtos.DigestItemDTO{
+			BlogID:       item.BlogID,
+			Title:        item.Title,
+			AuthorName:   item.AuthorName,
+			Tags:         item.Tags,
+			LikeCount:    item.LikeCount,
+			CommentCount: item.CommentCount,
+		})
+	}
+	return out
+}
+
+func digestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, contracts_usecases.ErrInvalidDigestFrequency), errors.Is(err, contracts_usecases.ErrInvalidUnsubscribeToken):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowController struct {
+	followUseCase contracts_usecases.IFollowUseCase
+}
+
+func NewFollowController(followUseCase contracts_usecases.IFollowUseCase) *FollowController {
+	return &FollowController{followUseCase: followUseCase}
+}
+
+func (fc *FollowController) Follow(c *gin.Context) {
+	if err := fc.followUseCase.FollowUser(c.GetString("user_id"), c.Param("userID")); err != nil {
+		c.JSON(followErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "You are now following this user"})
+}
+
+func (fc *FollowController) Unfollow(c *gin.Context) {
+	if err := fc.followUseCase.UnfollowUser(c.GetString("user_id"), c.Param("userID")); err != nil {
+		c.JSON(followErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "You unfollowed this user"})
+}
+
+func (fc *FollowController) GetFollowStats(c *gin.Context) {
+	followers, following, err := fc.followUseCase.GetFollowStats(c.Param("userID"))
+	if err != nil {
+		c.JSON(followErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"followers": followers, "following": following})
+}
+
+func (fc *FollowController) FollowTag(c *gin.Context) {
+	if err := fc.followUseCase.FollowTag(c.GetString("user_id"), c.Param("tag")); err != nil {
+		c.JSON(followErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "You are now following this tag"})
+}
+
+func (fc *FollowController) UnfollowTag(c *gin.Context) {
+	if err := fc.followUseCase.UnfollowTag(c.GetString("user_id"), c.Param("tag")); err != nil {
+		c.JSON(followErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "You unfollowed this tag"})
+}
+
+func (fc *FollowController) ListFollowedTags(c *gin.Context) {
+	tags, err := fc.followUseCase.ListFollowedTags(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load followed tags"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func followErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, contracts_usecases.ErrUserNotFound), errors.Is(err, contracts_usecases.ErrNotFollowing),
+		errors.Is(err, contracts_usecases.ErrNotFollowingTag):
+		return http.StatusNotFound
+	case errors.Is(err, contracts_usecases.ErrAlreadyFollowing), errors.Is(err, contracts_usecases.ErrAlreadyFollowingTag):
+		return http.StatusConflict
+	case errors.Is(err, contracts_usecases.ErrCannotFollowSelf), errors.Is(err, contracts_usecases.ErrInvalidTag):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	contracts_services "blog_api/Domain/contracts/services"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSController struct {
+	jwtService contracts_services.IJWTService
+}
+
+func NewJWKSController(jwtService contracts_services.IJWTService) *JWKSController {
+	return &JWKSController{jwtService: jwtService}
+}
+
+// publishes the public keys our tokens are signed with so other services can verify them
+func (jc *JWKSController) Keys(c *gin.Context) {
+	keys := jc.jwtService.PublicKeys()
+	response := dtos.JWKSResponseDTO{Keys: make([]dtos.JWKDTO, 0, len(keys))}
+	for _, k := range keys {
+		jwk := dtos.JWKDTO{KeyID: k.KeyID, Use: "sig", Algorithm: k.Algorithm}
+		if !k.NotBefore.IsZero() {
+			jwk.NotBefore = k.NotBefore.Unix()
+		}
+		switch public := k.Key.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		response.Keys = append(response.Keys, jwk)
+	}
+	// short enough that verifiers see a newly scheduled key well before it starts signing
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MagicLinkController struct {
+	magicLinkUseCase contracts_usecases.IMagicLinkUseCase
+	twoFactorUseCase contracts_usecases.ITwoFactorUseCase
+	tokenUseCase     contracts_usecases.ITokenUseCase
+	auditUseCase     contracts_usecases.IAuditUseCase
+}
+
+func NewMagicLinkController(magicLinkUseCase contracts_usecases.IMagicLinkUseCase, twoFactorUseCase contracts_usecases.ITwoFactorUseCase, tokenUseCase contracts_usecases.ITokenUseCase, auditUseCase contracts_usecases.IAuditUseCase) *MagicLinkController {
+	return &MagicLinkController{
+		magicLinkUseCase: magicLinkUseCase,
+		twoFactorUseCase: twoFactorUseCase,
+		tokenUseCase:     tokenUseCase,
+		auditUseCase:     auditUseCase,
+	}
+}
+
+// emails a one-time sign-in link
+func (mc *MagicLinkController) Request(c *gin.Context) {
+	var req dtos.MagicLinkRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := mc.magicLinkUseCase.RequestLink(req.Email); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(mc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditMagicLinkRequested,
+		TargetType: models.AuditTargetUser,
+		Details:    map[string]interface{}{"email": req.Email},
+	})
+	// Always the same answer to prevent email enumeration
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "If sign-in by email is available for this address, a link has been sent"})
+}
+
+// signs in with the token from the link, subject to two-factor authentication like a password login
+func (mc *MagicLinkController) Verify(c *gin.Context) {
+	var req dtos.MagicLinkVerifyDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	login, err := mc.magicLinkUseCase.ConsumeLink(req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, contracts_usecases.ErrInvalidMagicLink):
+			c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, contracts_usecases.ErrAccountDeactivated):
+			c.IndentedJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		}
+		return
+	}
+
+	challenge, err := mc.twoFactorUseCase.BeginLogin(login.User)
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor login"})
+		return
+	}
+	if challenge != nil {
+		c.IndentedJSON(http.StatusOK, toChallengeResponse(challenge))
+		return
+	}
+	if !startSession(c, mc.tokenUseCase, mc.auditUseCase, login.User, "") {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{
+		"message":     "Login successful",
+		"is_new_user": login.IsNewUser,
+	})
+}
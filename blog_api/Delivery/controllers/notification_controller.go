@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationUseCase contracts_usecases.INotificationUseCase
+}
+
+func NewNotificationController(notificationUseCase contracts_usecases.INotificationUseCase) *NotificationController {
+	return &NotificationController{notificationUseCase: notificationUseCase}
+}
+
+// lists the current user's notifications, newest first, with the unread count
+func (nc *NotificationController) ListNotifications(c *gin.Context) {
+	var queryDTO dtos.NotificationQueryDTO
+	if err := c.ShouldBindQuery(&queryDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	query := &models.NotificationQuery{
+		UnreadOnly: queryDTO.Unread,
+		Page:       queryDTO.Page,
+		PageSize:   queryDTO.PageSize,
+	}
+
+	notifications, total, unread, err := nc.notificationUseCase.ListNotifications(c.GetString("user_id"), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load notifications"})
+		return
+	}
+
+	items := make([]dtos.NotificationResponseDTO, 0, len(notifications))
+	for i := range notifications {
+		items = append(items, toNotificationResponse(&notifications[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": items,
+		"unread_count":  unread,
+		"pagination": dtos.NotificationPaginationDTO{
+			TotalPages:         int(math.Ceil(float64(total) / float64(query.PageSize))),
+			CurrentPage:        query.Page,
+			TotalNotifications: total,
+			PageSize:           query.PageSize,
+		},
+	})
+}
+
+func (nc *NotificationController) UnreadCount(c *gin.Context) {
+	unread, err := nc.notificationUseCase.UnreadCount(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+func (nc *NotificationController) MarkRead(c *gin.Context) {
+	if err := nc.notificationUseCase.MarkRead(c.GetString("user_id"), c.Param("id")); err != nil {
+		c.JSON(notificationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func (nc *NotificationController) MarkAllRead(c *gin.Context) {
+	updated, err := nc.notificationUseCase.MarkAllRead(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read", "updated": updated})
+}
+
+func (nc *NotificationController) GetPreferences(c *gin.Context) {
+	prefs, err := nc.notificationUseCase.GetPreferences(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"types": effectivePreferences(prefs)})
+}
+
+func (nc *NotificationController) UpdatePreferences(c *gin.Context) {
+	var req dtos.NotificationPreferencesDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	prefs, err := nc.notificationUseCase.UpdatePreferences(c.GetString("user_id"), req.Types)
+	if err != nil {
+		c.JSON(notificationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"types": effectivePreferences(prefs)})
+}
+
+// lists every notification type with whether it is enabled
+func effectivePreferences(prefs *models.NotificationPreferences) map[string]bool {
+	types := make(map[string]bool, len(models.NotificationTypes))
+	for _, t := range models.NotificationTypes {
+		types[t] = prefs.Enabled(t)
+	}
+	return types
+}
+
+func toNotificationResponse(n *models.Notification) dtos.NotificationResponseDTO {
+	return dtos.NotificationResponseDTO{
+		ID:        n.ID,
+		Type:      n.Type,
+		ActorID:   n.ActorID,
+		BlogID:    n.BlogID,
+		CommentID: n.CommentID,
+		Message:   n.Message,
+		Read:      n.Read,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+func notificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, contracts_usecases.ErrNotificationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, contracts_usecases.ErrInvalidNotificationType):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -3,34 +3,47 @@ package controllers
 import (
 	"blog_api/Delivery/dtos"
 	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// holds the signed OAuth flow between the login redirect and the callback
+const oauthFlowCookie = "oauth_flow"
+
 
 type OAuthController struct {
 	oauthUseCase contracts_usecases.IOAuthUseCase
+	auditUseCase contracts_usecases.IAuditUseCase
 }
 
-func NewOAuthController(oauthUseCase contracts_usecases.IOAuthUseCase) *OAuthController {
+func NewOAuthController(oauthUseCase contracts_usecases.IOAuthUseCase, auditUseCase contracts_usecases.IAuditUseCase) *OAuthController {
 	return &OAuthController{
 		oauthUseCase: oauthUseCase,
+		auditUseCase: auditUseCase,
 	}
 }
 
-// starts the OAuth flow for a specific provider
+// starts the OAuth flow for a specific provider; ?redirect= names where to send the browser after login
 func (oc *OAuthController) InitiateOAuthFlow(c *gin.Context) {
 	provider := c.Param("provider")
 
-	authURL, err := oc.oauthUseCase.InitiateOAuthFlow(provider)
+	authorization, err := oc.oauthUseCase.InitiateOAuthFlow(provider, c.Query("redirect"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Lax so the cookie survives the top-level redirect back from the provider
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthFlowCookie, authorization.FlowToken, int(time.Until(authorization.ExpiresAt).Seconds()), "/api/auth", "", true, true)
 	c.JSON(http.StatusOK, gin.H{
-		"auth_url": authURL,
+		"auth_url": authorization.AuthURL,
 		"provider": provider,
 	})
 }
@@ -40,15 +53,47 @@ func (oc *OAuthController) HandleOAuthCallback(c *gin.Context) {
 	provider := c.Param("provider")
 	code := c.Query("code")
 	state := c.Query("state")
+	flowToken, _ := c.Cookie(oauthFlowCookie)
+	// a flow is good for one callback, whatever its outcome
+	clearOAuthFlowCookie(c)
 
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sign-in was cancelled or refused by the provider: " + providerErr})
+		return
+	}
 	if code == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization code is required"})
 		return
 	}
 
-	result, err := oc.oauthUseCase.HandleOAuthCallback(provider, code, state)
+	result, err := oc.oauthUseCase.HandleOAuthCallback(provider, code, state, flowToken, sessionClient(c))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(oauthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if result.TwoFactor != nil {
+		if result.RedirectURL != "" {
+			// the fragment never reaches a server or a Referer header
+			c.Redirect(http.StatusFound, withFragment(result.RedirectURL, url.Values{
+				"two_factor_token": {result.TwoFactor.Token},
+				"two_factor_type":  {result.TwoFactor.Kind},
+			}))
+			return
+		}
+		c.JSON(http.StatusOK, toChallengeResponse(result.TwoFactor))
+		return
+	}
+	recordAudit(oc.auditUseCase, c, models.AuditEntry{
+		ActorID:    result.User.ID,
+		Action:     models.AuditLogin,
+		TargetType: models.AuditTargetUser,
+		TargetID:   result.User.ID,
+		Details:    map[string]interface{}{"provider": provider, "new_user": result.IsNewUser},
+	})
+	c.SetCookie("access_token", result.AccessToken, 900, "/", "", true, true)         // 15 min expiry, Secure & HttpOnly
+	c.SetCookie("refresh_token", result.RefreshToken, 7*24*3600, "/", "", true, true) // 7 days expiry, Secure & HttpOnly
+	if result.RedirectURL != "" {
+		c.Redirect(http.StatusFound, result.RedirectURL)
 		return
 	}
 
@@ -82,14 +127,94 @@ func (oc *OAuthController) LinkOAuthToExistingUser(c *gin.Context) {
 		return
 	}
 
-	err := oc.oauthUseCase.LinkOAuthToExistingUser(provider, linkDTO.Code, userID)
+	flowToken, _ := c.Cookie(oauthFlowCookie)
+	clearOAuthFlowCookie(c)
+
+	err := oc.oauthUseCase.LinkOAuthToExistingUser(provider, linkDTO.Code, linkDTO.State, flowToken, userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(oauthErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(oc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditOAuthLink,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Details:    map[string]interface{}{"provider": provider},
+	})
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "OAuth account linked successfully",
 		"provider": provider,
 	})
 }
+
+// lists the provider accounts linked to the signed-in user
+func (oc *OAuthController) ListLinkedAccounts(c *gin.Context) {
+	linked, err := oc.oauthUseCase.ListLinkedAccounts(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(oauthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	response := dtos.LinkedAccountsResponseDTO{
+		Accounts:    make([]dtos.LinkedAccountResponseDTO, 0, len(linked.Accounts)),
+		HasPassword: linked.HasPassword,
+	}
+	for _, account := range linked.Accounts {
+		response.Accounts = append(response.Accounts, dtos.LinkedAccountResponseDTO{
+			Provider: account.Provider,
+			Email:    account.Email,
+			Name:     account.Name,
+			Picture:  account.Picture,
+			LinkedAt: account.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// unlinks a provider from the signed-in user
+func (oc *OAuthController) UnlinkProvider(c *gin.Context) {
+	provider := c.Param("provider")
+	userID := c.GetString("user_id")
+
+	if err := oc.oauthUseCase.UnlinkProvider(userID, provider); err != nil {
+		c.JSON(oauthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(oc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditOAuthUnlink,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Details:    map[string]interface{}{"provider": provider},
+	})
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "OAuth account unlinked successfully",
+		"provider": provider,
+	})
+}
+
+func clearOAuthFlowCookie(c *gin.Context) {
+	c.SetCookie(oauthFlowCookie, "", -1, "/api/auth", "", true, true)
+}
+
+// appends values to the URL fragment, keeping any fragment the URL already had
+func withFragment(rawURL string, values url.Values) string {
+	if strings.Contains(rawURL, "#") {
+		return rawURL + "&" + values.Encode()
+	}
+	return rawURL + "#" + values.Encode()
+}
+
+func oauthErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, contracts_usecases.ErrInvalidOAuthState):
+		return http.StatusUnauthorized
+	case errors.Is(err, contracts_usecases.ErrOAuthAccountExists), errors.Is(err, contracts_usecases.ErrLastSignInMethod):
+		return http.StatusConflict
+	case errors.Is(err, contracts_usecases.ErrOAuthLinkNotFound), errors.Is(err, contracts_usecases.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, contracts_usecases.ErrAccountDeactivated):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
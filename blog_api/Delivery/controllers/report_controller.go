@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportController struct {
+	reportUseCase contracts_usecases.IReportUseCase
+	auditUseCase  contracts_usecases.IAuditUseCase
+}
+
+func NewReportController(reportUseCase contracts_usecases.IReportUseCase, auditUseCase contracts_usecases.IAuditUseCase) *ReportController {
+	return &ReportController{reportUseCase: reportUseCase, auditUseCase: auditUseCase}
+}
+
+func (rc *ReportController) ReportBlog(c *gin.Context) {
+	rc.report(c, models.ReportTargetBlog, c.Param("id"))
+}
+
+func (rc *ReportController) ReportComment(c *gin.Context) {
+	rc.report(c, models.ReportTargetComment, c.Param("id"))
+}
+
+func (rc *ReportController) ReportUser(c *gin.Context) {
+	rc.report(c, models.ReportTargetUser, c.Param("userID"))
+}
+
+func (rc *ReportController) report(c *gin.Context, targetType, targetID string) {
+	var req dtos.ReportDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if _, err := rc.reportUseCase.ReportContent(c.GetString("user_id"), targetType, targetID, req.Reason, req.Details); err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Thanks, a moderator will review your report"})
+}
+
+// lists moderation cases, the most reported first
+func (rc *ReportController) GetQueue(c *gin.Context) {
+	var queryDTO dtos.ModerationQueueQueryDTO
+	if err := c.ShouldBindQuery(&queryDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	status := queryDTO.Status
+	if status == "" {
+		status = models.ReportStatusOpen
+	} else if status == "all" {
+		status = ""
+	}
+
+	reports, total, err := rc.reportUseCase.GetModerationQueue(status, queryDTO.TargetType, queryDTO.Page, queryDTO.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load moderation queue"})
+		return
+	}
+
+	page, pageSize := queryDTO.Page, queryDTO.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	items := make([]dtos.ReportResponseDTO, 0, len(reports))
+	for i := range reports {
+		item := toReportResponse(&reports[i])
+		item.Reports = nil
+		items = append(items, item)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"reports": items,
+		"pagination": dtos.ModerationPaginationDTO{
+			TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+			CurrentPage:  page,
+			TotalReports: total,
+			PageSize:     pageSize,
+		},
+	})
+}
+
+// returns a moderation case with every individual report
+func (rc *ReportController) GetReport(c *gin.Context) {
+	report, err := rc.reportUseCase.GetReport(c.Param("id"))
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toReportResponse(report))
+}
+
+// applies a moderator action: dismiss, hide, delete, warn or suspend
+func (rc *ReportController) ResolveReport(c *gin.Context) {
+	var req dtos.ResolveReportDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	reportID := c.Param("id")
+	report, err := rc.reportUseCase.ResolveReport(reportID, c.GetString("user_id"), req.Action, req.Note)
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(rc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditReportResolve,
+		TargetType: models.AuditTargetReport,
+		TargetID:   reportID,
+		Details: map[string]interface{}{
+			"action":       req.Action,
+			"note":         req.Note,
+			"content_type": report.TargetType,
+			"content_id":   report.TargetID,
+			"owner_id":     report.TargetOwnerID,
+		},
+	})
+	c.JSON(http.StatusOK, toReportResponse(report))
+}
+
+func toReportResponse(report *models.ContentReport) dtos.ReportResponseDTO {
+	resp := dtos.ReportResponseDTO{
+		ID:             report.ID,
+		TargetType:     report.TargetType,
+		TargetID:       report.TargetID,
+		TargetOwnerID:  report.TargetOwnerID,
+		Status:         report.Status,
+		ReportCount:    report.ReportCount,
+		ReasonCounts:   report.ReasonCounts,
+		Reports:        make([]dtos.ReportEntryResponseDTO, 0, len(report.Reports)),
+		AutoHidden:     report.AutoHidden,
+		Resolution:     report.Resolution,
+		ResolutionNote: report.ResolutionNote,
+		ResolvedBy:     report.ResolvedBy,
+		ResolvedAt:     report.ResolvedAt,
+		CreatedAt:      report.CreatedAt,
+		LastReportedAt: report.LastReportedAt,
+	}
+	for _, e := range report.Reports {
+		resp.Reports = append(resp.Reports, dtos.ReportEntryResponseDTO{
+			ReporterID: e.ReporterID,
+			Reason:     e.Reason,
+			Details:    e.Details,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	return resp
+}
+
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, contracts_usecases.ErrReportNotFound), errors.Is(err, contracts_usecases.ErrReportTargetNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, contracts_usecases.ErrAlreadyReported), errors.Is(err, contracts_usecases.ErrReportClosed):
+		return http.StatusConflict
+	case errors.Is(err, contracts_usecases.ErrProtectedAccount):
+		return http.StatusForbidden
+	case errors.Is(err, contracts_usecases.ErrInvalidReportTarget), errors.Is(err, contracts_usecases.ErrInvalidReportReason),
+		errors.Is(err, contracts_usecases.ErrReportDetailsRequired), errors.Is(err, contracts_usecases.ErrCannotReportOwnContent),
+		errors.Is(err, contracts_usecases.ErrInvalidModerationAction), errors.Is(err, contracts_usecases.ErrModerationNoteRequired):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	usecases "blog_api/Domain/contracts/usecases"
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewController struct {
+	reviewUseCase usecases.IReviewUseCase
+}
+
+func NewReviewController(reviewUseCase usecases.IReviewUseCase) *ReviewController {
+	return &ReviewController{reviewUseCase: reviewUseCase}
+}
+
+// lists blogs waiting for review
+func (rc *ReviewController) GetReviewQueue(c *gin.Context) {
+	var query dtos.ReviewQueueQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request"})
+		return
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+
+	blogs, total, err := rc.reviewUseCase.GetReviewQueue(query.Page, query.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"blogs": blogs,
+		"pagination": dtos.PaginationMetadataDTO{
+			TotalPages:  int(math.Ceil(float64(total) / float64(query.PageSize))),
+			CurrentPage: query.Page,
+			TotalPosts:  total,
+			PageSize:    query.PageSize,
+		},
+	})
+}
+
+func (rc *ReviewController) ApproveBlog(c *gin.Context) {
+	rc.decide(c, rc.reviewUseCase.ApproveBlog, "Blog approved and published")
+}
+
+func (rc *ReviewController) RejectBlog(c *gin.Context) {
+	rc.decide(c, rc.reviewUseCase.RejectBlog, "Blog rejected")
+}
+
+func (rc *ReviewController) RequestChanges(c *gin.Context) {
+	rc.decide(c, rc.reviewUseCase.RequestChanges, "Changes requested from the author")
+}
+
+// resubmits a blog for review after changes were requested
+func (rc *ReviewController) SubmitForReview(c *gin.Context) {
+	blogID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	if err := rc.reviewUseCase.SubmitForReview(blogID, userID); err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Blog submitted for review"})
+}
+
+// returns the review history of a blog
+func (rc *ReviewController) GetReviewHistory(c *gin.Context) {
+	blogID := c.Param("id")
+	userID := c.GetString("user_id")
+	role := c.GetString("role")
+
+	reviews, err := rc.reviewUseCase.GetReviewHistory(blogID, userID, role)
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	history := make([]dtos.ReviewEventResponseDTO, 0, len(reviews))
+	for _, r := range reviews {
+		history = append(history, dtos.ReviewEventResponseDTO{
+			ID:         r.ID,
+			BlogID:     r.BlogID,
+			ActorID:    r.ActorID,
+			Action:     r.Action,
+			FromStatus: r.FromStatus,
+			ToStatus:   r.ToStatus,
+			Comment:    r.Comment,
+			CreatedAt:  r.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"reviews": history})
+}
+
+func (rc *ReviewController) decide(c *gin.Context, action func(blogID, reviewerID, comment string) error, message string) {
+	blogID := c.Param("id")
+	reviewerID := c.GetString("user_id")
+
+	var decision dtos.ReviewDecisionDTO
+	if err := c.ShouldBindJSON(&decision); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := action(blogID, reviewerID, decision.Comment); err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "blog_id": blogID})
+}
+
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrBlogNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrNotBlogAuthor), errors.Is(err, usecases.ErrCannotReviewOwnBlog):
+		return http.StatusForbidden
+	case errors.Is(err, usecases.ErrInvalidReviewState):
+		return http.StatusConflict
+	case errors.Is(err, usecases.ErrReviewCommentRequired):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
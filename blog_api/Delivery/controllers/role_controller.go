@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	roleUseCase  contracts_usecases.IRoleUseCase
+	auditUseCase contracts_usecases.IAuditUseCase
+}
+
+func NewRoleController(roleUseCase contracts_usecases.IRoleUseCase, auditUseCase contracts_usecases.IAuditUseCase) *RoleController {
+	return &RoleController{roleUseCase: roleUseCase, auditUseCase: auditUseCase}
+}
+
+// lists all roles and the permissions that can be granted
+func (rc *RoleController) ListRoles(c *gin.Context) {
+	roles, err := rc.roleUseCase.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list roles"})
+		return
+	}
+
+	items := make([]dtos.RoleResponseDTO, 0, len(roles))
+	for i := range roles {
+		items = append(items, toRoleResponse(&roles[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"roles": items, "available_permissions": models.AllPermissions})
+}
+
+func (rc *RoleController) GetRole(c *gin.Context) {
+	role, err := rc.roleUseCase.GetRole(c.Param("roleID"))
+	if err != nil {
+		c.JSON(roleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toRoleResponse(role))
+}
+
+func (rc *RoleController) CreateRole(c *gin.Context) {
+	var req dtos.CreateRoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	role, err := rc.roleUseCase.CreateRole(req.Name, req.Description, req.Permissions)
+	if err != nil {
+		c.JSON(roleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(rc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditRoleCreate,
+		TargetType: models.AuditTargetRole,
+		TargetID:   role.ID,
+		After:      rc.auditUseCase.SnapshotRole(role.ID),
+	})
+	c.JSON(http.StatusCreated, toRoleResponse(role))
+}
+
+func (rc *RoleController) UpdateRole(c *gin.Context) {
+	var req dtos.UpdateRoleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	roleID := c.Param("roleID")
+	before := rc.auditUseCase.SnapshotRole(roleID)
+	role, err := rc.roleUseCase.UpdateRole(roleID, req.Description, req.Permissions)
+	if err != nil {
+		c.JSON(roleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(rc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditRoleUpdate,
+		TargetType: models.AuditTargetRole,
+		TargetID:   roleID,
+		Before:     before,
+		After:      rc.auditUseCase.SnapshotRole(roleID),
+	})
+	c.JSON(http.StatusOK, toRoleResponse(role))
+}
+
+func (rc *RoleController) DeleteRole(c *gin.Context) {
+	roleID := c.Param("roleID")
+	before := rc.auditUseCase.SnapshotRole(roleID)
+	if err := rc.roleUseCase.DeleteRole(roleID); err != nil {
+		c.JSON(roleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(rc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditRoleDelete,
+		TargetType: models.AuditTargetRole,
+		TargetID:   roleID,
+		Before:     before,
+	})
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully", "role_id": roleID})
+}
+
+func toRoleResponse(role *models.Role) dtos.RoleResponseDTO {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return dtos.RoleResponseDTO{
+		ID:          role.ID,
+		Name:        role.Role,
+		Description: role.Description,
+		Permissions: permissions,
+		BuiltIn:     models.IsBuiltInRole(role.Role),
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, contracts_usecases.ErrRoleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, contracts_usecases.ErrRoleExists), errors.Is(err, contracts_usecases.ErrRoleInUse):
+		return http.StatusConflict
+	case errors.Is(err, contracts_usecases.ErrInvalidRoleName), errors.Is(err, contracts_usecases.ErrInvalidPermission),
+		errors.Is(err, contracts_usecases.ErrBuiltInRole), errors.Is(err, contracts_usecases.ErrAdminRoleLockout):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionController struct {
+	tokenUseCase contracts_usecases.ITokenUseCase
+	auditUseCase contracts_usecases.IAuditUseCase
+}
+
+func NewSessionController(tokenUseCase contracts_usecases.ITokenUseCase, auditUseCase contracts_usecases.IAuditUseCase) *SessionController {
+	return &SessionController{tokenUseCase: tokenUseCase, auditUseCase: auditUseCase}
+}
+
+// lists the devices the user is signed in on, marking the one making the request
+func (sc *SessionController) List(c *gin.Context) {
+	sessions, err := sc.tokenUseCase.ListSessions(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load sessions"})
+		return
+	}
+	current := c.GetString("session_id")
+	items := make([]dtos.SessionResponseDTO, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, dtos.SessionResponseDTO{
+			ID:         s.ID,
+			Device:     s.Device,
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			Current:    s.ID == current,
+			CreatedAt:  s.CreatedAt,
+			LastUsedAt: s.LastUsedAt,
+			ExpiresAt:  s.ExpiresAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": items})
+}
+
+// signs one device out
+func (sc *SessionController) Revoke(c *gin.Context) {
+	userID := c.GetString("user_id")
+	sessionID := c.Param("sessionID")
+	if err := sc.tokenUseCase.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, contracts_usecases.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	recordAudit(sc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditSessionRevoke,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Details:    map[string]interface{}{"session_id": sessionID},
+	})
+	if sessionID == c.GetString("session_id") {
+		clearSessionCookies(c)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// signs every other device out, keeping the current one
+func (sc *SessionController) RevokeOthers(c *gin.Context) {
+	userID := c.GetString("user_id")
+	current := c.GetString("session_id")
+	if current == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sign in again to manage sessions from this device"})
+		return
+	}
+	revoked, err := sc.tokenUseCase.RevokeOtherSessions(userID, current)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	recordAudit(sc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditSessionRevokeOthers,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Details:    map[string]interface{}{"revoked": revoked},
+	})
+	c.JSON(http.StatusOK, gin.H{"message": "Other sessions revoked", "revoked": revoked})
+}
+
+// the device details recorded on a session
+func sessionClient(c *gin.Context) models.SessionClient {
+	return models.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
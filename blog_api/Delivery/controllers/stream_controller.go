@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"blog_api/Domain/contracts/services"
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keeps proxies from closing an idle stream
+const streamHeartbeat = 25 * time.Second
+
+type StreamController struct {
+	hub             services.IEventHub
+	realtimeUseCase contracts_usecases.IRealtimeUseCase
+}
+
+func NewStreamController(hub services.IEventHub, realtimeUseCase contracts_usecases.IRealtimeUseCase) *StreamController {
+	return &StreamController{hub: hub, realtimeUseCase: realtimeUseCase}
+}
+
+// streams the user's notifications and, for each ?blog= id, new comments and counter changes as server-sent events
+func (sc *StreamController) Stream(c *gin.Context) {
+	var blogIDs []string
+	for _, v := range c.QueryArray("blog") {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				blogIDs = append(blogIDs, id)
+			}
+		}
+	}
+
+	topics, err := sc.realtimeUseCase.StreamTopics(c.GetString("user_id"), c.GetString("role"), blogIDs)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, contracts_usecases.ErrBlogNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, contracts_usecases.ErrTooManyStreamTopics):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub := sc.hub.Subscribe(topics)
+	defer sub.Close()
+
+	// end the stream when the access token expires so the client reconnects with a fresh one
+	var expired <-chan time.Time
+	if v, ok := c.Get("token_expires_at"); ok {
+		if expiresAt, ok := v.(time.Time); ok {
+			timer := time.NewTimer(time.Until(expiresAt))
+			defer timer.Stop()
+			expired = timer.C
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: 3000\nevent: ready\ndata: {\"topics\":%d}\n\n", len(topics))
+	w.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-expired:
+			fmt.Fprint(w, "event: token_expired\ndata: {}\n\n")
+			w.Flush()
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			w.Flush()
+		case event := <-sub.Events():
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+			w.Flush()
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"blog_api/Delivery/dtos"
 	services "blog_api/Domain/contracts/services"
 	usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,16 +15,19 @@ import (
 type TokenController struct {
 	tokenUseCase usecases.ITokenUseCase
 	jwtService    services.IJWTService
+	auditUseCase usecases.IAuditUseCase
 }
 
 
 func NewTokenController(
 	tokenUseCase usecases.ITokenUseCase,
 	jwtService services.IJWTService,
+	auditUseCase usecases.IAuditUseCase,
 ) *TokenController {
 	return &TokenController{
 		tokenUseCase: tokenUseCase,
 		jwtService:    jwtService,
+		auditUseCase: auditUseCase,
 	}
 }
 
@@ -57,48 +62,58 @@ func (tc *TokenController) ValidateToken(c *gin.Context) {
 	})
 }
 
-// refreshes an access token using a refresh token
+// rotates the refresh token: returns a new access and refresh token and invalidates the one presented
 func (tc *TokenController) RefreshToken(c *gin.Context) {
 	var refreshDTO dtos.RefreshTokenDTO
-	err := c.ShouldBindJSON(&refreshDTO)
-	if err != nil {
-		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-		return
-	}
-
-	valid, err := tc.tokenUseCase.ValidateRefreshToken(refreshDTO.RefreshToken)
-	if err != nil {
-		c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
-		return
-	}
-
-	if !valid {
-		c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "Refresh token not found or expired"})
-		return
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&refreshDTO); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
 	}
-
-	claims, err := tc.jwtService.ValidateRefreshToken(refreshDTO.RefreshToken)
-	if err != nil {
-		c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
-		return
+	refreshToken := refreshDTO.RefreshToken
+	if refreshToken == "" {
+		refreshToken, _ = c.Cookie("refresh_token")
 	}
-
-	userID, ok := claims["user_id"].(string)
-	if !ok {
-		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Invalid token claims"})
+	if refreshToken == "" {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Refresh token is required"})
 		return
 	}
 
-	// Generate new access token
-	role, _ := claims["role"].(string)
-	newAccessTokenModel, err := tc.tokenUseCase.RefreshAccessToken(userID, role)
+	accessTokenModel, refreshTokenModel, err := tc.tokenUseCase.RotateRefreshToken(refreshToken, sessionClient(c))
 	if err != nil {
-		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		switch {
+		case errors.Is(err, usecases.ErrRefreshTokenReused):
+			entry := models.AuditEntry{
+				Action:     models.AuditRefreshTokenReused,
+				TargetType: models.AuditTargetUser,
+			}
+			if claims, err := tc.jwtService.ValidateRefreshToken(refreshToken); err == nil {
+				entry.TargetID, _ = claims["user_id"].(string)
+			}
+			recordAudit(tc.auditUseCase, c, entry)
+			clearSessionCookies(c)
+			c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, usecases.ErrInvalidRefreshToken):
+			c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, usecases.ErrAccountDeactivated):
+			clearSessionCookies(c)
+			c.IndentedJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		}
 		return
 	}
 
-	c.SetCookie("access_token", newAccessTokenModel.Token, 900, "/", "", true, true) // 15 min expiry, Secure & HttpOnly
+	c.SetCookie("access_token", accessTokenModel.Token, 900, "/", "", true, true)          // 15 min expiry, Secure & HttpOnly
+	c.SetCookie("refresh_token", refreshTokenModel.Token, 7*24*3600, "/", "", true, true) // 7 days expiry, Secure & HttpOnly
 	c.IndentedJSON(http.StatusOK, gin.H{
 		"message": "Token refreshed successfully",
 	})
-} 
\ No newline at end of file
+}
+
+// expires both session cookies in the browser
+func clearSessionCookies(c *gin.Context) {
+	c.SetCookie("access_token", "", -1, "/", "", true, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", true, true)
+}
@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"blog_api/Delivery/dtos"
+	contracts_usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorController struct {
+	twoFactorUseCase contracts_usecases.ITwoFactorUseCase
+	tokenUseCase     contracts_usecases.ITokenUseCase
+	auditUseCase     contracts_usecases.IAuditUseCase
+	loginGuard       contracts_usecases.ILoginGuardUseCase
+}
+
+func NewTwoFactorController(twoFactorUseCase contracts_usecases.ITwoFactorUseCase, tokenUseCase contracts_usecases.ITokenUseCase, auditUseCase contracts_usecases.IAuditUseCase, loginGuard contracts_usecases.ILoginGuardUseCase) *TwoFactorController {
+	return &TwoFactorController{
+		twoFactorUseCase: twoFactorUseCase,
+		tokenUseCase:     tokenUseCase,
+		auditUseCase:     auditUseCase,
+		loginGuard:       loginGuard,
+	}
+}
+
+// second login step: exchanges a challenge and a code for a session
+func (tc *TwoFactorController) VerifyLogin(c *gin.Context) {
+	var req dtos.TwoFactorLoginDTO
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "") == (req.RecoveryCode == "") {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Provide challenge_token and either code or recovery_code"})
+		return
+	}
+
+	user, err := tc.twoFactorUseCase.ChallengeUser(req.ChallengeToken)
+	if err != nil {
+		c.IndentedJSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	// wrong codes count against the account like wrong passwords, so the lockout covers both factors
+	identifier := user.Email
+	if identifier == "" {
+		identifier = user.Username
+	}
+	if !checkLoginGuard(c, tc.loginGuard, models.GuardScopeLogin, identifier) {
+		return
+	}
+
+	result, err := tc.twoFactorUseCase.CompleteLogin(req.ChallengeToken, req.Code, req.RecoveryCode)
+	if err != nil {
+		if errors.Is(err, contracts_usecases.ErrInvalidTwoFactorCode) {
+			recordAudit(tc.auditUseCase, c, models.AuditEntry{
+				Action:     models.AuditTwoFactorFailed,
+				TargetType: models.AuditTargetUser,
+				TargetID:   user.ID,
+			})
+			locked, guardErr := tc.loginGuard.RecordFailure(models.GuardScopeLogin, identifier, c.ClientIP())
+			if guardErr != nil {
+				log.Printf("failed to record two-factor failure: %v", guardErr)
+			}
+			if locked {
+				recordAudit(tc.auditUseCase, c, models.AuditEntry{
+					Action:     models.AuditAccountLocked,
+					TargetType: models.AuditTargetUser,
+					TargetID:   user.ID,
+				})
+			}
+		}
+		c.IndentedJSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if err := tc.loginGuard.RecordSuccess(models.GuardScopeLogin, identifier); err != nil {
+		log.Printf("failed to clear login failures: %v", err)
+	}
+
+	if result.Method == models.TwoFactorMethodRecoveryCode {
+		recordAudit(tc.auditUseCase, c, models.AuditEntry{
+			ActorID:    result.User.ID,
+			Action:     models.AuditRecoveryCodeUsed,
+			TargetType: models.AuditTargetUser,
+			TargetID:   result.User.ID,
+			Details:    map[string]interface{}{"remaining": result.RecoveryCodesLeft},
+		})
+	}
+	if !startSession(c, tc.tokenUseCase, tc.auditUseCase, result.User, result.Method) {
+		return
+	}
+	body := gin.H{"message": "Login successful"}
+	if result.Method == models.TwoFactorMethodRecoveryCode {
+		body["recovery_codes_remaining"] = result.RecoveryCodesLeft
+	}
+	c.IndentedJSON(http.StatusOK, body)
+}
+
+// forced enrollment: returns a secret for an account that must use 2FA before signing in
+func (tc *TwoFactorController) StartRequiredSetup(c *gin.Context) {
+	var req dtos.TwoFactorSetupDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	enrollment, err := tc.twoFactorUseCase.StartRequiredEnrollment(req.ChallengeToken)
+	if err != nil {
+		c.IndentedJSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toEnrollmentResponse(enrollment))
+}
+
+// forced enrollment: turns 2FA on and signs the user in
+func (tc *TwoFactorController) ConfirmRequiredSetup(c *gin.Context) {
+	var req dtos.TwoFactorSetupConfirmDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	user, codes, err := tc.twoFactorUseCase.ConfirmRequiredEnrollment(req.ChallengeToken, req.Code)
+	if err != nil {
+		c.IndentedJSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(tc.auditUseCase, c, models.AuditEntry{
+		ActorID:    user.ID,
+		Action:     models.AuditTwoFactorEnabled,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+		Details:    map[string]interface{}{"required": true},
+	})
+	if !startSession(c, tc.tokenUseCase, tc.auditUseCase, user, models.TwoFactorMethodTOTP) {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, dtos.RecoveryCodesResponseDTO{
+		Message:       "Two-factor authentication enabled. Store these recovery codes somewhere safe, they are shown only once",
+		RecoveryCodes: codes,
+	})
+}
+
+// starts enrollment for the signed-in user
+func (tc *TwoFactorController) Enroll(c *gin.Context) {
+	enrollment, err := tc.twoFactorUseCase.StartEnrollment(c.GetString("user_id"))
+	if err != nil {
+		c.IndentedJSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, toEnrollmentResponse(enrollment))
+}
+
+// confirms enrollment with a code from the authenticator app
+func (tc *TwoFactorController) Confirm(c *gin.Context) {
+	var req dtos.TwoFactorCodeDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	userID := c.GetString("user_id")
+	codes, err := tc.twoFactorUseCase.ConfirmEnrollment(userID, req.Code)
+	if err != nil {
+		c.IndentedJSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(tc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditTwoFactorEnabled,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+	})
+	c.IndentedJSON(http.StatusOK, dtos.RecoveryCodesResponseDTO{
+		Message:       "Two-factor authentication enabled. Store these recovery codes somewhere safe, they are shown only once",
+		RecoveryCodes: codes,
+	})
+}
+
+// turns 2FA off for the signed-in user
+func (tc *TwoFactorController) Disable(c *gin.Context) {
+	var req dtos.DisableTwoFactorDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	userID := c.GetString("user_id")
+	if !checkLoginGuard(c, tc.loginGuard, models.GuardScopeReauthenticate, userID) {
+		return
+	}
+	if err := tc.twoFactorUseCase.Disable(userID, req.Password, req.Code); err != nil {
+		tc.reauthFailed(c, userID, err)
+		c.IndentedJSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	tc.reauthSucceeded(userID)
+	recordAudit(tc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditTwoFactorDisabled,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+	})
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// replaces the signed-in user's recovery codes
+func (tc *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dtos.TwoFactorCodeDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	userID := c.GetString("user_id")
+	if !checkLoginGuard(c, tc.loginGuard, models.GuardScopeReauthenticate, userID) {
+		return
+	}
+	codes, err := tc.twoFactorUseCase.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		tc.reauthFailed(c, userID, err)
+		c.IndentedJSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	tc.reauthSucceeded(userID)
+	recordAudit(tc.auditUseCase, c, models.AuditEntry{
+		Action:     models.AuditRecoveryCodesRenewed,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+	})
+	c.IndentedJSON(http.StatusOK, dtos.RecoveryCodesResponseDTO{
+		Message:       "New recovery codes generated, the previous ones no longer work",
+		RecoveryCodes: codes,
+	})
+}
+
+// wrong codes and passwords on the signed-in 2FA settings count toward the same lockout as a password change
+func (tc *TwoFactorController) reauthFailed(c *gin.Context, userID string, err error) {
+	if errors.Is(err, contracts_usecases.ErrInvalidTwoFactorCode) || errors.Is(err, contracts_usecases.ErrInvalidPassword) {
+		recordReauthFailure(c, tc.loginGuard, tc.auditUseCase, userID)
+	}
+}
+
+func (tc *TwoFactorController) reauthSucceeded(userID string) {
+	if err := tc.loginGuard.RecordSuccess(models.GuardScopeReauthenticate, userID); err != nil {
+		log.Printf("failed to clear re-authentication failures: %v", err)
+	}
+}
+
+// issues tokens, sets the session cookies and records the login; false means an error response was written
+func startSession(c *gin.Context, tokenUseCase contracts_usecases.ITokenUseCase, auditUseCase contracts_usecases.IAuditUseCase, user *models.User, method string) bool {
+	accessTokenModel, refreshTokenModel, err := tokenUseCase.GenerateAndStoreTokens(user.ID, user.RoleID, sessionClient(c))
+	if err != nil {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		return false
+	}
+	entry := models.AuditEntry{
+		ActorID:    user.ID,
+		Action:     models.AuditLogin,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	}
+	if method != "" {
+		entry.Details = map[string]interface{}{"second_factor": method}
+	}
+	recordAudit(auditUseCase, c, entry)
+	c.SetCookie("access_token", accessTokenModel.Token, 900, "/", "", true, true)         // 15 min expiry, Secure & HttpOnly
+	c.SetCookie("refresh_token", refreshTokenModel.Token, 7*24*3600, "/", "", true, true) // 7 days expiry, Secure & HttpOnly
+	return true
+}
+
+func toChallengeResponse(challenge *models.TwoFactorChallenge) dtos.TwoFactorChallengeResponseDTO {
+	message := "Enter the code from your authenticator app to finish signing in"
+	if challenge.Kind == models.TwoFactorChallengeSetup {
+		message = "Your account must use two-factor authentication, set it up to finish signing in"
+	}
+	return dtos.TwoFactorChallengeResponseDTO{
+		Message:           message,
+		TwoFactorRequired: true,
+		ChallengeType:     challenge.Kind,
+		ChallengeToken:    challenge.Token,
+		ExpiresAt:         challenge.ExpiresAt,
+	}
+}
+
+func toEnrollmentResponse(enrollment *models.TwoFactorEnrollment) dtos.TwoFactorEnrollmentResponseDTO {
+	return dtos.TwoFactorEnrollmentResponseDTO{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.ProvisioningURI,
+	}
+}
+
+func twoFactorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, contracts_usecases.ErrInvalidTwoFactorCode), errors.Is(err, contracts_usecases.ErrInvalidTwoFactorChallenge),
+		errors.Is(err, contracts_usecases.ErrInvalidPassword):
+		return http.StatusUnauthorized
+	case errors.Is(err, contracts_usecases.ErrTwoFactorAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, contracts_usecases.ErrTwoFactorNotEnabled), errors.Is(err, contracts_usecases.ErrTwoFactorNotStarted):
+		return http.StatusBadRequest
+	case errors.Is(err, contracts_usecases.ErrTwoFactorMandatory):
+		return http.StatusForbidden
+	case errors.Is(err, contracts_usecases.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -5,23 +5,34 @@ import (
 	services "blog_api/Domain/contracts/services"
 	usecases "blog_api/Domain/contracts/usecases"
 	"blog_api/Domain/models"
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 
 type UserController struct {
-	userUsecase  usecases.IUserUseCase
-	tokenUsecase usecases.ITokenUseCase
-	jwtService   services.IJWTService
+	userUsecase      usecases.IUserUseCase
+	tokenUsecase     usecases.ITokenUseCase
+	jwtService       services.IJWTService
+	auditUsecase     usecases.IAuditUseCase
+	twoFactorUsecase usecases.ITwoFactorUseCase
+	loginGuard       usecases.ILoginGuardUseCase
 }
 
-func NewUserController(userUsecase usecases.IUserUseCase, tokenUsecase usecases.ITokenUseCase, jwtService services.IJWTService) *UserController {
+func NewUserController(userUsecase usecases.IUserUseCase, tokenUsecase usecases.ITokenUseCase, jwtService services.IJWTService, auditUsecase usecases.IAuditUseCase, twoFactorUsecase usecases.ITwoFactorUseCase, loginGuard usecases.ILoginGuardUseCase) *UserController {
 	return &UserController{
-		userUsecase:  userUsecase,
-		tokenUsecase: tokenUsecase,
-		jwtService:   jwtService,
+		userUsecase:      userUsecase,
+		tokenUsecase:     tokenUsecase,
+		jwtService:       jwtService,
+		auditUsecase:     auditUsecase,
+		twoFactorUsecase: twoFactorUsecase,
+		loginGuard:       loginGuard,
 	}
 }
 
@@ -61,31 +72,70 @@ func (uc *UserController) Login(c *gin.Context) {
 		return
 	}
 	
+	if !checkLoginGuard(c, uc.loginGuard, models.GuardScopeLogin, userDTO.EmailOrUsername) {
+		return
+	}
 	user, err := uc.userUsecase.LoginUser(userDTO.EmailOrUsername, userDTO.Password)
+	if errors.Is(err, usecases.ErrPasswordExpired) {
+		// the password was right, so this is not a failed attempt; earlier failures stay until a full sign-in,
+		// second factor included, succeeds
+		c.IndentedJSON(http.StatusForbidden, gin.H{"error": err.Error(), "password_expired": true})
+		return
+	}
 	if err != nil {
+		recordAudit(uc.auditUsecase, c, models.AuditEntry{
+			Action:     models.AuditLoginFailed,
+			TargetType: models.AuditTargetUser,
+			Details:    map[string]interface{}{"identifier": userDTO.EmailOrUsername},
+		})
+		locked, guardErr := uc.loginGuard.RecordFailure(models.GuardScopeLogin, userDTO.EmailOrUsername, c.ClientIP())
+		if guardErr != nil {
+			log.Printf("failed to record login failure: %v", guardErr)
+		}
+		if locked {
+			recordAudit(uc.auditUsecase, c, models.AuditEntry{
+				Action:     models.AuditAccountLocked,
+				TargetType: models.AuditTargetUser,
+				Details:    map[string]interface{}{"identifier": userDTO.EmailOrUsername},
+			})
+		}
 		c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 	
-	accessTokenModel, refreshTokenModel, err := uc.tokenUsecase.GenerateAndStoreTokens(user.ID, user.RoleID)
+	challenge, err := uc.twoFactorUsecase.BeginLogin(user)
 	if err != nil {
-		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor login"})
+		return
+	}
+	if challenge != nil {
+		// failures are only forgotten once the second factor passes too
+		c.IndentedJSON(http.StatusOK, toChallengeResponse(challenge))
+		return
+	}
+	if err := uc.loginGuard.RecordSuccess(models.GuardScopeLogin, userDTO.EmailOrUsername); err != nil {
+		log.Printf("failed to clear login failures: %v", err)
+	}
+
+	if !startSession(c, uc.tokenUsecase, uc.auditUsecase, user, "") {
 		return
 	}
-	
-	c.SetCookie("access_token", accessTokenModel.Token, 900, "/", "", true, true) // 15 min expiry, Secure & HttpOnly
-	c.SetCookie("refresh_token", refreshTokenModel.Token, 7*24*3600, "/", "", true, true) // 7 days expiry, Secure & HttpOnly
 	c.IndentedJSON(http.StatusOK, gin.H{
 		"message": "Login successful",
 	})
 }
 
+// signs out the current session only; the access token comes from the body or the cookie
 func (uc *UserController) Logout(c *gin.Context) {
 	var logoutDTO dtos.LogoutDTO
-	err := c.ShouldBindJSON(&logoutDTO)
-	if err != nil {
-		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-		return
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&logoutDTO); err != nil {
+			c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+	}
+	if logoutDTO.AccessToken == "" {
+		logoutDTO.AccessToken, _ = c.Cookie("access_token")
 	}
 	claims, err := uc.jwtService.ValidateJWT(logoutDTO.AccessToken)
 	if err != nil {
@@ -98,13 +148,15 @@ func (uc *UserController) Logout(c *gin.Context) {
 		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Invalid token claims"})
 		return
 	}
+	sessionID, _ := claims["sid"].(string)
 
-	err = uc.userUsecase.LogoutUser(userID)
+	err = uc.userUsecase.LogoutUser(userID, sessionID)
 	if err != nil {
 		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
 		return
 	}
 	
+	clearSessionCookies(c)
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Logout successful"})
 }
 //handles forgot password requests
@@ -116,11 +168,23 @@ func (uc *UserController) ForgotPassword(c *gin.Context) {
 		return
 	}
 	
+	if !checkLoginGuard(c, uc.loginGuard, models.GuardScopeForgotPassword, forgotPasswordDTO.Email) {
+		return
+	}
+	// every request counts, so the endpoint cannot be used to flood someone's inbox
+	if _, err := uc.loginGuard.RecordFailure(models.GuardScopeForgotPassword, forgotPasswordDTO.Email, c.ClientIP()); err != nil {
+		log.Printf("failed to record password reset request: %v", err)
+	}
 	err = uc.userUsecase.ForgotPassword(forgotPasswordDTO.Email)
 	if err != nil {
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	recordAudit(uc.auditUsecase, c, models.AuditEntry{
+		Action:     models.AuditPasswordResetRequested,
+		TargetType: models.AuditTargetUser,
+		Details:    map[string]interface{}{"email": forgotPasswordDTO.Email},
+	})
 	
 	// Always return success to prevent email enumeration
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "If the email exists, a password reset link has been sent"})
@@ -135,14 +199,255 @@ func (uc *UserController) ResetPassword(c *gin.Context) {
 		return
 	}
 	
-	err = uc.userUsecase.ResetPassword(resetPasswordDTO.Token, resetPasswordDTO.NewPassword)
+	if !checkLoginGuard(c, uc.loginGuard, models.GuardScopeResetPassword, "") {
+		return
+	}
+	user, err := uc.userUsecase.ResetPassword(resetPasswordDTO.Token, resetPasswordDTO.NewPassword)
 	if err != nil {
+		// only guessed tokens count; a rejected new password is an honest mistake
+		if errors.Is(err, usecases.ErrInvalidResetToken) {
+			if _, guardErr := uc.loginGuard.RecordFailure(models.GuardScopeResetPassword, "", c.ClientIP()); guardErr != nil {
+				log.Printf("failed to record reset failure: %v", guardErr)
+			}
+		}
 		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// a reset proves ownership, so a lock left by someone guessing the old password is lifted
+	if err := uc.loginGuard.Unlock(user.ID); err != nil {
+		log.Printf("failed to clear login lock for %s: %v", user.ID, err)
+	}
+	recordAudit(uc.auditUsecase, c, models.AuditEntry{
+		ActorID:    user.ID,
+		Action:     models.AuditPasswordReset,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	})
 	
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
 }
+// changes the signed-in user's password and signs out their other devices
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	var changeDTO dtos.ChangePasswordDTO
+	if err := c.ShouldBindJSON(&changeDTO); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if !checkLoginGuard(c, uc.loginGuard, models.GuardScopeReauthenticate, userID) {
+		return
+	}
+	initial, err := uc.userUsecase.ChangePassword(userID, c.GetString("session_id"), changeDTO.CurrentPassword, changeDTO.NewPassword)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, usecases.ErrInvalidPassword):
+			// not 401: the session is fine, only the confirmation was wrong
+			recordReauthFailure(c, uc.loginGuard, uc.auditUsecase, userID)
+			status = http.StatusForbidden
+		case errors.Is(err, usecases.ErrUserNotFound):
+			status = http.StatusNotFound
+		}
+		c.IndentedJSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(uc.auditUsecase, c, models.AuditEntry{
+		Action:     models.AuditPasswordChanged,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Details:    map[string]interface{}{"initial": initial},
+	})
+	if err := uc.loginGuard.RecordSuccess(models.GuardScopeReauthenticate, userID); err != nil {
+		log.Printf("failed to clear re-authentication failures: %v", err)
+	}
+
+	message := "Password changed, other sessions have been signed out"
+	if initial {
+		message = "Password set, other sessions have been signed out"
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"message": message})
+}
+
+// sends a confirmation link to the address the signed-in user wants to move to
+func (uc *UserController) RequestEmailChange(c *gin.Context) {
+	var changeDTO dtos.ChangeEmailDTO
+	if err := c.ShouldBindJSON(&changeDTO); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if !checkLoginGuard(c, uc.loginGuard, models.GuardScopeReauthenticate, userID) {
+		return
+	}
+	approvalRequired, err := uc.userUsecase.RequestEmailChange(userID, changeDTO.CurrentPassword, changeDTO.NewEmail)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidPassword) {
+			recordReauthFailure(c, uc.loginGuard, uc.auditUsecase, userID)
+		}
+		c.IndentedJSON(emailChangeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(uc.auditUsecase, c, models.AuditEntry{
+		Action:     models.AuditEmailChangeRequested,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Details:    map[string]interface{}{"new_email": changeDTO.NewEmail, "approval_required": approvalRequired},
+	})
+	if err := uc.loginGuard.RecordSuccess(models.GuardScopeReauthenticate, userID); err != nil {
+		log.Printf("failed to clear re-authentication failures: %v", err)
+	}
+
+	if approvalRequired {
+		c.IndentedJSON(http.StatusOK, gin.H{
+			"message":           "Approve the change from the link sent to your current address",
+			"approval_required": true,
+		})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "A confirmation link has been sent to the new address"})
+}
+
+// sends the new address its confirmation link once the current address approved the change
+func (uc *UserController) ApproveEmailChange(c *gin.Context) {
+	var tokenDTO dtos.EmailChangeTokenDTO
+	if err := c.ShouldBindJSON(&tokenDTO); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	change, err := uc.userUsecase.ApproveEmailChange(tokenDTO.Token)
+	if err != nil {
+		c.IndentedJSON(emailChangeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(uc.auditUsecase, c, models.AuditEntry{
+		ActorID:    change.UserID,
+		Action:     models.AuditEmailChangeApproved,
+		TargetType: models.AuditTargetUser,
+		TargetID:   change.UserID,
+		Details:    map[string]interface{}{"new_email": change.NewEmail},
+	})
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "A confirmation link has been sent to the new address"})
+}
+
+// switches the account to the new address from the emailed confirmation link
+func (uc *UserController) ConfirmEmailChange(c *gin.Context) {
+	var tokenDTO dtos.EmailChangeTokenDTO
+	if err := c.ShouldBindJSON(&tokenDTO); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	change, err := uc.userUsecase.ConfirmEmailChange(tokenDTO.Token)
+	if err != nil {
+		c.IndentedJSON(emailChangeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(uc.auditUsecase, c, models.AuditEntry{
+		ActorID:    change.UserID,
+		Action:     models.AuditEmailChanged,
+		TargetType: models.AuditTargetUser,
+		TargetID:   change.UserID,
+		Before:     map[string]interface{}{"email": change.OldEmail},
+		After:      map[string]interface{}{"email": change.NewEmail},
+	})
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Email address changed", "email": change.NewEmail})
+}
+
+// restores the previous address from the link sent to it after a change
+func (uc *UserController) RevertEmailChange(c *gin.Context) {
+	var tokenDTO dtos.EmailChangeTokenDTO
+	if err := c.ShouldBindJSON(&tokenDTO); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	change, err := uc.userUsecase.RevertEmailChange(tokenDTO.Token)
+	if err != nil {
+		c.IndentedJSON(emailChangeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(uc.auditUsecase, c, models.AuditEntry{
+		ActorID:    change.UserID,
+		Action:     models.AuditEmailChangeReverted,
+		TargetType: models.AuditTargetUser,
+		TargetID:   change.UserID,
+		Before:     map[string]interface{}{"email": change.OldEmail},
+		After:      map[string]interface{}{"email": change.NewEmail},
+	})
+
+	clearSessionCookies(c)
+	c.IndentedJSON(http.StatusOK, gin.H{
+		"message": "Email address restored and all sessions signed out; reset your password to secure the account",
+		"email":   change.NewEmail,
+	})
+}
+
+func emailChangeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidPassword):
+		// not 401: the session is fine, only the confirmation was wrong
+		return http.StatusForbidden
+	case errors.Is(err, usecases.ErrEmailTaken):
+		return http.StatusConflict
+	case errors.Is(err, usecases.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		// invalid addresses and links
+		return http.StatusBadRequest
+	}
+}
+
+// verifies the user's email from the emailed token
+func (uc *UserController) VerifyEmail(c *gin.Context) {
+	var verifyDTO dtos.VerifyEmailDTO
+	if err := c.ShouldBindJSON(&verifyDTO); err != nil {
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	user, err := uc.userUsecase.VerifyEmail(verifyDTO.Token)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, usecases.ErrInvalidVerificationToken) {
+			statusCode = http.StatusBadRequest
+		} else if errors.Is(err, usecases.ErrEmailAlreadyVerified) {
+			statusCode = http.StatusConflict
+		}
+		c.IndentedJSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+	recordAudit(uc.auditUsecase, c, models.AuditEntry{
+		ActorID:    user.ID,
+		Action:     models.AuditEmailVerified,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+		Details:    map[string]interface{}{"email": user.Email},
+	})
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// sends the signed-in user a new verification link
+func (uc *UserController) ResendVerification(c *gin.Context) {
+	err := uc.userUsecase.ResendVerificationEmail(c.GetString("user_id"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, usecases.ErrVerificationThrottled) {
+			statusCode = http.StatusTooManyRequests
+		} else if errors.Is(err, usecases.ErrEmailAlreadyVerified) {
+			statusCode = http.StatusConflict
+		}
+		c.IndentedJSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
 func (uc *UserController) UpdateProfile(c *gin.Context) {
 	userID := c.GetString("user_id")
 	var updateDTO dtos.ProfileUpdateDTO
@@ -179,3 +484,39 @@ func (uc *UserController) UpdateProfile(c *gin.Context) {
 
 
 
+
+// counts a wrong password or code from a signed-in user, so a stolen session cannot guess it without limit
+func recordReauthFailure(c *gin.Context, guard usecases.ILoginGuardUseCase, auditUsecase usecases.IAuditUseCase, userID string) {
+	locked, err := guard.RecordFailure(models.GuardScopeReauthenticate, userID, c.ClientIP())
+	if err != nil {
+		log.Printf("failed to record re-authentication failure: %v", err)
+	}
+	if locked {
+		recordAudit(auditUsecase, c, models.AuditEntry{
+			Action:     models.AuditAccountLocked,
+			TargetType: models.AuditTargetUser,
+			TargetID:   userID,
+			Details:    map[string]interface{}{"scope": models.GuardScopeReauthenticate},
+		})
+	}
+}
+
+// rejects the request with 429 and Retry-After while the account or the client's IP address is throttled
+func checkLoginGuard(c *gin.Context, guard usecases.ILoginGuardUseCase, scope, identifier string) bool {
+	wait, err := guard.Check(scope, identifier, c.ClientIP())
+	if err == nil {
+		return true
+	}
+	if !errors.Is(err, usecases.ErrTooManyAttempts) {
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check sign-in attempts"})
+		return false
+	}
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.IndentedJSON(http.StatusTooManyRequests, gin.H{
+		"error":       err.Error(),
+		"retry_after": seconds,
+		"retry_at":    time.Now().Add(wait).UTC(),
+	})
+	return false
+}
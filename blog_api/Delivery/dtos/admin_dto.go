@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 // promote user request
 type PromoteUserDTO struct {
 	TargetUserID string `json:"target_user_id"`
@@ -23,3 +25,48 @@ type DemoteUserResponseDTO struct {
 	UserID  string `json:"user_id"`
 	NewRole string `json:"new_role"`
 }
+
+// assign role request
+type AssignRoleDTO struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// assign role response
+type AssignRoleResponseDTO struct {
+	Message string `json:"message"`
+	UserID  string `json:"user_id"`
+	NewRole string `json:"new_role"`
+}
+
+// admin user listing query
+type AdminUserQueryDTO struct {
+	Search         string `form:"search"`
+	Role           string `form:"role"`
+	Active         *bool  `form:"active"`
+	IncludeDeleted bool   `form:"include_deleted"`
+	Page           int    `form:"page"`
+	PageSize       int    `form:"page_size"`
+}
+
+// a user as seen in the admin console
+type AdminUserResponseDTO struct {
+	ID               string     `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	RoleID           string     `json:"role_id"`
+	IsActive         bool       `json:"is_active"`
+	EmailVerified    bool       `json:"email_verified"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	CreatedAt        time.Time  `json:"created_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+}
+
+// admin user listing pagination
+type AdminUserPaginationDTO struct {
+	TotalPages  int `json:"total_pages"`
+	CurrentPage int `json:"current_page"`
+	TotalUsers  int `json:"total_users"`
+	PageSize    int `json:"page_size"`
+}
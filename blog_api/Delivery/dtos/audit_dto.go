@@ -0,0 +1,37 @@
+package dtos
+
+import "time"
+
+// audit log query; from and to are RFC3339 timestamps
+type AuditQueryDTO struct {
+	Actor    string `form:"actor"`
+	Target   string `form:"target"`
+	Action   string `form:"action"`
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+// an audit log entry
+type AuditEntryResponseDTO struct {
+	ID         string                 `json:"id"`
+	ActorID    string                 `json:"actor_id"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type"`
+	TargetID   string                 `json:"target_id"`
+	Before     map[string]interface{} `json:"before,omitempty"`
+	After      map[string]interface{} `json:"after,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	IP         string                 `json:"ip"`
+	UserAgent  string                 `json:"user_agent"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// audit log pagination
+type AuditPaginationDTO struct {
+	TotalPages   int `json:"total_pages"`
+	CurrentPage  int `json:"current_page"`
+	TotalEntries int `json:"total_entries"`
+	PageSize     int `json:"page_size"`
+}
@@ -1,5 +1,6 @@
 package dtos
 
 type CommentDTO struct {
-    Content string `json:"content" binding:"required"`
+    Content  string `json:"content" binding:"required"`
+    ParentID string `json:"parent_id"`
 }
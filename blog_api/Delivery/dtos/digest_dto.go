@@ -0,0 +1,28 @@
+package dtos
+
+import "time"
+
+type DigestSettingsDTO struct {
+	Frequency string `json:"frequency" binding:"required"`
+}
+
+type DigestSettingsResponseDTO struct {
+	Frequency  string     `json:"frequency"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+}
+
+type DigestItemDTO struct {
+	BlogID       string   `json:"blog_id"`
+	Title        string   `json:"title"`
+	AuthorName   string   `json:"author_name"`
+	Tags         []string `json:"tags,omitempty"`
+	LikeCount    int      `json:"like_count"`
+	CommentCount int      `json:"comment_count"`
+}
+
+type DigestPreviewDTO struct {
+	Frequency     string          `json:"frequency"`
+	Since         time.Time       `json:"since"`
+	FollowedPosts []DigestItemDTO `json:"followed_posts"`
+	TrendingPosts []DigestItemDTO `json:"trending_posts"`
+}
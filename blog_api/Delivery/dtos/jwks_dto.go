@@ -0,0 +1,20 @@
+package dtos
+
+// a JSON Web Key Set (RFC 7517) as served from /.well-known/jwks.json
+type JWKSResponseDTO struct {
+	Keys []JWKDTO `json:"keys"`
+}
+
+// one public key; RSA keys fill n and e, Ed25519 keys fill crv and x
+type JWKDTO struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	// non-standard hint (Unix seconds) for keys published ahead of their rotation
+	NotBefore int64 `json:"nbf,omitempty"`
+}
@@ -0,0 +1,11 @@
+package dtos
+
+// asks for a sign-in link to be emailed
+type MagicLinkRequestDTO struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// the token from an emailed sign-in link
+type MagicLinkVerifyDTO struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,35 @@
+package dtos
+
+import "time"
+
+// notification listing query
+type NotificationQueryDTO struct {
+	Unread   bool `form:"unread"`
+	Page     int  `form:"page"`
+	PageSize int  `form:"page_size"`
+}
+
+type NotificationResponseDTO struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	ActorID   string     `json:"actor_id"`
+	BlogID    string     `json:"blog_id,omitempty"`
+	CommentID string     `json:"comment_id,omitempty"`
+	Message   string     `json:"message"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// notification listing pagination
+type NotificationPaginationDTO struct {
+	TotalPages         int `json:"total_pages"`
+	CurrentPage        int `json:"current_page"`
+	TotalNotifications int `json:"total_notifications"`
+	PageSize           int `json:"page_size"`
+}
+
+// per-type notification switches, e.g. {"like": false}
+type NotificationPreferencesDTO struct {
+	Types map[string]bool `json:"types" binding:"required"`
+}
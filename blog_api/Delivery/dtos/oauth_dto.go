@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 
 type OAuthLoginResponseDTO struct {
 	Message      string           `json:"message"`
@@ -14,6 +16,8 @@ type OAuthLoginResponseDTO struct {
 // the request for linking OAuth account to existing user
 type LinkOAuthDTO struct {
 	Code string `json:"code" binding:"required"`
+	// the state the provider returned alongside the code
+	State string `json:"state" binding:"required"`
 }
 
 type UserResponseDTO struct {
@@ -22,4 +26,18 @@ type UserResponseDTO struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
-} 
\ No newline at end of file
+} 
+
+// a provider account the user can sign in with
+type LinkedAccountResponseDTO struct {
+	Provider string    `json:"provider"`
+	Email    string    `json:"email"`
+	Name     string    `json:"name"`
+	Picture  string    `json:"picture"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+type LinkedAccountsResponseDTO struct {
+	Accounts    []LinkedAccountResponseDTO `json:"accounts"`
+	HasPassword bool                       `json:"has_password"`
+}
@@ -0,0 +1,58 @@
+package dtos
+
+import "time"
+
+// report request for a blog, comment or user
+type ReportDTO struct {
+	Reason  string `json:"reason" binding:"required"`
+	Details string `json:"details"`
+}
+
+// moderator decision on a report
+type ResolveReportDTO struct {
+	Action string `json:"action" binding:"required"`
+	Note   string `json:"note"`
+}
+
+// moderation queue query
+type ModerationQueueQueryDTO struct {
+	Status     string `form:"status"`
+	TargetType string `form:"target_type"`
+	Page       int    `form:"page"`
+	PageSize   int    `form:"page_size"`
+}
+
+// a single reader's report as seen by moderators
+type ReportEntryResponseDTO struct {
+	ReporterID string    `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	Details    string    `json:"details,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// a moderation case
+type ReportResponseDTO struct {
+	ID             string                   `json:"id"`
+	TargetType     string                   `json:"target_type"`
+	TargetID       string                   `json:"target_id"`
+	TargetOwnerID  string                   `json:"target_owner_id"`
+	Status         string                   `json:"status"`
+	ReportCount    int                      `json:"report_count"`
+	ReasonCounts   map[string]int           `json:"reason_counts"`
+	Reports        []ReportEntryResponseDTO `json:"reports,omitempty"`
+	AutoHidden     bool                     `json:"auto_hidden"`
+	Resolution     string                   `json:"resolution,omitempty"`
+	ResolutionNote string                   `json:"resolution_note,omitempty"`
+	ResolvedBy     string                   `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time               `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	LastReportedAt time.Time                `json:"last_reported_at"`
+}
+
+// moderation queue pagination
+type ModerationPaginationDTO struct {
+	TotalPages   int `json:"total_pages"`
+	CurrentPage  int `json:"current_page"`
+	TotalReports int `json:"total_reports"`
+	PageSize     int `json:"page_size"`
+}
@@ -0,0 +1,26 @@
+package dtos
+
+import "time"
+
+// reviewer decision request
+type ReviewDecisionDTO struct {
+	Comment string `json:"comment"`
+}
+
+// review queue query
+type ReviewQueueQueryDTO struct {
+	Page     int `form:"page"`
+	PageSize int `form:"page_size"`
+}
+
+// a single entry of a blog's review history
+type ReviewEventResponseDTO struct {
+	ID         string    `json:"id"`
+	BlogID     string    `json:"blog_id"`
+	ActorID    string    `json:"actor_id"`
+	Action     string    `json:"action"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
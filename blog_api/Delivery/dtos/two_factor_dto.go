@@ -0,0 +1,53 @@
+package dtos
+
+import "time"
+
+// returned instead of a session when a login needs a second factor
+type TwoFactorChallengeResponseDTO struct {
+	Message           string    `json:"message"`
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeType     string    `json:"challenge_type"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// second login step; send either code or recovery_code
+type TwoFactorLoginDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// forced enrollment request made with a setup challenge
+type TwoFactorSetupDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// forced enrollment confirmation
+type TwoFactorSetupConfirmDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// a current code from the authenticator app
+type TwoFactorCodeDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// disable request; password may be empty for OAuth-only accounts
+type DisableTwoFactorDTO struct {
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// secret to add to an authenticator app
+type TwoFactorEnrollmentResponseDTO struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// recovery codes, shown once
+type RecoveryCodesResponseDTO struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
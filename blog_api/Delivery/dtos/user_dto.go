@@ -46,6 +46,28 @@ type ResetPasswordDTO struct {
 	NewPassword string `json:"new_password"`
 }
 
+// password change by a signed-in user; accounts without a password leave current_password empty
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// asks to move the account to another address; accounts without a password leave current_password empty
+type ChangeEmailDTO struct {
+	NewEmail        string `json:"new_email" binding:"required"`
+	CurrentPassword string `json:"current_password"`
+}
+
+// token from an email change confirmation or revert link
+type EmailChangeTokenDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// email verification request
+type VerifyEmailDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
 type ForgotPasswordResponseDTO struct {
 	Message string `json:"message"`
 }
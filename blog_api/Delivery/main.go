@@ -4,6 +4,7 @@ import (
 	"blog_api/Delivery/controllers"
 	"blog_api/Delivery/routers"
 	contracts_services "blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
 	infrastructure "blog_api/Infrastructure"
 	"blog_api/Infrastructure/provider"
 	repositories "blog_api/Repositories"
@@ -13,6 +14,8 @@ import (
 	"context"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -49,17 +52,81 @@ func main() {
 	// Initialize repositories
 	userRepo := repositories.NewMongoUserRepository(db.Collection("users"))
 	tokenRepo := repositories.NewMongoTokenRepository(db.Collection("access_tokens"), db.Collection("refresh_tokens"))
-	oauthRepo := repositories.NewMongoOAuthRepository(db.Collection("oauth_users"))
+	cipherKeys, err := infrastructure.LoadCipherKeys()
+	if err != nil {
+		log.Fatalf("Failed to load OAuth token keys: %v", err)
+	}
+	tokenCipher, err := infrastructure.NewEnvelopeCipher(cipherKeys)
+	if err != nil {
+		log.Fatalf("Failed to load OAuth token keys: %v", err)
+	}
+	oauthRepo, err := repositories.NewMongoOAuthRepository(db.Collection("oauth_users"), tokenCipher)
+	if err != nil {
+		log.Fatalf("Failed to initialize OAuth links: %v", err)
+	}
 	roleRepo := repositories.NewMongoRoleRepository(db.Collection("roles"))
+	magicLinkRepo, err := repositories.NewMongoMagicLinkRepository(db.Collection("magic_links"))
+	if err != nil {
+		log.Fatalf("Failed to initialize magic links: %v", err)
+	}
+	loginAttemptRepo, err := repositories.NewMongoLoginAttemptRepository(db.Collection("login_attempts"))
+	if err != nil {
+		log.Fatalf("Failed to initialize login attempts: %v", err)
+	}
 	blogRepo := repositories.NewMongoBlogRepository(db.Collection("Blogs"), db.Collection("Blog_interaction"))
 	commRepo := repositories.NewMongoCommentRepository(db.Collection("Comments"))
+	reviewRepo := repositories.NewMongoReviewRepository(db.Collection("blog_reviews"))
+	auditRepo := repositories.NewMongoAuditRepository(db.Collection("audit_log"))
+	reportRepo := repositories.NewMongoReportRepository(db.Collection("content_reports"))
+	emailOutboxRepo := repositories.NewMongoEmailOutboxRepository(db.Collection("email_outbox"))
+	notificationRepo := repositories.NewMongoNotificationRepository(db.Collection("notifications"), db.Collection("notification_preferences"))
+	followRepo := repositories.NewMongoFollowRepository(db.Collection("follows"), db.Collection("tag_follows"))
+	digestRepo := repositories.NewMongoDigestRepository(db.Collection("digest_settings"))
+	sessionRepo := repositories.NewMongoSessionRepository(db.Collection("sessions"))
 
 	// Initialize services
 	passwordSvc := infrastructure.NewPasswordService()
-	jwtSvc := infrastructure.NewJWTService()
-	validationSvc := infrastructure.NewValidationService()
-	emailSvc := infrastructure.NewEmailService()
+	jwtConfig, err := infrastructure.LoadJWTConfig()
+	if err != nil {
+		log.Fatalf("Invalid JWT configuration: %v", err)
+	}
+	jwtSvc, err := infrastructure.NewJWTService(jwtConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT signing keys: %v", err)
+	}
+	passwordPolicy, err := infrastructure.LoadPasswordPolicy()
+	if err != nil {
+		log.Fatalf("Invalid password policy: %v", err)
+	}
+	var breachedPasswords contracts_services.IBreachedPasswordChecker
+	if path := os.Getenv("PASSWORD_BREACHED_LIST"); path != "" {
+		if breachedPasswords, err = infrastructure.NewBreachedPasswordList(path); err != nil {
+			log.Fatalf("Failed to load breached password list: %v", err)
+		}
+	}
+	validationSvc := infrastructure.NewValidationService(passwordPolicy, breachedPasswords)
+	emailConfig, err := infrastructure.LoadEmailConfig()
+	if err != nil {
+		log.Fatalf("Invalid email configuration: %v", err)
+	}
+	emailSvc, err := infrastructure.NewEmailService(emailConfig, emailOutboxRepo)
+	if err != nil {
+		log.Fatalf("Failed to initialize email service: %v", err)
+	}
+	mailer, err := infrastructure.NewMailer(emailConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
 	imageSvc := infrastructure.NewImageService(uploadDir)
+	actionTokenSvc, err := infrastructure.NewActionTokenService()
+	if err != nil {
+		log.Fatalf("Failed to initialize action tokens: %v", err)
+	}
+	totpSvc := infrastructure.NewTOTPService(os.Getenv("TOTP_ISSUER"))
+	tokenDenylist := infrastructure.NewTokenDenylist(tokenRepo, 0)
+	if err := tokenDenylist.Sync(); err != nil {
+		log.Printf("Warning: failed to load revoked access tokens: %v", err)
+	}
 
 	maxTokens := 1000
 	temperature := float32(0.7)
@@ -76,28 +143,28 @@ func main() {
 
 	defer aiService.Close()
 
-	// Dev seeding: roles + initial admin account 
-	if os.Getenv("ENV") != "production" {
+	// Built-in roles and their default permissions; existing permission sets are left as edited
+	for _, roleName := range []string{models.RoleUser, models.RoleReviewer, models.RoleAdmin} {
+		if err := roleRepo.EnsureRole(roleName, models.DefaultRolePermissions[roleName]); err != nil {
+			log.Fatalf("Failed to seed role %s: %v", roleName, err)
+		}
+	}
+	// admin always holds every permission, including ones added after it was seeded
+	if err := roleRepo.GrantPermissions(models.RoleAdmin, models.AllPermissions); err != nil {
+		log.Fatalf("Failed to grant admin permissions: %v", err)
+	}
+
+	permissionCacheTTL := envDuration("PERMISSION_CACHE_TTL", time.Minute, true)
+	permissionSvc := infrastructure.NewPermissionService(roleRepo, permissionCacheTTL)
+
+	// Dev seeding: initial admin account 
+	if !infrastructure.IsProduction() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
 		rolesCol := db.Collection("roles")
 		usersCol := db.Collection("users")
 
-		// Ensure roles exist (user, admin)
-		_, _ = rolesCol.UpdateOne(
-			ctx,
-			bson.M{"role": "user"},
-			bson.M{"$setOnInsert": bson.M{"role": "user", "created_at": time.Now(), "updated_at": time.Now()}},
-			options.Update().SetUpsert(true),
-		)
-		_, _ = rolesCol.UpdateOne(
-			ctx,
-			bson.M{"role": "admin"},
-			bson.M{"$setOnInsert": bson.M{"role": "admin", "created_at": time.Now(), "updated_at": time.Now()}},
-			options.Update().SetUpsert(true),
-		)
-
 		// Read admin role ObjectID
 		var adminRoleDoc bson.M
 		if err := rolesCol.FindOne(ctx, bson.M{"role": "admin"}).Decode(&adminRoleDoc); err == nil {
@@ -132,24 +199,146 @@ func main() {
 		"github":   githubOAuthSvc,
 		"facebook": facebookOAuthSvc,
 	}
+	oidcConfigs, err := provider.LoadOIDCProviderConfigs()
+	if err != nil {
+		log.Fatalf("Invalid OIDC provider configuration: %v", err)
+	}
+	for _, config := range oidcConfigs {
+		oauthServices[config.Name] = provider.NewOIDCOAuthService(config)
+		log.Printf("OIDC login enabled for %s (%s)", config.Name, config.IssuerURL)
+	}
+
+	eventBroker, err := infrastructure.NewEventBroker(os.Getenv("REALTIME_BROKER"), db)
+	if err != nil {
+		log.Fatalf("Failed to initialize realtime broker: %v", err)
+	}
+	eventHub := infrastructure.NewEventHub(eventBroker)
 
 	// Initialize use cases
-	tokenUseCase := usecases.NewTokenUseCase(tokenRepo, jwtSvc, roleRepo)
-	userUseCase := usecases.NewUserUseCase(userRepo, passwordSvc, jwtSvc, validationSvc, emailSvc, tokenUseCase, roleRepo)
-	oauthUseCase := usecases.NewOAuthUseCase(userRepo, oauthRepo, oauthServices, tokenUseCase, roleRepo)
-	adminUseCase := usecases.NewAdminUseCase(userRepo, roleRepo)
-	blogUseCase := usecases.NewBlogUseCase(blogRepo, userRepo)
-	commentUseCase := usecases.NewCommentUseCases(commRepo, blogRepo)
+	tokenUseCase := usecases.NewTokenUseCase(tokenRepo, jwtSvc, roleRepo, sessionRepo, tokenDenylist, userRepo)
+	verificationConfig := usecases.EmailVerificationConfig{
+		ResendCooldown: envDuration("VERIFICATION_RESEND_COOLDOWN", time.Minute, true),
+	}
+	magicLinkConfig := usecases.MagicLinkConfig{
+		TTL:         envDuration("MAGIC_LINK_TTL", 15*time.Minute, false),
+		AllowSignup: true,
+		MaxPerHour:  envInt("MAGIC_LINK_MAX_PER_HOUR", 5, true),
+	}
+	if v := os.Getenv("MAGIC_LINK_SIGNUP"); v != "" {
+		magicLinkConfig.AllowSignup = v == "true"
+	}
+	loginGuardConfig := usecases.LoginGuardConfig{
+		AccountLimit:    envInt("LOGIN_MAX_FAILURES_PER_ACCOUNT", 5, true),
+		IPLimit:         envInt("LOGIN_MAX_FAILURES_PER_IP", 20, true),
+		Window:          envDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute, true),
+		LockoutDuration: envDuration("LOGIN_LOCKOUT", 15*time.Minute, true),
+		BaseDelay:       envDuration("LOGIN_BASE_DELAY", time.Second, true),
+		MaxDelay:        envDuration("LOGIN_MAX_DELAY", 30*time.Second, true),
+	}
+	loginGuardUseCase := usecases.NewLoginGuardUseCase(loginAttemptRepo, userRepo, emailSvc, loginGuardConfig)
+	magicLinkUseCase := usecases.NewMagicLinkUseCase(userRepo, magicLinkRepo, roleRepo, validationSvc, emailSvc, magicLinkConfig)
+	userUseCase := usecases.NewUserUseCase(userRepo, passwordSvc, jwtSvc, validationSvc, emailSvc, tokenUseCase, roleRepo, actionTokenSvc, verificationConfig, passwordPolicy)
+	twoFactorPolicy := usecases.TwoFactorPolicy{RequireForAdmins: os.Getenv("REQUIRE_2FA_FOR_ADMINS") == "true"}
+	twoFactorUseCase := usecases.NewTwoFactorUseCase(userRepo, roleRepo, permissionSvc, passwordSvc, totpSvc, actionTokenSvc, loginAttemptRepo, twoFactorPolicy)
+	oauthFlowConfig := usecases.OAuthFlowConfig{}
+	for _, origin := range strings.Split(os.Getenv("OAUTH_ALLOWED_REDIRECT_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			oauthFlowConfig.AllowedRedirectOrigins = append(oauthFlowConfig.AllowedRedirectOrigins, origin)
+		}
+	}
+	oauthUseCase := usecases.NewOAuthUseCase(userRepo, oauthRepo, oauthServices, tokenUseCase, roleRepo, twoFactorUseCase, actionTokenSvc, oauthFlowConfig)
+	adminUseCase := usecases.NewAdminUseCase(userRepo, roleRepo, blogRepo, commRepo, oauthRepo, followRepo, notificationRepo, digestRepo, reportRepo, reviewRepo, tokenUseCase, permissionSvc)
+	contentPolicy := usecases.ContentPolicy{
+		RequireReview:        os.Getenv("REQUIRE_BLOG_REVIEW") == "true",
+		RequireVerifiedEmail: os.Getenv("REQUIRE_VERIFIED_EMAIL") == "true",
+	}
+	notificationUseCase := usecases.NewNotificationUseCase(notificationRepo, userRepo, blogRepo, eventHub)
+	followUseCase := usecases.NewFollowUseCase(followRepo, userRepo, notificationUseCase)
+	blogUseCase := usecases.NewBlogUseCase(blogRepo, userRepo, reviewRepo, contentPolicy, permissionSvc, notificationUseCase, eventHub)
+	reviewUseCase := usecases.NewReviewUseCase(blogRepo, reviewRepo, userRepo, emailSvc, permissionSvc)
+	commentUseCase := usecases.NewCommentUseCases(commRepo, blogRepo, permissionSvc, userRepo, contentPolicy, notificationUseCase, eventHub)
 	aiUseCase := usecases.NewAIUseCase(aiService)
+	roleUseCase := usecases.NewRoleUseCase(roleRepo, userRepo, permissionSvc)
+	auditUseCase := usecases.NewAuditUseCase(auditRepo, userRepo, roleRepo)
+	reportPolicy := usecases.ReportPolicy{AutoHideThreshold: envInt("REPORT_AUTO_HIDE_THRESHOLD", 5, true)}
+	realtimeUseCase := usecases.NewRealtimeUseCase(blogRepo)
+	digestConfig := usecases.DigestConfig{BatchSize: 100, FollowedLimit: 10, TrendingLimit: 5, Slack: time.Hour}
+	digestUseCase := usecases.NewDigestUseCase(digestRepo, followRepo, blogRepo, userRepo, emailSvc, actionTokenSvc, digestConfig)
+	reportUseCase := usecases.NewReportUseCase(reportRepo, blogRepo, commRepo, userRepo, roleRepo, tokenUseCase, emailSvc, permissionSvc, reportPolicy)
 
 	// Initialize controllers
-	userController := controllers.NewUserController(userUseCase, tokenUseCase, jwtSvc)
-	tokenController := controllers.NewTokenController(tokenUseCase, jwtSvc)
-	oauthController := controllers.NewOAuthController(oauthUseCase)
-	adminController := controllers.NewAdminController(adminUseCase)
+	userController := controllers.NewUserController(userUseCase, tokenUseCase, jwtSvc, auditUseCase, twoFactorUseCase, loginGuardUseCase)
+	tokenController := controllers.NewTokenController(tokenUseCase, jwtSvc, auditUseCase)
+	oauthController := controllers.NewOAuthController(oauthUseCase, auditUseCase)
+	adminController := controllers.NewAdminController(adminUseCase, auditUseCase, loginGuardUseCase)
 	blogController := controllers.NewBlogController(blogUseCase, imageSvc)
 	commentController := controllers.NewCommentController(commentUseCase)
 	aiController := controllers.NewAIController(aiUseCase)
+	reviewController := controllers.NewReviewController(reviewUseCase)
+	roleController := controllers.NewRoleController(roleUseCase, auditUseCase)
+	auditController := controllers.NewAuditController(auditUseCase)
+	reportController := controllers.NewReportController(reportUseCase, auditUseCase)
+	notificationController := controllers.NewNotificationController(notificationUseCase)
+	followController := controllers.NewFollowController(followUseCase)
+	streamController := controllers.NewStreamController(eventHub, realtimeUseCase)
+	digestController := controllers.NewDigestController(digestUseCase)
+	twoFactorController := controllers.NewTwoFactorController(twoFactorUseCase, tokenUseCase, auditUseCase, loginGuardUseCase)
+	sessionController := controllers.NewSessionController(tokenUseCase, auditUseCase)
+	jwksController := controllers.NewJWKSController(jwtSvc)
+	magicLinkController := controllers.NewMagicLinkController(magicLinkUseCase, twoFactorUseCase, tokenUseCase, auditUseCase)
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	outboxConfig := infrastructure.DefaultEmailOutboxConfig()
+	outboxConfig.Interval = envDuration("EMAIL_OUTBOX_INTERVAL", outboxConfig.Interval, false)
+	outboxConfig.MaxAttempts = envInt("EMAIL_MAX_ATTEMPTS", outboxConfig.MaxAttempts, false)
+	log.Printf("Email delivery via %s driver", emailConfig.Driver)
+	go infrastructure.NewEmailOutboxWorker(emailOutboxRepo, mailer, outboxConfig).Run(workerCtx)
+	go eventHub.Run(workerCtx)
+
+	denylistInterval := envDuration("TOKEN_DENYLIST_SYNC_INTERVAL", 5*time.Second, false)
+	go tokenDenylist.Run(workerCtx, denylistInterval)
+
+	digestInterval := envDuration("DIGEST_CHECK_INTERVAL", time.Hour, false)
+	go infrastructure.RunPeriodically(workerCtx, digestInterval, "email digests", func(ctx context.Context) error {
+		sent, err := digestUseCase.SendDueDigests(time.Now())
+		if sent > 0 {
+			log.Printf("Sent %d email digests", sent)
+		}
+		return err
+	})
+
+	oauthRefreshInterval := envDuration("OAUTH_TOKEN_REFRESH_INTERVAL", 5*time.Minute, false)
+	go infrastructure.RunPeriodically(workerCtx, oauthRefreshInterval, "OAuth token refresh", func(ctx context.Context) error {
+		// renew anything that would expire before the pass after next
+		refreshed, err := oauthUseCase.RefreshExpiringTokens(time.Now().Add(2 * oauthRefreshInterval))
+		if refreshed > 0 {
+			log.Printf("Refreshed %d OAuth provider tokens", refreshed)
+		}
+		return err
+	})
+	go infrastructure.RunPeriodically(workerCtx, time.Hour, "OAuth token re-encryption", func(ctx context.Context) error {
+		rewritten, err := oauthRepo.ReencryptTokens(500)
+		if rewritten > 0 {
+			log.Printf("Re-encrypted %d OAuth provider tokens with key %s", rewritten, tokenCipher.ActiveKeyID())
+		}
+		return err
+	})
+
+	rateLimitConfig, err := infrastructure.LoadRateLimitConfig()
+	if err != nil {
+		log.Fatalf("Failed to load rate limit config: %v", err)
+	}
+	rateLimitStore, err := infrastructure.NewRateLimitStore(os.Getenv("RATE_LIMIT_STORE"), db)
+	if err != nil {
+		log.Fatalf("Failed to initialize rate limit store: %v", err)
+	}
+	rateLimiter, err := infrastructure.NewRateLimiter(rateLimitConfig, rateLimitStore, jwtSvc, tokenDenylist)
+	if err != nil {
+		log.Fatalf("Invalid rate limit config: %v", err)
+	}
 
 	// Setup router
 	router := routers.SetupRouter(
@@ -160,9 +349,38 @@ func main() {
 		blogController,
 		commentController,
 		aiController,
+		reviewController,
+		roleController,
+		auditController,
+		reportController,
+		notificationController,
+		followController,
+		streamController,
+		digestController,
+		twoFactorController,
+		sessionController,
+		jwksController,
+		magicLinkController,
 		jwtSvc,
+		tokenDenylist,
+		permissionSvc,
+		rateLimiter,
 	)
 
+	// Per-IP limits rely on the client address; only the listed proxies may set X-Forwarded-For.
+	// With none listed the connection address is used, since gin trusts every proxy by default.
+	var proxies []string
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "none" {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				proxies = append(proxies, p)
+			}
+		}
+	}
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Get port from environment variable or use default
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -175,3 +393,32 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// reads a duration such as 90s from the environment; an unset, negative or invalid value (or zero, unless allowed)
+// keeps the default with a warning
+func envDuration(name string, def time.Duration, allowZero bool) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 || (d == 0 && !allowZero) {
+		log.Printf("Warning: invalid %s %q, using %s", name, v, def)
+		return def
+	}
+	return d
+}
+
+// like envDuration, for whole numbers
+func envInt(name string, def int, allowZero bool) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || (n == 0 && !allowZero) {
+		log.Printf("Warning: invalid %s %q, using %d", name, v, def)
+		return def
+	}
+	return n
+}
@@ -3,6 +3,7 @@ package routers
 import (
 	"blog_api/Delivery/controllers"
 	contracts_services "blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
 	infrastructure "blog_api/Infrastructure"
 
 	"github.com/gin-gonic/gin"
@@ -16,22 +17,70 @@ func SetupRouter(
 	blogController *controllers.BlogController,
 	commentController *controllers.CommentController,
 	aiController *controllers.AIController, // Added AI controller
+	reviewController *controllers.ReviewController,
+	roleController *controllers.RoleController,
+	auditController *controllers.AuditController,
+	reportController *controllers.ReportController,
+	notificationController *controllers.NotificationController,
+	followController *controllers.FollowController,
+	streamController *controllers.StreamController,
+	digestController *controllers.DigestController,
+	twoFactorController *controllers.TwoFactorController,
+	sessionController *controllers.SessionController,
+	jwksController *controllers.JWKSController,
+	magicLinkController *controllers.MagicLinkController,
 	jwtService contracts_services.IJWTService,
+	tokenDenylist contracts_services.ITokenDenylist,
+	permissionService contracts_services.IPermissionService,
+	rateLimiter *infrastructure.RateLimiter,
 ) *gin.Engine {
 	router := gin.Default()
 
+	// Every route is rate limited; policies pick the quota by route, caller and role
+	if rateLimiter != nil {
+		router.Use(rateLimiter.Middleware())
+	}
+
+	// Public signing keys for services that verify our tokens
+	router.GET("/.well-known/jwks.json", jwksController.Keys)
+
 	// User routes
 	userRoutes := router.Group("/api/users")
 	{
 		userRoutes.POST("/register", userController.Register)
 		userRoutes.POST("/login", userController.Login)
+		userRoutes.POST("/login/2fa", twoFactorController.VerifyLogin)
+		userRoutes.POST("/login/2fa/setup", twoFactorController.StartRequiredSetup)
+		userRoutes.POST("/login/2fa/setup/confirm", twoFactorController.ConfirmRequiredSetup)
+		userRoutes.POST("/login/magic-link", magicLinkController.Request)
+		userRoutes.POST("/login/magic-link/verify", magicLinkController.Verify)
 		userRoutes.POST("/logout", userController.Logout)
 		userRoutes.POST("/forgot-password", userController.ForgotPassword)
 		userRoutes.POST("/reset-password", userController.ResetPassword)
+		userRoutes.POST("/verify-email", userController.VerifyEmail)
+		userRoutes.POST("/email-change/approve", userController.ApproveEmailChange)
+		userRoutes.POST("/email-change/confirm", userController.ConfirmEmailChange)
+		userRoutes.POST("/email-change/revert", userController.RevertEmailChange)
 
 		// Auth required
-		userRoutes.Use(infrastructure.AuthMiddleware(jwtService))
+		userRoutes.Use(infrastructure.AuthMiddleware(jwtService, tokenDenylist))
 		userRoutes.PUT("/profile", userController.UpdateProfile)
+		userRoutes.PUT("/me/password", userController.ChangePassword)
+		userRoutes.POST("/me/email", userController.RequestEmailChange)
+		userRoutes.POST("/resend-verification", userController.ResendVerification)
+		userRoutes.POST("/2fa/enroll", twoFactorController.Enroll)
+		userRoutes.POST("/2fa/confirm", twoFactorController.Confirm)
+		userRoutes.POST("/2fa/disable", twoFactorController.Disable)
+		userRoutes.POST("/2fa/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+		userRoutes.GET("/me/sessions", sessionController.List)
+		userRoutes.POST("/me/sessions/revoke-others", sessionController.RevokeOthers)
+		userRoutes.DELETE("/me/sessions/:sessionID", sessionController.Revoke)
+		userRoutes.GET("/me/oauth", oauthController.ListLinkedAccounts)
+		userRoutes.DELETE("/me/oauth/:provider", oauthController.UnlinkProvider)
+		userRoutes.POST("/:userID/report", reportController.ReportUser)
+		userRoutes.POST("/:userID/follow", followController.Follow)
+		userRoutes.DELETE("/:userID/follow", followController.Unfollow)
+		userRoutes.GET("/:userID/follow-stats", followController.GetFollowStats)
 	}
 
 	// Authentication routes
@@ -47,25 +96,47 @@ func SetupRouter(
 		oauthRoutes.GET("/:provider/login", oauthController.InitiateOAuthFlow)
 		oauthRoutes.GET("/:provider/callback", oauthController.HandleOAuthCallback)
 		oauthRoutes.POST("/:provider/link", 
-			infrastructure.AuthMiddleware(jwtService),
-			infrastructure.RBACMiddleware("user", "admin"), 
+			infrastructure.AuthMiddleware(jwtService, tokenDenylist),
 			oauthController.LinkOAuthToExistingUser)
 	}
 
 	// Admin routes
 	adminRoutes := router.Group("/api/admin")
-	adminRoutes.Use(
-		infrastructure.AuthMiddleware(jwtService), 
-		infrastructure.RBACMiddleware("admin"),
-	)
+	adminRoutes.Use(infrastructure.AuthMiddleware(jwtService, tokenDenylist))
+
+	adminUserRoutes := adminRoutes.Group("/users")
+	adminUserRoutes.Use(infrastructure.RequirePermission(permissionService, models.PermUserManage))
+	{
+		adminUserRoutes.POST("/:userID/promote", adminController.PromoteUser)
+		adminUserRoutes.POST("/:userID/demote", adminController.DemoteUser)
+		adminUserRoutes.PUT("/:userID/role", adminController.AssignRole)
+		adminUserRoutes.GET("", adminController.ListUsers)
+		adminUserRoutes.POST("/:userID/deactivate", adminController.DeactivateUser)
+		adminUserRoutes.POST("/:userID/reactivate", adminController.ReactivateUser)
+		adminUserRoutes.POST("/:userID/unlock", adminController.UnlockUser)
+		adminUserRoutes.DELETE("/:userID", adminController.DeleteUser)
+	}
+
+	adminRoleRoutes := adminRoutes.Group("/roles")
+	adminRoleRoutes.Use(infrastructure.RequirePermission(permissionService, models.PermRoleManage))
 	{
-		adminRoutes.POST("/users/:userID/promote", adminController.PromoteUser)
-		adminRoutes.POST("/users/:userID/demote", adminController.DemoteUser)
+		adminRoleRoutes.GET("", roleController.ListRoles)
+		adminRoleRoutes.POST("", roleController.CreateRole)
+		adminRoleRoutes.GET("/:roleID", roleController.GetRole)
+		adminRoleRoutes.PUT("/:roleID", roleController.UpdateRole)
+		adminRoleRoutes.DELETE("/:roleID", roleController.DeleteRole)
+	}
+
+	adminAuditRoutes := adminRoutes.Group("/audit-log")
+	adminAuditRoutes.Use(infrastructure.RequirePermission(permissionService, models.PermAuditRead))
+	{
+		adminAuditRoutes.GET("", auditController.ListEntries)
+		adminAuditRoutes.GET("/export", auditController.ExportEntries)
 	}
 
 	// Blog routes
 	blogRoutes := router.Group("/api/blogs")
-	blogRoutes.Use(infrastructure.AuthMiddleware(jwtService))
+	blogRoutes.Use(infrastructure.AuthMiddleware(jwtService, tokenDenylist))
 	{
 		blogRoutes.POST("/create", blogController.CreateBlog)
 		blogRoutes.GET("/", blogController.GetBlogs)
@@ -74,29 +145,96 @@ func SetupRouter(
 		blogRoutes.GET("/search", blogController.SearchBlogsHandler)
 		blogRoutes.POST("/:id/like", blogController.LikeBlog)
 		blogRoutes.POST("/:id/dislike", blogController.DislikeBlog)
+		blogRoutes.POST("/:id/share", blogController.ShareBlog)
+		blogRoutes.POST("/:id/submit", reviewController.SubmitForReview)
+		blogRoutes.GET("/:id/reviews", reviewController.GetReviewHistory)
+		blogRoutes.POST("/:id/report", reportController.ReportBlog)
 		blogRoutes.POST("/:id/generate-content",
-			infrastructure.RBACMiddleware("user", "admin"),
+			infrastructure.RequirePermission(permissionService, models.PermAIUse),
 			aiController.GenerateBlogContentForPost)
 	}
 
+	// Review routes
+	reviewRoutes := router.Group("/api/reviews")
+	reviewRoutes.Use(
+		infrastructure.AuthMiddleware(jwtService, tokenDenylist),
+		infrastructure.RequirePermission(permissionService, models.PermBlogReview),
+	)
+	{
+		reviewRoutes.GET("/queue", reviewController.GetReviewQueue)
+		reviewRoutes.POST("/:id/approve", reviewController.ApproveBlog)
+		reviewRoutes.POST("/:id/reject", reviewController.RejectBlog)
+		reviewRoutes.POST("/:id/request-changes", reviewController.RequestChanges)
+	}
+
+	// Moderation routes
+	moderationRoutes := router.Group("/api/moderation")
+	moderationRoutes.Use(
+		infrastructure.AuthMiddleware(jwtService, tokenDenylist),
+		infrastructure.RequirePermission(permissionService, models.PermContentModerate),
+	)
+	{
+		moderationRoutes.GET("/queue", reportController.GetQueue)
+		moderationRoutes.GET("/reports/:id", reportController.GetReport)
+		moderationRoutes.POST("/reports/:id/resolve", reportController.ResolveReport)
+	}
+
 	// Comment routes
 	commentRoutes := router.Group("/api/comments")
-	commentRoutes.Use(infrastructure.AuthMiddleware(jwtService))
+	commentRoutes.Use(infrastructure.AuthMiddleware(jwtService, tokenDenylist))
 	{ 
 		commentRoutes.POST("/create/:id", commentController.CreateComment)
 		commentRoutes.PUT("/:id", commentController.UpdateComment)
 		commentRoutes.DELETE("/:id", commentController.DeleteComment)
+		commentRoutes.POST("/:id/report", reportController.ReportComment)
+	}
+
+	// Notification routes
+	notificationRoutes := router.Group("/api/notifications")
+	notificationRoutes.Use(infrastructure.AuthMiddleware(jwtService, tokenDenylist))
+	{
+		notificationRoutes.GET("", notificationController.ListNotifications)
+		notificationRoutes.GET("/unread-count", notificationController.UnreadCount)
+		notificationRoutes.POST("/read-all", notificationController.MarkAllRead)
+		notificationRoutes.POST("/:id/read", notificationController.MarkRead)
+		notificationRoutes.GET("/preferences", notificationController.GetPreferences)
+		notificationRoutes.PUT("/preferences", notificationController.UpdatePreferences)
 	}
 
+	// Tag follow routes
+	tagRoutes := router.Group("/api/tags")
+	tagRoutes.Use(infrastructure.AuthMiddleware(jwtService, tokenDenylist))
+	{
+		tagRoutes.GET("/following", followController.ListFollowedTags)
+		tagRoutes.POST("/:tag/follow", followController.FollowTag)
+		tagRoutes.DELETE("/:tag/follow", followController.UnfollowTag)
+	}
+
+	// Digest routes
+	digestRoutes := router.Group("/api/digest")
+	{
+		digestRoutes.GET("/unsubscribe", digestController.Unsubscribe)
+		digestRoutes.POST("/unsubscribe", digestController.Unsubscribe)
+
+		// Auth required
+		digestRoutes.Use(infrastructure.AuthMiddleware(jwtService, tokenDenylist))
+		digestRoutes.GET("/settings", digestController.GetSettings)
+		digestRoutes.PUT("/settings", digestController.UpdateSettings)
+		digestRoutes.GET("/preview", digestController.Preview)
+	}
+
+	// Realtime stream
+	router.GET("/api/stream", infrastructure.AuthMiddleware(jwtService, tokenDenylist), streamController.Stream)
+
 	// AI routes
 	aiRoutes := router.Group("/api/ai")
-	aiRoutes.Use(infrastructure.AuthMiddleware(jwtService))
+	aiRoutes.Use(infrastructure.AuthMiddleware(jwtService, tokenDenylist))
 	{
 		aiRoutes.POST("/generate", 
-			infrastructure.RBACMiddleware("user", "admin"),
+			infrastructure.RequirePermission(permissionService, models.PermAIUse),
 			aiController.GenerateBlogPost)
 		aiRoutes.POST("/suggest-improvements", 
-			infrastructure.RBACMiddleware("user", "admin"),
+			infrastructure.RequirePermission(permissionService, models.PermAIUse),
 			aiController.SuggestImprovements)
 	}
 
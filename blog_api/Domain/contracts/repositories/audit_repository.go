@@ -0,0 +1,9 @@
+package repositories
+
+import "blog_api/Domain/models"
+
+// the audit log is append-only: entries are never updated or removed
+type IAuditRepository interface {
+	CreateEntry(entry *models.AuditEntry) error
+	ListEntries(query *models.AuditQuery) ([]models.AuditEntry, int, error)
+}
@@ -1,6 +1,9 @@
 package repositories
 
-import "blog_api/Domain/models"
+import (
+	"blog_api/Domain/models"
+	"time"
+)
 
 type IBlogRepository interface {
 	CreateBlog(blog *models.Blog) error
@@ -9,6 +12,15 @@ type IBlogRepository interface {
 	GetBlogByID(blogID string) (models.Blog,error)
 	DeleteBlog( blogID string) error
 	SearchBlogs(blogTitle string,authorID string)(*[]models.Blog,error)
+	UpdateBlogStatus(blogID, status string) error
+	// moves a blog to toStatus only while it is still in fromStatus; false when another update got there first
+	TransitionBlogStatus(blogID, fromStatus, toStatus string) (bool, error)
+	GetBlogsByStatus(status string, page, pageSize int) ([]models.Blog, int, error)
+	SetBlogsStatusByAuthor(authorID, status string) error
+	DeleteBlogsByAuthor(authorID string) ([]string, error)
+	AdjustCommentCount(blogID string, delta int) error
+	// published blogs created since the given time, most liked first; empty authorIDs and tags match every blog
+	GetTopBlogsSince(since time.Time, authorIDs, tags []string, limit int) ([]models.Blog, error)
 
 	HasUserInteraction(userID, blogID, action string) (bool, error)
     AddUserInteraction(userID, blogID, action string) error
@@ -16,6 +28,7 @@ type IBlogRepository interface {
 	IncrementLike(blogID string) error
     IncrementDislike(blogID string) error
 	IncrementComment(blogID string)error
+	IncrementShare(blogID string) error
 	DecrementComment(blogID string) error
 
 }
\ No newline at end of file
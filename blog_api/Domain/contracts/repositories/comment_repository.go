@@ -3,9 +3,14 @@ package repositories
 import "blog_api/Domain/models"
 
 type ICommentRepository interface {
-	CreateComment(blogID, userID, content string) error
+	CreateComment(comment *models.Comment) error
 	CheckCommentExist(CommentID string) error
 	UpdateComment(CommentID, content string) error
 	DeleteComment(commentID string) error
 	GetCommentByID(commentID string) (models.Comment,error)
+	DeleteCommentsByBlogID(blogID string) error
+	// deletes a user's comments and returns how many were removed per blog
+	DeleteCommentsByUserID(userID string) (map[string]int, error)
+	SetCommentsHiddenByUserID(userID string, hidden bool) error
+	SetCommentHidden(commentID string, hidden bool) error
 }
\ No newline at end of file
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"blog_api/Domain/models"
+	"time"
+)
+
+type IDigestRepository interface {
+	// returns off settings when the user has none stored
+	GetSettings(userID string) (*models.DigestSettings, error)
+	SaveFrequency(userID, frequency string) error
+	// lists subscriptions of the frequency last sent before the cutoff (or never sent)
+	ListDue(frequency string, sentBefore time.Time, limit int) ([]models.DigestSettings, error)
+	// sets last_sent_at only if it still equals previous, so one instance sends each digest
+	ClaimRun(userID string, previous *time.Time, now time.Time) (bool, error)
+	DeleteSettings(userID string) error
+}
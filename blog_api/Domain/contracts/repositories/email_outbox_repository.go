@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"blog_api/Domain/models"
+	"time"
+)
+
+type IEmailOutboxRepository interface {
+	Enqueue(message *models.EmailMessage) error
+	// locks up to limit due messages for delivery; a lock expires after lease so crashed workers do not strand mail
+	ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.EmailMessage, error)
+	MarkSent(messageID string, sentAt time.Time) error
+	// records a failed attempt; the message is retried at nextAttemptAt unless giveUp is set
+	MarkFailed(messageID string, attempts int, lastError string, nextAttemptAt time.Time, giveUp bool) error
+}
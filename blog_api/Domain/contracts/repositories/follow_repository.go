@@ -0,0 +1,23 @@
+package repositories
+
+import "blog_api/Domain/models"
+
+type IFollowRepository interface {
+	// reports false when the follow already existed
+	Follow(follow *models.Follow) (bool, error)
+	// returns ErrNotFound when the user was not following
+	Unfollow(followerID, followeeID string) error
+	IsFollowing(followerID, followeeID string) (bool, error)
+	CountFollowers(userID string) (int, error)
+	CountFollowing(userID string) (int, error)
+	ListFollowingIDs(userID string) ([]string, error)
+
+	// reports false when the tag was already followed
+	FollowTag(userID, tag string) (bool, error)
+	// returns ErrNotFound when the tag was not followed
+	UnfollowTag(userID, tag string) error
+	ListFollowedTags(userID string) ([]string, error)
+
+	// removes follows to and from the user and the tags they follow
+	DeleteFollowsByUserID(userID string) error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"blog_api/Domain/models"
+	"time"
+)
+
+type ILoginAttemptRepository interface {
+	Get(key string) (*models.LoginThrottle, error)
+	// counts a failure, starting over when the last one is older than window and no lock is active
+	RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginThrottle, error)
+	Lock(key string, until time.Time) error
+	Delete(keys ...string) error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"blog_api/Domain/models"
+	"time"
+)
+
+type IMagicLinkRepository interface {
+	Create(link *models.MagicLink) error
+	// marks an unused, unexpired link as used and returns it; ErrNotFound otherwise
+	Consume(tokenHash string, now time.Time) (*models.MagicLink, error)
+	// how many links were sent to an address since the given time
+	CountSince(email string, since time.Time) (int64, error)
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import "blog_api/Domain/models"
+
+type INotificationRepository interface {
+	CreateNotification(notification *models.Notification) error
+	ListNotifications(userID string, query *models.NotificationQuery) ([]models.Notification, int, error)
+	CountUnread(userID string) (int, error)
+	// returns ErrNotFound unless the notification belongs to the user
+	MarkRead(userID, notificationID string) error
+	MarkAllRead(userID string) (int, error)
+	// returns default (all enabled) preferences when the user has none stored
+	GetPreferences(userID string) (*models.NotificationPreferences, error)
+	SavePreferences(prefs *models.NotificationPreferences) error
+	// removes notifications sent to or caused by the user, and the user's preferences
+	DeleteNotificationsByUserID(userID string) error
+}
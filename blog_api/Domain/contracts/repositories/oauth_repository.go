@@ -1,6 +1,9 @@
  package repositories
 
-import "blog_api/Domain/models"
+import (
+	"blog_api/Domain/models"
+	"time"
+)
 
 
 type IOAuthRepository interface {
@@ -8,5 +11,14 @@ type IOAuthRepository interface {
 	GetOAuthUserByProviderID(provider, providerID string) (*models.OAuthUser, error)
 	GetOAuthUserByEmail(provider, email string) (*models.OAuthUser, error)
 	UpdateOAuthUser(oauthUser *models.OAuthUser) error
+	ListOAuthUsersByUserID(userID string) ([]models.OAuthUser, error)
+	// links holding a refresh token whose access token expires before the given time
+	ListOAuthUsersExpiringBefore(before time.Time, limit int) ([]models.OAuthUser, error)
+	// rewrites tokens stored in plaintext or under a retired key with the active key
+	ReencryptTokens(limit int) (int, error)
+	// notes a failed background refresh so the link is listed after links that have not failed
+	MarkRefreshFailed(oauthUserID string, at time.Time) error
 	LinkOAuthToUser(oauthUserID, userID string) error
+	DeleteOAuthUsersByProvider(userID, provider string) (int64, error)
+	DeleteOAuthUsersByUserID(userID string) error
 }
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"blog_api/Domain/models"
+	"time"
+)
+
+type IReportRepository interface {
+	// adds a report to the target's open case, opening one if needed, and returns the case
+	AddReport(targetType, targetID, ownerID string, entry models.ReportEntry) (*models.ContentReport, error)
+	HasOpenReportFrom(targetType, targetID, reporterID string) (bool, error)
+	GetReportByID(reportID string) (*models.ContentReport, error)
+	// lists cases with the most reports first
+	ListReports(status, targetType string, page, pageSize int) ([]models.ContentReport, int, error)
+	MarkAutoHidden(reportID, hiddenFrom string) error
+	ResolveReport(reportID, status, resolution, note, moderatorID string, resolvedAt time.Time) error
+	// removes cases about the user's content and drops the user's ID from reports they filed
+	DeleteReportsByUserID(userID string) error
+}
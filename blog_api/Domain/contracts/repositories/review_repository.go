@@ -0,0 +1,10 @@
+package repositories
+
+import "blog_api/Domain/models"
+
+type IReviewRepository interface {
+	CreateReview(review *models.BlogReview) error
+	GetReviewsByBlogID(blogID string) ([]models.BlogReview, error)
+	// removes the review history of the author's blogs
+	DeleteReviewsByAuthorID(authorID string) error
+}
@@ -6,4 +6,13 @@ import ("blog_api/Domain/models")
 type IRoleRepository interface {
 	GetRoleByID(roleID string) (*models.Role, error)
 	GetRoleIDByName(roleName string) (string, error)
+	GetRoleByName(roleName string) (*models.Role, error)
+	ListRoles() ([]models.Role, error)
+	CreateRole(role *models.Role) error
+	UpdateRole(role *models.Role) error
+	DeleteRole(roleID string) error
+	// creates the role if missing and gives it default permissions if it has none
+	EnsureRole(roleName string, permissions []string) error
+	// adds permissions to a role without removing any it already has
+	GrantPermissions(roleName string, permissions []string) error
 }
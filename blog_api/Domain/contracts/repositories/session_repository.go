@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"blog_api/Domain/models"
+	"time"
+)
+
+type ISessionRepository interface {
+	// stores a new session and sets its ID
+	Create(session *models.Session) error
+	GetByID(sessionID string) (*models.Session, error)
+	// sessions that are neither revoked nor expired, most recently used first
+	ListActive(userID string, now time.Time) ([]models.Session, error)
+	// records a refresh from the session's device
+	Touch(sessionID string, client models.SessionClient, usedAt, expiresAt time.Time) error
+	Revoke(sessionID string, revokedAt time.Time) error
+	RevokeAllForUser(userID string, revokedAt time.Time) error
+}
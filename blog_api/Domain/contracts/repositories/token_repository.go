@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"blog_api/Domain/models"
+	"time"
 )
 
 type ITokenRepository interface {
@@ -14,4 +15,11 @@ type ITokenRepository interface {
 	RevokeAccessToken(token string) error
 	RevokeRefreshToken(token string) error
 	RevokeAllUserTokens(userID string) error
+	GetRefreshToken(token string) (*models.RefreshToken, error)
+	// marks a refresh token as used, reporting false if it had already been rotated
+	MarkRefreshTokenRotated(token string, rotatedAt time.Time) (bool, error)
+	// revokes every access and refresh token issued to a token family
+	RevokeTokenFamily(familyID string) error
+	// unexpired access tokens revoked at or after since
+	ListRevokedAccessTokens(since time.Time) ([]models.RevokedAccessToken, error)
 } 
\ No newline at end of file
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"blog_api/Domain/models"
+	"time"
 )
 
 type IUserRepository interface {
@@ -14,8 +15,21 @@ type IUserRepository interface {
 	UpdateUser(user *models.User) error
 	GetUserByResetToken(token string) (*models.User, error)
 	UpdateUserRole(userID, newRole string) error
-	GetAdminCount() (int, error)
+	CountUsersByRole(roleID string) (int, error)
 	UpdateUserProfile(userID string, updateFields map[string]interface{}) error
-
+	ListUsers(query *models.UserQuery) ([]models.User, int, error)
+	SetUserActive(userID string, active bool) error
+	SoftDeleteUser(userID string) error
+	DeleteUser(userID string) error
+	MarkEmailVerified(userID string) error
+	// moves a verified address to the user if they still have fromEmail; false means they do not
+	ChangeEmail(userID, fromEmail, toEmail string, changedAt time.Time) (bool, error)
+	SetVerificationSentAt(userID string, sentAt time.Time) error
+	SetTwoFactorPending(userID, secret string) error
+	EnableTwoFactor(userID, secret string, recoveryCodes []string) error
+	DisableTwoFactor(userID string) error
+	ReplaceRecoveryCodes(userID string, recoveryCodes []string) error
+	ConsumeRecoveryCode(userID, codeHash string) (bool, error)
+	ClaimTOTPStep(userID string, step int64) (bool, error)
 
 } 
\ No newline at end of file
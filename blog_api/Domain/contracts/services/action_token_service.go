@@ -0,0 +1,12 @@
+package services
+
+import (
+	"blog_api/Domain/models"
+	"time"
+)
+
+// issues and verifies signed, expiring tokens embedded in emailed links
+type IActionTokenService interface {
+	Issue(purpose, subject string, data map[string]string, ttl time.Duration) (string, error)
+	Verify(purpose, token string) (*models.ActionToken, error)
+}
@@ -0,0 +1,6 @@
+package services
+
+// tells whether a password appears in a corpus of breached passwords
+type IBreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
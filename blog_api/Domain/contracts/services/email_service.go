@@ -1,6 +1,20 @@
 package services
 
+import (
+	"blog_api/Domain/models"
+	"time"
+)
+
 type IEmailService interface {
 	SendPasswordResetEmail(email, resetToken string) error
 	SendPasswordChangedEmail(email string) error
+	SendReviewDecisionEmail(email, blogTitle, decision, comment string) error
+	SendModerationNoticeEmail(email, action, contentType, note string) error
+	SendVerificationEmail(email, token string) error
+	SendMagicLinkEmail(email, token string, ttl time.Duration) error
+	SendAccountLockedEmail(email string, until time.Time) error
+	SendEmailChangeConfirmEmail(email, token string) error
+	SendEmailChangeApprovalEmail(email, newEmail, token string, ttl time.Duration) error
+	SendEmailChangedEmail(oldEmail, newEmail, revertToken string, ttl time.Duration) error
+	SendDigestEmail(email string, digest *models.Digest, unsubscribeToken string) error
 } 
\ No newline at end of file
@@ -1,8 +1,13 @@
 package services
 
+import "blog_api/Domain/models"
+
 type IJWTService interface {
-	GenerateJWT(userID, role string) (string, error)
+	// returns the signed token and its jti
+	GenerateJWT(userID, role, sessionID string) (string, string, error)
 	ValidateJWT(tokenString string) (map[string]interface{}, error)
 	GenerateRefreshToken(userID string , role string) (string, error)
 	ValidateRefreshToken(tokenString string) (map[string]interface{}, error)
+	// asymmetric keys that currently verify, or soon will; empty when signing with a shared secret
+	PublicKeys() []models.PublicSigningKey
 } 
\ No newline at end of file
@@ -0,0 +1,8 @@
+package services
+
+import "blog_api/Domain/models"
+
+// delivers a rendered email over some transport (SMTP, files, memory)
+type IMailer interface {
+	Send(message *models.EmailMessage) error
+}
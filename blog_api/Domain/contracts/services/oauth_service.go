@@ -1,11 +1,24 @@
 package services
 
-import "blog_api/Domain/models"
+import (
+	"blog_api/Domain/models"
+	"errors"
+)
 
 
 type IOAuthService interface {
-    GetAuthURL(state string) (string, error)
-    ExchangeCodeForToken(code string) (*models.OAuthToken, error)
-    GetUserInfo(accessToken string) (*models.OAuthUserInfo, error)
+    // codeChallenge is the S256 PKCE challenge, empty when the provider does not support PKCE
+    GetAuthURL(state, codeChallenge string) (string, error)
+    ExchangeCodeForToken(code, codeVerifier string) (*models.OAuthToken, error)
+    // OpenID Connect providers also check the ID token that came with the access token
+    GetUserInfo(token *models.OAuthToken) (*models.OAuthUserInfo, error)
     GetProviderName() string
-}
\ No newline at end of file
+    SupportsPKCE() bool
+    // trades a stored refresh token for a new access token; the result's RefreshToken is empty when the provider keeps the old one
+    RefreshToken(refreshToken string) (*models.OAuthToken, error)
+}
+// returned by RefreshToken when the provider no longer honours the refresh token (revoked or expired)
+var ErrProviderGrantRevoked = errors.New("provider refresh token is no longer valid")
+
+// returned by RefreshToken for providers that do not issue refresh tokens
+var ErrRefreshNotSupported = errors.New("provider does not support token refresh")
@@ -0,0 +1,7 @@
+package services
+
+type IPermissionService interface {
+	HasPermission(roleName, permission string) (bool, error)
+	// drops cached permissions after roles change
+	Invalidate()
+}
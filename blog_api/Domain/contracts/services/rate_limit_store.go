@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// counts requests in fixed windows; shared stores let every API instance enforce the same quota
+type IRateLimitStore interface {
+	// counts a hit against key in the window containing now, returning the count so far and when the window ends
+	Hit(ctx context.Context, key string, window time.Duration, now time.Time) (int64, time.Time, error)
+}
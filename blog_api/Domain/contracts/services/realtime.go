@@ -0,0 +1,29 @@
+package services
+
+import (
+	"blog_api/Domain/models"
+	"context"
+)
+
+// publishes realtime events; use cases depend only on this
+type IEventPublisher interface {
+	Publish(topic, eventType string, data interface{})
+}
+
+// carries events between API instances; each instance's hub receives every event
+type IEventBroker interface {
+	Publish(event *models.RealtimeEvent) error
+	// delivers events from all instances until ctx is cancelled
+	Run(ctx context.Context, deliver func(event models.RealtimeEvent)) error
+}
+
+// fans broker events out to the streams connected to this instance
+type IEventHub interface {
+	IEventPublisher
+	Subscribe(topics []string) IEventSubscription
+}
+
+type IEventSubscription interface {
+	Events() <-chan models.RealtimeEvent
+	Close()
+}
@@ -0,0 +1,10 @@
+package services
+
+// encrypts small secrets, such as third-party tokens, for storage
+type ISecretCipher interface {
+	Encrypt(plaintext string) (string, error)
+	// values stored before encryption was enabled are returned unchanged
+	Decrypt(ciphertext string) (string, error)
+	// the key new values are encrypted under; values under any other key should be re-encrypted
+	ActiveKeyID() string
+}
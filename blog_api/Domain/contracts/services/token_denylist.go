@@ -0,0 +1,8 @@
+package services
+
+// answers whether an access token was revoked before it expired, without a database round trip
+type ITokenDenylist interface {
+	IsRevoked(jti string) bool
+	// pulls revocations recorded since the last sync, including those made by other instances
+	Sync() error
+}
@@ -0,0 +1,11 @@
+package services
+
+import "time"
+
+// generates and checks RFC 6238 time-based one-time passwords
+type ITOTPService interface {
+	GenerateSecret() (string, error)
+	ProvisioningURI(secret, accountName string) string
+	// returns the matched time step so callers can reject a replayed code
+	Validate(secret, code string, at time.Time) (int64, bool)
+}
@@ -3,6 +3,7 @@ package services
 
 type IValidationService interface {
 	ValidateEmail(email string) error
-	ValidatePassword(password string) error
+	// personalInfo holds the username, email and names the password must not be built from
+	ValidatePassword(password string, personalInfo ...string) error
 	ValidateUsername(username string) error
-} 
\ No newline at end of file
+}
@@ -1,6 +1,13 @@
 package usecases
 
+import "blog_api/Domain/models"
+
 type IAdminUseCase interface {
 	PromoteUser(adminID, targetUserID string) error
 	DemoteUser(adminID, targetUserID string) error
+	AssignRole(adminID, targetUserID, roleName string) error
+	ListUsers(query *models.UserQuery, roleName string) ([]models.User, int, error)
+	DeactivateUser(adminID, targetUserID string) error
+	ReactivateUser(adminID, targetUserID string) error
+	DeleteUser(adminID, targetUserID string, hard bool) error
 }
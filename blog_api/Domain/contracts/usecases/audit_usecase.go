@@ -0,0 +1,11 @@
+package usecases
+
+import "blog_api/Domain/models"
+
+type IAuditUseCase interface {
+	Record(entry *models.AuditEntry) error
+	SnapshotUser(userID string) map[string]interface{}
+	SnapshotRole(roleID string) map[string]interface{}
+	QueryEntries(query *models.AuditQuery) ([]models.AuditEntry, int, error)
+	ExportEntries(query *models.AuditQuery) ([]models.AuditEntry, error)
+}
@@ -2,7 +2,7 @@ package usecases
 
 
 type ICommentUseCase interface {
-	CreateComment(blogID, userID, content string) (error)
-	UpdateComment(commentID,content string)(error)
-	DeleteComment(commetnID string)(error)
+	CreateComment(blogID, userID, content, parentID string) (error)
+	UpdateComment(commentID, userID, content string)(error)
+	DeleteComment(commentID, userID, role string)(error)
 }
\ No newline at end of file
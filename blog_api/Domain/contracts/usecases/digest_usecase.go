@@ -0,0 +1,17 @@
+package usecases
+
+import (
+	"blog_api/Domain/models"
+	"time"
+)
+
+type IDigestUseCase interface {
+	GetSettings(userID string) (*models.DigestSettings, error)
+	UpdateFrequency(userID, frequency string) (*models.DigestSettings, error)
+	// turns digests off for the user named in a signed unsubscribe token
+	Unsubscribe(token string) error
+	// builds the digest a user would receive now, without sending it
+	PreviewDigest(userID string) (*models.Digest, error)
+	// sends every digest that is due and returns how many went out
+	SendDueDigests(now time.Time) (int, error)
+}
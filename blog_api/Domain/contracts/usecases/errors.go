@@ -0,0 +1,83 @@
+package usecases
+
+import "errors"
+
+// common errors returned by use cases
+var (
+	ErrBlogNotFound          = errors.New("blog not found")
+	ErrNotBlogAuthor         = errors.New("only the author can perform this action on the blog")
+	ErrInvalidReviewState    = errors.New("blog is not in a state that allows this review action")
+	ErrReviewCommentRequired = errors.New("a review comment is required for this action")
+	ErrCannotReviewOwnBlog   = errors.New("you cannot review your own blog")
+
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("a role with this name already exists")
+	ErrInvalidRoleName   = errors.New("role name must be 2-30 lowercase letters, digits or underscores")
+	ErrInvalidPermission = errors.New("unknown permission")
+	ErrBuiltInRole       = errors.New("built-in roles cannot be deleted")
+	ErrRoleInUse         = errors.New("role is still assigned to users")
+	ErrAdminRoleLockout  = errors.New("the admin role must keep the user:manage and role:manage permissions")
+	ErrNotCommentAuthor  = errors.New("only the author can perform this action on the comment")
+
+	ErrReportNotFound          = errors.New("report not found")
+	ErrReportTargetNotFound    = errors.New("reported content not found")
+	ErrInvalidReportTarget     = errors.New("unknown report target type")
+	ErrInvalidReportReason     = errors.New("unknown report reason")
+	ErrReportDetailsRequired   = errors.New("details are required when the reason is other")
+	ErrCannotReportOwnContent  = errors.New("you cannot report your own content")
+	ErrAlreadyReported         = errors.New("you have already reported this")
+	ErrReportClosed            = errors.New("report has already been resolved")
+	ErrInvalidModerationAction = errors.New("moderation action is not valid for this report")
+	ErrModerationNoteRequired  = errors.New("a note is required for this moderation action")
+	ErrProtectedAccount        = errors.New("accounts that can manage users cannot be suspended through moderation")
+
+	ErrInvalidVerificationToken = errors.New("verification link is invalid or has expired")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrVerificationThrottled    = errors.New("a verification email was sent recently, please wait before requesting another")
+	ErrEmailNotVerified         = errors.New("verify your email address before posting")
+
+	ErrUserNotFound            = errors.New("user not found")
+	ErrCannotFollowSelf        = errors.New("you cannot follow yourself")
+	ErrAlreadyFollowing        = errors.New("you already follow this user")
+	ErrNotFollowing            = errors.New("you do not follow this user")
+	ErrParentCommentNotFound   = errors.New("the comment you are replying to was not found on this blog")
+	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrInvalidNotificationType = errors.New("unknown notification type")
+	ErrTooManyStreamTopics     = errors.New("too many blogs requested for one stream")
+	ErrInvalidTag              = errors.New("tag must be 1-50 characters")
+	ErrAlreadyFollowingTag     = errors.New("you already follow this tag")
+	ErrNotFollowingTag         = errors.New("you do not follow this tag")
+	ErrInvalidDigestFrequency  = errors.New("frequency must be off, daily or weekly")
+	ErrInvalidUnsubscribeToken = errors.New("unsubscribe link is invalid or has expired")
+
+	ErrInvalidTwoFactorCode      = errors.New("invalid authentication code")
+	ErrInvalidTwoFactorChallenge = errors.New("two-factor login session is invalid or has expired, sign in again")
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotStarted       = errors.New("start two-factor enrollment before confirming it")
+	ErrTwoFactorMandatory        = errors.New("two-factor authentication is required for your role and cannot be disabled")
+	ErrInvalidPassword           = errors.New("password is incorrect")
+
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used; all sessions from that login have been signed out")
+	ErrSessionNotFound     = errors.New("session not found")
+
+	ErrInvalidOAuthState  = errors.New("OAuth login session is invalid or has expired, start the login again")
+	ErrInvalidRedirectURL = errors.New("redirect URL is not allowed")
+	ErrOAuthAccountExists = errors.New("an account with this email already exists; sign in to it and link this provider from your account settings")
+	ErrOAuthLinkNotFound  = errors.New("this provider is not linked to your account")
+	ErrLastSignInMethod   = errors.New("this is your only way to sign in; set a password or link another provider first")
+
+	ErrInvalidMagicLink   = errors.New("sign-in link is invalid, has expired or was already used")
+	ErrAccountDeactivated = errors.New("account is deactivated")
+
+	ErrTooManyAttempts   = errors.New("too many failed attempts, please wait before trying again")
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+	ErrPasswordReused  = errors.New("choose a password you have not used recently")
+	ErrPasswordExpired = errors.New("your password has expired, reset it with the forgot password link")
+
+	ErrEmailTaken              = errors.New("an account with this email already exists")
+	ErrSameEmail               = errors.New("this is already your email address")
+	ErrInvalidEmailChangeToken = errors.New("email change link is invalid, has expired or was already used")
+)
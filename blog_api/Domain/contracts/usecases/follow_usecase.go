@@ -0,0 +1,11 @@
+package usecases
+
+type IFollowUseCase interface {
+	FollowUser(followerID, followeeID string) error
+	UnfollowUser(followerID, followeeID string) error
+	// returns follower and following counts
+	GetFollowStats(userID string) (int, int, error)
+	FollowTag(userID, tag string) error
+	UnfollowTag(userID, tag string) error
+	ListFollowedTags(userID string) ([]string, error)
+}
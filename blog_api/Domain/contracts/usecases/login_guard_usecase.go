@@ -0,0 +1,15 @@
+package usecases
+
+import "time"
+
+// throttles password guessing per account and per IP address; scope is one of the models.GuardScope* kinds
+type ILoginGuardUseCase interface {
+	// how long the caller must wait before trying again, with ErrTooManyAttempts when that is not zero
+	Check(scope, identifier, ip string) (time.Duration, error)
+	// reports whether the failure locked the account
+	RecordFailure(scope, identifier, ip string) (bool, error)
+	// forgets the account's failures; the IP address keeps its count so one good login cannot reset it
+	RecordSuccess(scope, identifier string) error
+	// lifts every lock and counter on a user's account
+	Unlock(userID string) error
+}
@@ -0,0 +1,10 @@
+package usecases
+
+import "blog_api/Domain/models"
+
+type IMagicLinkUseCase interface {
+	// emails a sign-in link; says nothing about whether the address has an account
+	RequestLink(email string) error
+	// exchanges a link token for its user, creating the account on first use when sign-up is allowed
+	ConsumeLink(token string) (*models.MagicLinkLogin, error)
+}
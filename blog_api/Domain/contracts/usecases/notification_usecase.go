@@ -0,0 +1,14 @@
+package usecases
+
+import "blog_api/Domain/models"
+
+type INotificationUseCase interface {
+	// stores a notification unless the actor is the recipient or the recipient opted out
+	Notify(notification *models.Notification) error
+	ListNotifications(userID string, query *models.NotificationQuery) ([]models.Notification, int, int, error)
+	UnreadCount(userID string) (int, error)
+	MarkRead(userID, notificationID string) error
+	MarkAllRead(userID string) (int, error)
+	GetPreferences(userID string) (*models.NotificationPreferences, error)
+	UpdatePreferences(userID string, types map[string]bool) (*models.NotificationPreferences, error)
+}
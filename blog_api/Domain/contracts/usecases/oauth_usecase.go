@@ -1,10 +1,18 @@
 package usecases
 
-import "blog_api/Domain/models"
+import (
+	"blog_api/Domain/models"
+	"time"
+)
 
 
 type IOAuthUseCase interface {
-	InitiateOAuthFlow(provider string) (string, error)
-	HandleOAuthCallback(provider, code, state string) (*models.OAuthLoginResult, error)
-	LinkOAuthToExistingUser(provider, code, userID string) error
+	InitiateOAuthFlow(provider, redirectURL string) (*models.OAuthAuthorization, error)
+	// flowToken is the value handed out by InitiateOAuthFlow; state must match the one inside it
+	HandleOAuthCallback(provider, code, state, flowToken string, client models.SessionClient) (*models.OAuthLoginResult, error)
+	LinkOAuthToExistingUser(provider, code, state, flowToken, userID string) error
+	ListLinkedAccounts(userID string) (*models.LinkedAccounts, error)
+	UnlinkProvider(userID, provider string) error
+	// renews provider access tokens expiring before the given time, for providers that issue refresh tokens
+	RefreshExpiringTokens(before time.Time) (int, error)
 }
@@ -0,0 +1,6 @@
+package usecases
+
+type IRealtimeUseCase interface {
+	// returns the topics a user may stream: their own plus the requested visible blogs
+	StreamTopics(userID, role string, blogIDs []string) ([]string, error)
+}
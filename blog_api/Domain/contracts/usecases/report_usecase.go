@@ -0,0 +1,10 @@
+package usecases
+
+import "blog_api/Domain/models"
+
+type IReportUseCase interface {
+	ReportContent(reporterID, targetType, targetID, reason, details string) (*models.ContentReport, error)
+	GetModerationQueue(status, targetType string, page, pageSize int) ([]models.ContentReport, int, error)
+	GetReport(reportID string) (*models.ContentReport, error)
+	ResolveReport(reportID, moderatorID, action, note string) (*models.ContentReport, error)
+}
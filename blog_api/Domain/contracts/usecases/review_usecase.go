@@ -0,0 +1,12 @@
+package usecases
+
+import "blog_api/Domain/models"
+
+type IReviewUseCase interface {
+	SubmitForReview(blogID, authorID string) error
+	GetReviewQueue(page, pageSize int) ([]models.Blog, int, error)
+	ApproveBlog(blogID, reviewerID, comment string) error
+	RejectBlog(blogID, reviewerID, comment string) error
+	RequestChanges(blogID, reviewerID, comment string) error
+	GetReviewHistory(blogID, userID, role string) ([]models.BlogReview, error)
+}
@@ -0,0 +1,11 @@
+package usecases
+
+import "blog_api/Domain/models"
+
+type IRoleUseCase interface {
+	ListRoles() ([]models.Role, error)
+	GetRole(roleID string) (*models.Role, error)
+	CreateRole(name, description string, permissions []string) (*models.Role, error)
+	UpdateRole(roleID, description string, permissions []string) (*models.Role, error)
+	DeleteRole(roleID string) error
+}
@@ -7,12 +7,16 @@ import (
 
 type ITokenUseCase interface {
 	StoreTokens(accessToken *models.AccessToken, refreshToken *models.RefreshToken) error
-	GenerateAndStoreTokens(userID, roleID string) (*models.AccessToken, *models.RefreshToken, error)
-	//generates a new access token with proper expiration
-	RefreshAccessToken(userID, roleID string) (*models.AccessToken, error)
+	// opens a session for the client and issues its first token pair
+	GenerateAndStoreTokens(userID, roleID string, client models.SessionClient) (*models.AccessToken, *models.RefreshToken, error)
+	// exchanges a refresh token for a new pair, revoking its family if it was already used
+	RotateRefreshToken(refreshToken string, client models.SessionClient) (*models.AccessToken, *models.RefreshToken, error)
 	ValidateAccessToken(token string) (bool, error)
 	ValidateRefreshToken(token string) (bool, error)
 	RevokeAccessToken(token string) error
 	RevokeRefreshToken(token string) error
 	RevokeAllUserTokens(userID string) error
+	ListSessions(userID string) ([]models.Session, error)
+	RevokeSession(userID, sessionID string) error
+	RevokeOtherSessions(userID, currentSessionID string) (int, error)
 } 
\ No newline at end of file
@@ -0,0 +1,18 @@
+package usecases
+
+import "blog_api/Domain/models"
+
+type ITwoFactorUseCase interface {
+	// returns nil when the user may be issued tokens straight after the password check
+	BeginLogin(user *models.User) (*models.TwoFactorChallenge, error)
+	// the account a pending login challenge belongs to
+	ChallengeUser(challengeToken string) (*models.User, error)
+	// a challenge stops working after a few wrong codes
+	CompleteLogin(challengeToken, code, recoveryCode string) (*models.TwoFactorLogin, error)
+	StartRequiredEnrollment(challengeToken string) (*models.TwoFactorEnrollment, error)
+	ConfirmRequiredEnrollment(challengeToken, code string) (*models.User, []string, error)
+	StartEnrollment(userID string) (*models.TwoFactorEnrollment, error)
+	ConfirmEnrollment(userID, code string) ([]string, error)
+	Disable(userID, password, code string) error
+	RegenerateRecoveryCodes(userID, code string) ([]string, error)
+}
@@ -8,8 +8,19 @@ import (
 type IUserUseCase interface {
 	RegisterUser(user *models.User) error
 	LoginUser(emailOrUsername, password string) (*models.User, error)
-	LogoutUser(userID string) error
+	LogoutUser(userID, sessionID string) error
 	ForgotPassword(email string) error
-	ResetPassword(token, newPassword string) error
+	ResetPassword(token, newPassword string) (*models.User, error)
+	// signs out every session but sessionID; initial reports that the account had no password before
+	ChangePassword(userID, sessionID, currentPassword, newPassword string) (initial bool, err error)
+	// emails a confirmation link to newEmail; the address changes only once it is followed.
+	// Reports true when an account without a password must first approve it from its current address
+	RequestEmailChange(userID, currentPassword, newEmail string) (bool, error)
+	ApproveEmailChange(token string) (*models.EmailChange, error)
+	ConfirmEmailChange(token string) (*models.EmailChange, error)
+	// restores the address from before a confirmed change and signs the user out everywhere
+	RevertEmailChange(token string) (*models.EmailChange, error)
 	UpdateUserProfile(userID string, update *models.UserProfileUpdate) (*models.User, error)
+	VerifyEmail(token string) (*models.User, error)
+	ResendVerificationEmail(userID string) error
 } 
\ No newline at end of file
@@ -15,10 +15,25 @@ type Blog struct {
 	CommentCount  int
 	ShareCount    int
 	AISuggestion  string
+	Status        string
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
 
+// Blog publication states
+const (
+	BlogStatusPublished        = "published"
+	BlogStatusPendingReview    = "pending_review"
+	BlogStatusChangesRequested = "changes_requested"
+	BlogStatusRejected         = "rejected"
+	BlogStatusHidden           = "hidden"
+)
+
+// blogs stored before review existed have no status and count as published
+func (b *Blog) IsPublished() bool {
+	return b.Status == "" || b.Status == BlogStatusPublished
+}
+
 type UploadedImage struct {
 	Filename string
 	Size     int64
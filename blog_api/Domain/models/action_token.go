@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// the verified contents of a signed, single-purpose link token
+type ActionToken struct {
+	Purpose   string
+	Subject   string
+	Data      map[string]string
+	ExpiresAt time.Time
+}
+
+// Action token purposes
+const (
+	ActionEmailVerification = "email_verification"
+	ActionDigestUnsubscribe = "digest_unsubscribe"
+	ActionTwoFactorLogin    = "two_factor_login"
+	ActionTwoFactorSetup    = "two_factor_setup"
+	ActionOAuthFlow         = "oauth_flow"
+	ActionEmailChange       = "email_change"
+	// accounts without a password approve an email change from their current address
+	ActionEmailChangeApproval = "email_change_approval"
+	ActionEmailRevert         = "email_revert"
+)
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// an append-only record of a privileged action or security event
+type AuditEntry struct {
+	ID         string
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     map[string]interface{}
+	After      map[string]interface{}
+	Details    map[string]interface{}
+	IP         string
+	UserAgent  string
+	CreatedAt  time.Time
+}
+
+// filters for browsing the audit log
+type AuditQuery struct {
+	ActorID  string
+	TargetID string
+	Action   string
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	PageSize int
+}
+
+// Audit target types
+const (
+	AuditTargetUser   = "user"
+	AuditTargetRole   = "role"
+	AuditTargetReport = "report"
+)
+
+// Audit actions
+const (
+	AuditUserPromote    = "user.promote"
+	AuditUserDemote     = "user.demote"
+	AuditUserAssignRole = "user.assign_role"
+	AuditUserDeactivate = "user.deactivate"
+	AuditUserReactivate = "user.reactivate"
+	AuditUserDelete     = "user.delete"
+	AuditUserUnlock     = "user.unlock"
+
+	AuditRoleCreate = "role.create"
+	AuditRoleUpdate = "role.update"
+	AuditRoleDelete = "role.delete"
+
+	AuditReportResolve = "report.resolve"
+
+	AuditLogin                  = "auth.login"
+	AuditLoginFailed            = "auth.login_failed"
+	AuditPasswordResetRequested = "auth.password_reset_requested"
+	AuditPasswordReset          = "auth.password_reset"
+	AuditPasswordChanged        = "auth.password_changed"
+	AuditOAuthLink              = "auth.oauth_link"
+	AuditOAuthUnlink            = "auth.oauth_unlink"
+	AuditEmailVerified          = "auth.email_verified"
+	AuditEmailChangeRequested   = "auth.email_change_requested"
+	AuditEmailChangeApproved    = "auth.email_change_approved"
+	AuditEmailChanged           = "auth.email_changed"
+	AuditEmailChangeReverted    = "auth.email_change_reverted"
+	AuditTwoFactorEnabled       = "auth.2fa_enabled"
+	AuditTwoFactorDisabled      = "auth.2fa_disabled"
+	AuditTwoFactorFailed        = "auth.2fa_failed"
+	AuditRecoveryCodeUsed       = "auth.recovery_code_used"
+	AuditRecoveryCodesRenewed   = "auth.recovery_codes_regenerated"
+	AuditRefreshTokenReused     = "auth.refresh_token_reused"
+	AuditSessionRevoke          = "auth.session_revoke"
+	AuditSessionRevokeOthers    = "auth.session_revoke_others"
+	AuditMagicLinkRequested     = "auth.magic_link_requested"
+	AuditAccountLocked          = "auth.account_locked"
+)
@@ -6,7 +6,9 @@ type Comment struct {
 	ID        string
 	BlogID    string
 	UserID    string
+	ParentID  string
 	Content   string
+	Hidden    bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
\ No newline at end of file
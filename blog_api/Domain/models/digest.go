@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Digest frequencies
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+func IsValidDigestFrequency(f string) bool {
+	return f == DigestOff || f == DigestDaily || f == DigestWeekly
+}
+
+// how long one digest covers
+func DigestPeriod(frequency string) time.Duration {
+	switch frequency {
+	case DigestDaily:
+		return 24 * time.Hour
+	case DigestWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// a user's digest subscription; users start with digests off
+type DigestSettings struct {
+	UserID     string
+	Frequency  string
+	LastSentAt *time.Time
+	UpdatedAt  time.Time
+}
+
+// a post listed in a digest
+type DigestItem struct {
+	BlogID       string
+	Title        string
+	AuthorName   string
+	Tags         []string
+	LikeCount    int
+	CommentCount int
+}
+
+// the compiled digest for one user
+type Digest struct {
+	Frequency     string
+	Since         time.Time
+	FollowedPosts []DigestItem
+	TrendingPosts []DigestItem
+}
+
+func (d *Digest) IsEmpty() bool {
+	return len(d.FollowedPosts) == 0 && len(d.TrendingPosts) == 0
+}
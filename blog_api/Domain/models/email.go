@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// a rendered email waiting in, or delivered from, the outbox
+type EmailMessage struct {
+	ID            string
+	To            string
+	Subject       string
+	TextBody      string
+	HTMLBody      string
+	Template      string
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
+
+// Outbox statuses
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSending = "sending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+)
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// failed attempts recorded against one account or IP address for one kind of request
+type LoginThrottle struct {
+	Key            string
+	Failures       int
+	FirstFailureAt time.Time
+	LastFailureAt  time.Time
+	LockedUntil    *time.Time
+}
+
+// Kinds of request protected against guessing
+const (
+	GuardScopeLogin          = "login"
+	GuardScopeForgotPassword = "forgot_password"
+	GuardScopeResetPassword  = "reset_password"
+	// a signed-in user confirming a sensitive change; keyed by user ID rather than email or username
+	GuardScopeReauthenticate = "reauthenticate"
+)
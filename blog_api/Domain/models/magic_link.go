@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// an emailed sign-in link; only the hash of its token is stored
+type MagicLink struct {
+	ID        string
+	Email     string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// the outcome of following a sign-in link
+type MagicLinkLogin struct {
+	User *User
+	// the account was created by this link
+	IsNewUser bool
+}
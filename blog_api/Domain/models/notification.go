@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// an in-app notification for a user about someone else's activity
+type Notification struct {
+	ID        string
+	UserID    string
+	ActorID   string
+	Type      string
+	BlogID    string
+	CommentID string
+	Message   string
+	Read      bool
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+// Notification types
+const (
+	NotificationComment = "comment"
+	NotificationReply   = "reply"
+	NotificationLike    = "like"
+	NotificationShare   = "share"
+	NotificationFollow  = "follow"
+)
+
+var NotificationTypes = []string{
+	NotificationComment,
+	NotificationReply,
+	NotificationLike,
+	NotificationShare,
+	NotificationFollow,
+}
+
+func IsValidNotificationType(t string) bool {
+	for _, nt := range NotificationTypes {
+		if nt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// which notification types a user wants; types missing from the map are enabled
+type NotificationPreferences struct {
+	UserID    string
+	Types     map[string]bool
+	UpdatedAt time.Time
+}
+
+func (p *NotificationPreferences) Enabled(notificationType string) bool {
+	if p == nil || p.Types == nil {
+		return true
+	}
+	enabled, ok := p.Types[notificationType]
+	return !ok || enabled
+}
+
+// notification listing filters
+type NotificationQuery struct {
+	UnreadOnly bool
+	Page       int
+	PageSize   int
+}
+
+// a user following another user
+type Follow struct {
+	FollowerID string
+	FolloweeID string
+	CreatedAt  time.Time
+}
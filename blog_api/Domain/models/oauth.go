@@ -8,14 +8,18 @@ type OAuthToken struct {
 	RefreshToken string
 	ExpiresIn    int
 	TokenType    string
+	// signed identity assertion from OpenID Connect providers; empty for plain OAuth2
+	IDToken string
 }
 
 //user information from OAuth provider
 type OAuthUserInfo struct {
 	ProviderID string
 	Email      string
-	Name       string
-	Picture    string
+	// true only when the provider asserts it confirmed the address; required before linking by email
+	EmailVerified bool
+	Name          string
+	Picture       string
 }
 
 // an OAuth user account linked to a platform user
@@ -40,6 +44,32 @@ type OAuthLoginResult struct {
 	AccessToken  string
 	RefreshToken string
 	IsNewUser    bool
+	// set instead of the tokens when the account needs a second factor
+	TwoFactor *TwoFactorChallenge
+	// where the browser goes once signed in; empty means answer with JSON
+	RedirectURL string
+}
+
+// the provider accounts linked to a user
+type LinkedAccounts struct {
+	Accounts []OAuthUser
+	// without a password the last linked account cannot be removed
+	HasPassword bool
+}
+
+// a started OAuth login; FlowToken goes into a short-lived cookie that the callback must present
+type OAuthAuthorization struct {
+	AuthURL   string
+	FlowToken string
+	ExpiresAt time.Time
+}
+
+// what the browser proved it started: the state sent to the provider, the PKCE verifier and where to return to
+type OAuthFlow struct {
+	Provider     string
+	State        string
+	CodeVerifier string
+	RedirectURL  string
 }
 
 // OAuthProvider constants
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// rules every new password has to follow
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// rejects passwords containing the username, the email's local part or the user's names
+	DisallowPersonalInfo bool
+	// how many of the user's latest passwords, the current one included, cannot be reused; 0 turns the check off
+	HistorySize int
+	// how long a password stays valid before it has to be reset; 0 means passwords never expire
+	MaxAge time.Duration
+}
+
+// the policy used when no PASSWORD_* settings are given
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:            8,
+		MaxLength:            72,
+		RequireUpper:         true,
+		RequireLower:         true,
+		RequireDigit:         true,
+		DisallowPersonalInfo: true,
+		HistorySize:          5,
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+// Permission constants granted to roles
+const (
+	PermAIUse           = "ai:use"
+	PermBlogReview      = "blog:review"
+	PermBlogDeleteAny   = "blog:delete:any"
+	PermCommentModerate = "comment:moderate"
+	PermUserManage      = "user:manage"
+	PermRoleManage      = "role:manage"
+	PermAuditRead       = "audit:read"
+	PermContentModerate = "content:moderate"
+)
+
+// every permission a role may be granted
+var AllPermissions = []string{
+	PermAIUse,
+	PermBlogReview,
+	PermBlogDeleteAny,
+	PermCommentModerate,
+	PermUserManage,
+	PermRoleManage,
+	PermAuditRead,
+	PermContentModerate,
+}
+
+// permissions seeded for the built-in roles
+var DefaultRolePermissions = map[string][]string{
+	RoleUser:     {PermAIUse},
+	RoleReviewer: {PermAIUse, PermBlogReview},
+	RoleAdmin:    AllPermissions,
+}
+
+// reports whether a permission name is known
+func IsValidPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
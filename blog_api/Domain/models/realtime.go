@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// a server-sent event; Data holds the JSON payload
+type RealtimeEvent struct {
+	ID        string
+	Topic     string
+	Type      string
+	Data      []byte
+	CreatedAt time.Time
+}
+
+// Realtime event types
+const (
+	EventCommentCreated      = "comment.created"
+	EventBlogCounters        = "blog.counters"
+	EventNotificationCreated = "notification.created"
+)
+
+// events about a blog: new comments and counter changes
+func BlogTopic(blogID string) string {
+	return "blog:" + blogID
+}
+
+// events only the user may see, such as their notifications
+func UserTopic(userID string) string {
+	return "user:" + userID
+}
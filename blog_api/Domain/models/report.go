@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// a moderation case aggregating every open report against one piece of content or user
+type ContentReport struct {
+	ID             string
+	TargetType     string
+	TargetID       string
+	TargetOwnerID  string
+	Status         string
+	ReportCount    int
+	ReasonCounts   map[string]int
+	Reports        []ReportEntry
+	AutoHidden     bool
+	HiddenFrom     string // blog status to restore when an auto-hide is dismissed
+	Resolution     string
+	ResolutionNote string
+	ResolvedBy     string
+	ResolvedAt     *time.Time
+	CreatedAt      time.Time
+	LastReportedAt time.Time
+}
+
+// a single reader's report
+type ReportEntry struct {
+	ReporterID string
+	Reason     string
+	Details    string
+	CreatedAt  time.Time
+}
+
+// Report target types
+const (
+	ReportTargetBlog    = "blog"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+)
+
+// Report statuses
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusActioned  = "actioned"
+)
+
+// Report reasons
+const (
+	ReportReasonSpam           = "spam"
+	ReportReasonHarassment     = "harassment"
+	ReportReasonHateSpeech     = "hate_speech"
+	ReportReasonMisinformation = "misinformation"
+	ReportReasonSexualContent  = "sexual_content"
+	ReportReasonViolence       = "violence"
+	ReportReasonOther          = "other"
+)
+
+var ReportReasons = []string{
+	ReportReasonSpam,
+	ReportReasonHarassment,
+	ReportReasonHateSpeech,
+	ReportReasonMisinformation,
+	ReportReasonSexualContent,
+	ReportReasonViolence,
+	ReportReasonOther,
+}
+
+// Moderator actions
+const (
+	ModerationDismiss = "dismiss"
+	ModerationHide    = "hide"
+	ModerationDelete  = "delete"
+	ModerationWarn    = "warn"
+	ModerationSuspend = "suspend"
+)
+
+// reports whether a reason category is known
+func IsValidReportReason(reason string) bool {
+	for _, r := range ReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// a recorded state transition of a blog in the review workflow
+type BlogReview struct {
+	ID         string
+	BlogID     string
+	AuthorID   string
+	ActorID    string
+	Action     string
+	FromStatus string
+	ToStatus   string
+	Comment    string
+	CreatedAt  time.Time
+}
+
+// Review actions
+const (
+	ReviewActionSubmit         = "submit"
+	ReviewActionApprove        = "approve"
+	ReviewActionReject         = "reject"
+	ReviewActionRequestChanges = "request_changes"
+)
@@ -4,14 +4,32 @@ import "time"
 
 // Role constants for user roles
 const (
-	RoleUser  = "user"
-	RoleAdmin = "admin"
+	RoleUser     = "user"
+	RoleAdmin    = "admin"
+	RoleReviewer = "reviewer"
 )
 
 
 type Role struct {
-	ID        string
-	Role      string    //"admin", "user"
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          string
+	Role        string    //"admin", "reviewer", "user" or a custom role name
+	Description string
+	Permissions []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// reports whether the role grants a permission
+func (r *Role) HasPermission(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// built-in roles cannot be deleted
+func IsBuiltInRole(name string) bool {
+	return name == RoleUser || name == RoleAdmin || name == RoleReviewer
 }
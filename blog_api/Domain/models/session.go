@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// a signed-in device; every token rotated from one login belongs to the same session
+type Session struct {
+	ID         string
+	UserID     string
+	UserAgent  string
+	Device     string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// where a login or refresh request came from
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
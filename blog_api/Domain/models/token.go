@@ -1,6 +1,7 @@
 package models
 
 import (
+	"crypto"
 	"time"
 )
 
@@ -19,16 +20,38 @@ type AccessToken struct {
 	ID        string
 	UserID    string
 	Token     string
+	JTI       string
+	FamilyID  string
 	ExpiresAt time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
 type RefreshToken struct {
-	ID        string
-	UserID    string
-	Token     string
+	ID     string
+	UserID string
+	Token  string
+	// every token rotated from the same login shares a family
+	FamilyID string
+	// set once the token has been exchanged; presenting it again means it was stolen
+	RotatedAt *time.Time
 	ExpiresAt time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
-} 
\ No newline at end of file
+}
+
+// an access token revoked before its expiry; middleware keeps these in memory until they expire
+type RevokedAccessToken struct {
+	JTI       string
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+// a public key other services can use to verify tokens we sign; published as a JWK
+type PublicSigningKey struct {
+	KeyID     string
+	Algorithm string // "RS256" or "EdDSA"
+	Key       crypto.PublicKey
+	// when the key starts signing; keys are published ahead of time so verifiers can cache them
+	NotBefore time.Time
+}
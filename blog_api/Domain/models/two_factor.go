@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Two-factor challenge kinds returned by a password login that needs a second step
+const (
+	TwoFactorChallengeCode  = "totp"
+	TwoFactorChallengeSetup = "setup"
+)
+
+// Second-factor methods accepted when completing a login
+const (
+	TwoFactorMethodTOTP         = "totp"
+	TwoFactorMethodRecoveryCode = "recovery_code"
+)
+
+// a short-lived token standing in for a session until the second factor is checked
+type TwoFactorChallenge struct {
+	Token     string
+	Kind      string
+	ExpiresAt time.Time
+}
+
+// the secret a user adds to their authenticator app
+type TwoFactorEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// the outcome of a successful second-factor check during login
+type TwoFactorLogin struct {
+	User              *User
+	Method            string
+	RecoveryCodesLeft int
+}
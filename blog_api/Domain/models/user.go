@@ -13,17 +13,46 @@ type User struct {
 	LastName             string
 	Email                string
 	Password             string
+	// hashes of earlier passwords, newest first, kept to block their reuse
+	PasswordHistory      []string
+	PasswordChangedAt    *time.Time
 	Bio                  string
 	ProfilePicture       string
 	ContactInfo          string
 	IsActive             bool
 	EmailVerified        bool
+	VerificationSentAt   *time.Time
+	// set by every email change and revert, so links issued before it stop working
+	EmailChangedAt       *time.Time
+	TwoFactorEnabled     bool
+	TwoFactorSecret      string
+	TwoFactorPending     string
+	RecoveryCodes        []string
+	TwoFactorLastStep    int64
 	ResetPasswordToken   string
 	ResetPasswordExpires *time.Time
+	DeletedAt            *time.Time
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 } 
 
+// admin user listing filters
+type UserQuery struct {
+	Search         string
+	RoleID         string
+	IsActive       *bool
+	IncludeDeleted bool
+	Page           int
+	PageSize       int
+}
+
+// an address change confirmed or reverted through an emailed link
+type EmailChange struct {
+	UserID   string
+	OldEmail string
+	NewEmail string
+}
+
 type UserProfileUpdate struct {
 	FirstName      string
 	LastName       string
@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+type ActionTokenService struct {
+	key []byte
+}
+
+// derives a key distinct from the access token key so the two token kinds can never be swapped.
+// Production refuses to fall back to the built-in development secret.
+func NewActionTokenService() (services.IActionTokenService, error) {
+	secret := os.Getenv("ACTION_TOKEN_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET_KEY")
+	}
+	if weakJWTSecrets[secret] || len(secret) < minJWTSecretLength {
+		if IsProduction() {
+			return nil, errors.New("ACTION_TOKEN_SECRET (or JWT_SECRET_KEY) must be a random value of at least 32 characters")
+		}
+		if secret == "" {
+			secret = "your-secret-key"
+		}
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("action-token"))
+	return &ActionTokenService{key: mac.Sum(nil)}, nil
+}
+
+// signs a token bound to a purpose and subject
+func (s *ActionTokenService) Issue(purpose, subject string, data map[string]string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"type":    "action",
+		"purpose": purpose,
+		"sub":     subject,
+		"iat":     now.Unix(),
+		"exp":     now.Add(ttl).Unix(),
+	}
+	if len(data) > 0 {
+		claims["data"] = data
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+}
+
+// checks the signature, expiry and purpose of a token
+func (s *ActionTokenService) Verify(purpose, tokenString string) (*models.ActionToken, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return s.key, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["type"] != "action" || claims["purpose"] != purpose {
+		return nil, errors.New("invalid token purpose")
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errors.New("invalid token subject")
+	}
+
+	result := &models.ActionToken{
+		Purpose: purpose,
+		Subject: subject,
+		Data:    map[string]string{},
+	}
+	if exp, ok := claims["exp"].(float64); ok {
+		result.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	if data, ok := claims["data"].(map[string]interface{}); ok {
+		for k, v := range data {
+			if str, ok := v.(string); ok {
+				result.Data[k] = str
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"context"
+	"log"
+	"time"
+)
+
+// retry settings for the outbox worker
+type EmailOutboxConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// how long a claimed message stays locked before another worker may retry it
+	Lease time.Duration
+}
+
+func DefaultEmailOutboxConfig() EmailOutboxConfig {
+	return EmailOutboxConfig{
+		Interval:    10 * time.Second,
+		BatchSize:   20,
+		MaxAttempts: 8,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  time.Hour,
+		Lease:       2 * time.Minute,
+	}
+}
+
+// delivers queued emails, retrying failures with exponential backoff
+type EmailOutboxWorker struct {
+	outbox repositories.IEmailOutboxRepository
+	mailer services.IMailer
+	config EmailOutboxConfig
+}
+
+func NewEmailOutboxWorker(outbox repositories.IEmailOutboxRepository, mailer services.IMailer, config EmailOutboxConfig) *EmailOutboxWorker {
+	return &EmailOutboxWorker{
+		outbox: outbox,
+		mailer: mailer,
+		config: config,
+	}
+}
+
+// polls the outbox until ctx is cancelled
+func (w *EmailOutboxWorker) Run(ctx context.Context) {
+	RunPeriodically(ctx, w.config.Interval, "email outbox", func(ctx context.Context) error {
+		_, err := w.ProcessDue(ctx)
+		return err
+	})
+}
+
+// sends every message that is due, returning how many were delivered
+func (w *EmailOutboxWorker) ProcessDue(ctx context.Context) (int, error) {
+	sent := 0
+	for ctx.Err() == nil {
+		messages, err := w.outbox.ClaimDue(time.Now(), w.config.BatchSize, w.config.Lease)
+		if err != nil {
+			return sent, err
+		}
+		for i := range messages {
+			msg := &messages[i]
+			attempts := msg.Attempts + 1
+			if err := w.mailer.Send(msg); err != nil {
+				giveUp := attempts >= w.config.MaxAttempts
+				if giveUp {
+					log.Printf("email outbox: giving up on %s to %s after %d attempts: %v", msg.Template, msg.To, attempts, err)
+				}
+				if err := w.outbox.MarkFailed(msg.ID, attempts, err.Error(), time.Now().Add(w.backoff(attempts)), giveUp); err != nil {
+					log.Printf("email outbox: failed to record attempt for %s: %v", msg.ID, err)
+				}
+				continue
+			}
+			if err := w.outbox.MarkSent(msg.ID, time.Now()); err != nil {
+				log.Printf("email outbox: failed to mark %s as sent: %v", msg.ID, err)
+			}
+			sent++
+		}
+		if len(messages) < w.config.BatchSize {
+			break
+		}
+	}
+	return sent, nil
+}
+
+// doubles the delay after each failed attempt, up to MaxBackoff
+func (w *EmailOutboxWorker) backoff(attempts int) time.Duration {
+	delay := w.config.BaseBackoff
+	for i := 1; i < attempts && delay < w.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.config.MaxBackoff {
+		delay = w.config.MaxBackoff
+	}
+	return delay
+}
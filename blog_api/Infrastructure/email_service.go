@@ -1,119 +1,257 @@
 package infrastructure
 
 import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"bytes"
+	"embed"
 	"fmt"
-	"net/smtp"
-	"os"
-	"strconv"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+	"time"
 )
 
-type EmailService struct{}
+//go:embed email_templates/*
+var emailTemplateFS embed.FS
 
-func NewEmailService() *EmailService {
-	return &EmailService{}
+// Email template names
+const (
+	EmailTemplatePasswordReset    = "password_reset"
+	EmailTemplatePasswordChanged  = "password_changed"
+	EmailTemplateReviewDecision   = "review_decision"
+	EmailTemplateModerationNotice = "moderation_notice"
+	EmailTemplateVerification     = "verification"
+	EmailTemplateDigest           = "digest"
+	EmailTemplateMagicLink        = "magic_link"
+	EmailTemplateAccountLocked    = "account_locked"
+	EmailTemplateEmailChange      = "email_change"
+	EmailTemplateEmailApproval    = "email_change_approval"
+	EmailTemplateEmailChanged     = "email_changed"
+)
+
+var emailTemplateNames = []string{
+	EmailTemplatePasswordReset,
+	EmailTemplatePasswordChanged,
+	EmailTemplateReviewDecision,
+	EmailTemplateModerationNotice,
+	EmailTemplateVerification,
+	EmailTemplateDigest,
+	EmailTemplateMagicLink,
+	EmailTemplateAccountLocked,
+	EmailTemplateEmailChange,
+	EmailTemplateEmailApproval,
+	EmailTemplateEmailChanged,
 }
 
-// sends a password reset email to the user
-func (es *EmailService) SendPasswordResetEmail(email, resetToken string) error {
-	// Get SMTP configuration from environment variables
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-	smtpUsername := os.Getenv("SMTP_USERNAME")
-	smtpPassword := os.Getenv("SMTP_PASSWORD")
-	resetURL := os.Getenv("RESET_URL") 
+// renders emails from templates and queues them in the outbox for the worker to deliver
+type EmailService struct {
+	config        EmailConfig
+	outbox        repositories.IEmailOutboxRepository
+	htmlTemplates map[string]*htmltemplate.Template
+	textTemplates map[string]*texttemplate.Template
+}
 
-	if smtpHost == "" || smtpPort == "" || smtpUsername == "" || smtpPassword == "" || resetURL == "" {
-		fmt.Printf("Password reset token for %s: %s\n", email, resetToken)
-		return nil
+func NewEmailService(config EmailConfig, outbox repositories.IEmailOutboxRepository) (*EmailService, error) {
+	es := &EmailService{
+		config:        config,
+		outbox:        outbox,
+		htmlTemplates: make(map[string]*htmltemplate.Template),
+		textTemplates: make(map[string]*texttemplate.Template),
 	}
-
-	port, err := strconv.Atoi(smtpPort)
-	if err != nil {
-		return fmt.Errorf("invalid SMTP port: %v", err)
-	}
-
-	// Email content with HTML formatting
-	subject := "Password Reset Request"
-	resetLink := fmt.Sprintf("%s?token=%s", resetURL, resetToken)
-
-	body := fmt.Sprintf(`
-		<html>
-		<body style="font-family: Arial, sans-serif; line-height: 1.6;">
-			<h2>Password Reset Request</h2>
-			<p>Hello,</p>
-			<p>You have requested to reset your password. Click the button below to reset it:</p>
-			<p>
-				<a href="%s" style="background-color: #4CAF50; color: white; padding: 10px 20px;
-				text-decoration: none; border-radius: 5px;">Reset Password</a>
-			</p>
-			<p>If the button doesn’t work, copy and paste this link into your browser:</p>
-			<p><a href="%s">%s</a></p>
-			<p>This link will expire in 1 hour.</p>
-			<p>If you didn't request this password reset, please ignore this email.</p>
-			<br>
-			<p>Best regards,<br>Your Blog Team</p>
-		</body>
-		</html>
-	`, resetLink, resetLink, resetLink)
-
-	// Full email message (with MIME type for HTML)
-	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n%s",
-		smtpUsername, email, subject, body)
-
-	// Set up authentication
-	auth := smtp.PlainAuth("", smtpUsername, smtpPassword, smtpHost)
-
-	// Send email
-	err = smtp.SendMail(fmt.Sprintf("%s:%d", smtpHost, port), auth, smtpUsername, []string{email}, []byte(message))
-	if err != nil {
-		return fmt.Errorf("failed to send email: %v", err)
+	for _, name := range emailTemplateNames {
+		htmlTmpl, err := htmltemplate.ParseFS(emailTemplateFS, "email_templates/layout.html", "email_templates/"+name+".html")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse email template %s: %v", name, err)
+		}
+		textTmpl, err := texttemplate.ParseFS(emailTemplateFS, "email_templates/layout.txt", "email_templates/"+name+".txt")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse email template %s: %v", name, err)
+		}
+		es.htmlTemplates[name] = htmlTmpl
+		es.textTemplates[name] = textTmpl
 	}
+	return es, nil
+}
 
-	return nil
+// sends a password reset email to the user
+func (es *EmailService) SendPasswordResetEmail(email, resetToken string) error {
+	return es.enqueue(email, EmailTemplatePasswordReset, "Password Reset Request", map[string]interface{}{
+		"Link": withToken(es.config.ResetURL, resetToken),
+	})
 }
 
 // sends a confirmation email when password is changed
 func (es *EmailService) SendPasswordChangedEmail(email string) error {
-	// Get SMTP configuration from environment variables
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-	smtpUsername := os.Getenv("SMTP_USERNAME")
-	smtpPassword := os.Getenv("SMTP_PASSWORD")
+	return es.enqueue(email, EmailTemplatePasswordChanged, "Password Changed Successfully", nil)
+}
 
-	if smtpHost == "" || smtpPort == "" || smtpUsername == "" || smtpPassword == "" {
-		fmt.Printf("Password changed confirmation sent to: %s\n", email)
-		return nil
+// notifies an author about a review decision on their blog
+func (es *EmailService) SendReviewDecisionEmail(email, blogTitle, decision, comment string) error {
+	headlines := map[string]string{
+		"approve":         "Your post has been published",
+		"reject":          "Your post was not accepted",
+		"request_changes": "Changes requested on your post",
 	}
+	subject, ok := headlines[decision]
+	if !ok {
+		subject = "Update on your post"
+	}
+	return es.enqueue(email, EmailTemplateReviewDecision, subject, map[string]interface{}{
+		"BlogTitle": blogTitle,
+		"Comment":   comment,
+	})
+}
 
-	port, err := strconv.Atoi(smtpPort)
-	if err != nil {
-		return fmt.Errorf("invalid SMTP port: %v", err)
+// informs a user about a moderation action taken on their content or account
+func (es *EmailService) SendModerationNoticeEmail(email, action, contentType, note string) error {
+	headlines := map[string]string{
+		"hide":    "Your %s has been hidden",
+		"delete":  "Your %s has been removed",
+		"warn":    "A warning about your %s",
+		"suspend": "Your account has been suspended",
 	}
+	headline, ok := headlines[action]
+	if !ok {
+		headline = "A moderation update about your %s"
+	}
+	subject := headline
+	if action != "suspend" {
+		subject = fmt.Sprintf(headline, contentType)
+	}
+	return es.enqueue(email, EmailTemplateModerationNotice, subject, map[string]interface{}{
+		"Note": note,
+	})
+}
 
-	subject := "Password Changed Successfully"
+// sends a one-time sign-in link
+func (es *EmailService) SendMagicLinkEmail(email, token string, ttl time.Duration) error {
+	return es.enqueue(email, EmailTemplateMagicLink, "Your sign-in link", map[string]interface{}{
+		"Link":    withToken(es.config.MagicLinkURL, token),
+		"Minutes": int(ttl.Minutes()),
+	})
+}
 
-	body := `
-		<html>
-		<body style="font-family: Arial, sans-serif; line-height: 1.6;">
-			<h2>Password Changed Successfully</h2>
-			<p>Hello,</p>
-			<p>Your password has been successfully changed.</p>
-			<p>If you didn't make this change, please contact support immediately.</p>
-			<br>
-			<p>Best regards,<br>Your Blog Team</p>
-		</body>
-		</html>
-	`
+// warns the owner that sign-in was locked after repeated wrong passwords
+func (es *EmailService) SendAccountLockedEmail(email string, until time.Time) error {
+	return es.enqueue(email, EmailTemplateAccountLocked, "Sign-in to your account was locked", map[string]interface{}{
+		"Until": until.UTC().Format("2006-01-02 15:04 UTC"),
+		"Link":  es.config.ResetURL,
+	})
+}
 
-	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n%s",
-		smtpUsername, email, subject, body)
+// sends the email verification link
+func (es *EmailService) SendVerificationEmail(email, token string) error {
+	return es.enqueue(email, EmailTemplateVerification, "Verify your email address", map[string]interface{}{
+		"Link": withToken(es.config.VerifyURL, token),
+	})
+}
 
-	auth := smtp.PlainAuth("", smtpUsername, smtpPassword, smtpHost)
+// sends the link confirming a new address to that address
+func (es *EmailService) SendEmailChangeConfirmEmail(email, token string) error {
+	return es.enqueue(email, EmailTemplateEmailChange, "Confirm your new email address", map[string]interface{}{
+		"Link": withToken(es.config.EmailChangeURL, token),
+	})
+}
 
-	err = smtp.SendMail(fmt.Sprintf("%s:%d", smtpHost, port), auth, smtpUsername, []string{email}, []byte(message))
-	if err != nil {
-		return fmt.Errorf("failed to send email: %v", err)
+// asks the current address to approve a change requested without a password
+func (es *EmailService) SendEmailChangeApprovalEmail(email, newEmail, token string, ttl time.Duration) error {
+	return es.enqueue(email, EmailTemplateEmailApproval, "Approve your email address change", map[string]interface{}{
+		"NewEmail": newEmail,
+		"Link":     withToken(es.config.EmailApproveURL, token),
+		"Minutes":  int(ttl.Minutes()),
+	})
+}
+
+// tells the previous address about a change, with a link to undo it
+func (es *EmailService) SendEmailChangedEmail(oldEmail, newEmail, revertToken string, ttl time.Duration) error {
+	return es.enqueue(oldEmail, EmailTemplateEmailChanged, "Your email address was changed", map[string]interface{}{
+		"NewEmail": newEmail,
+		"Link":     withToken(es.config.EmailRevertURL, revertToken),
+		"Days":     int(ttl.Hours() / 24),
+	})
+}
+
+// sends a daily or weekly digest of posts
+func (es *EmailService) SendDigestEmail(email string, digest *models.Digest, unsubscribeToken string) error {
+	subject := "Your weekly digest"
+	if digest.Frequency == models.DigestDaily {
+		subject = "Your daily digest"
+	}
+	return es.enqueue(email, EmailTemplateDigest, subject, map[string]interface{}{
+		"Frequency":       digest.Frequency,
+		"Since":           digest.Since,
+		"FollowedPosts":   es.digestLinks(digest.FollowedPosts),
+		"TrendingPosts":   es.digestLinks(digest.TrendingPosts),
+		"UnsubscribeLink": withToken(es.config.UnsubscribeURL, unsubscribeToken),
+	})
+}
+
+type digestLink struct {
+	models.DigestItem
+	Link string
+}
+
+func (es *EmailService) digestLinks(items []models.DigestItem) []digestLink {
+	links := make([]digestLink, 0, len(items))
+	for _, item := range items {
+		links = append(links, digestLink{
+			DigestItem: item,
+			Link:       strings.TrimRight(es.config.BlogURL, "/") + "/" + url.PathEscape(item.BlogID),
+		})
+	}
+	return links
+}
+
+// renders both bodies of a template; the subject doubles as the heading
+func (es *EmailService) Render(name, subject string, data map[string]interface{}) (string, string, error) {
+	htmlTmpl, ok := es.htmlTemplates[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown email template %q", name)
+	}
+	if data == nil {
+		data = map[string]interface{}{}
 	}
+	data["Title"] = subject
 
+	var textBody, htmlBody bytes.Buffer
+	if err := es.textTemplates[name].ExecuteTemplate(&textBody, "layout", data); err != nil {
+		return "", "", fmt.Errorf("failed to render email %s: %v", name, err)
+	}
+	if err := htmlTmpl.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return "", "", fmt.Errorf("failed to render email %s: %v", name, err)
+	}
+	return textBody.String(), htmlBody.String(), nil
+}
+
+// renders the email and stores it in the outbox
+func (es *EmailService) enqueue(email, name, subject string, data map[string]interface{}) error {
+	textBody, htmlBody, err := es.Render(name, subject, data)
+	if err != nil {
+		return err
+	}
+	message := &models.EmailMessage{
+		To:       email,
+		Subject:  subject,
+		TextBody: textBody,
+		HTMLBody: htmlBody,
+		Template: name,
+	}
+	if err := es.outbox.Enqueue(message); err != nil {
+		return fmt.Errorf("failed to queue email: %v", err)
+	}
 	return nil
 }
+
+// appends the token as a query parameter, keeping any query the base URL already has
+func withToken(base, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return fmt.Sprintf("%s?token=%s", base, url.QueryEscape(token))
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
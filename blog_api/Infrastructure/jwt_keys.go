@@ -0,0 +1,345 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// secrets that ship in code or examples and must never sign production tokens
+var weakJWTSecrets = map[string]bool{
+	"your-secret-key": true,
+	"change_me_dev_only_please_use_long_random": true,
+}
+
+const minJWTSecretLength = 32
+
+// how the JWT service obtains its keys
+type JWTConfig struct {
+	// JSON file listing asymmetric keys and when each becomes active; enables RS256/EdDSA
+	KeysFile string
+	// HS256 secret; with a keys file it only verifies tokens issued before the switch
+	Secret string
+	// how long a key keeps verifying tokens after a newer key takes over signing
+	GracePeriod time.Duration
+	Production  bool
+}
+
+// reads JWT_KEYS_FILE, JWT_SECRET_KEY and JWT_KEY_GRACE_PERIOD
+func LoadJWTConfig() (JWTConfig, error) {
+	config := JWTConfig{
+		KeysFile:    os.Getenv("JWT_KEYS_FILE"),
+		Secret:      os.Getenv("JWT_SECRET_KEY"),
+		GracePeriod: 7 * 24 * time.Hour, // refresh token lifetime
+		Production:  IsProduction(),
+	}
+	if v := os.Getenv("JWT_KEY_GRACE_PERIOD"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return config, fmt.Errorf("invalid JWT_KEY_GRACE_PERIOD %q", v)
+		}
+		config.GracePeriod = d
+	}
+	return config, nil
+}
+
+// true when ENV says this is a production deployment
+func IsProduction() bool {
+	env := strings.ToLower(os.Getenv("ENV"))
+	return env == "production" || env == "prod"
+}
+
+type jwtKey struct {
+	kid        string
+	method     jwt.SigningMethod
+	signKey    interface{}
+	verifyKey  interface{}
+	notBefore  time.Time
+	verifyOnly bool
+}
+
+// JWTKeySet holds every key the service may sign or verify with, ordered by activation time.
+// The newest active key signs; a superseded key still verifies for the grace period.
+type JWTKeySet struct {
+	keys  []*jwtKey
+	grace time.Duration
+}
+
+type jwtKeysFile struct {
+	Keys []struct {
+		KID            string    `json:"kid"`
+		PrivateKeyFile string    `json:"private_key_file"`
+		PublicKeyFile  string    `json:"public_key_file"`
+		NotBefore      time.Time `json:"not_before"`
+	} `json:"keys"`
+}
+
+func NewJWTKeySet(config JWTConfig) (*JWTKeySet, error) {
+	set := &JWTKeySet{grace: config.GracePeriod}
+
+	if config.KeysFile != "" {
+		if err := set.loadKeysFile(config.KeysFile); err != nil {
+			return nil, err
+		}
+	}
+
+	secret := config.Secret
+	weak := weakJWTSecrets[secret] || len(secret) < minJWTSecretLength
+	if config.Production && weak && (secret != "" || config.KeysFile == "") {
+		return nil, errors.New("no usable JWT key configured: set JWT_KEYS_FILE or a random JWT_SECRET_KEY of at least 32 characters")
+	}
+	if config.KeysFile == "" {
+		if secret == "" {
+			secret = "your-secret-key"
+		}
+		if weak {
+			log.Printf("Warning: JWTs are signed with a weak development secret; set JWT_KEYS_FILE before deploying")
+		}
+	}
+	if secret != "" {
+		set.keys = append(set.keys, &jwtKey{
+			kid:       hmacKeyID(secret),
+			method:    jwt.SigningMethodHS256,
+			signKey:   []byte(secret),
+			verifyKey: []byte(secret),
+			// with asymmetric keys configured the secret only verifies tokens signed before the switch
+			verifyOnly: config.KeysFile != "",
+		})
+	}
+
+	sort.SliceStable(set.keys, func(i, j int) bool { return set.keys[i].notBefore.Before(set.keys[j].notBefore) })
+	if _, err := set.signingKey(time.Now()); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func (s *JWTKeySet) loadKeysFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read JWT keys file: %w", err)
+	}
+	var file jwtKeysFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return fmt.Errorf("parse JWT keys file: %w", err)
+	}
+	if len(file.Keys) == 0 {
+		return errors.New("JWT keys file lists no keys")
+	}
+
+	dir := filepath.Dir(path)
+	seen := map[string]bool{}
+	for _, entry := range file.Keys {
+		if entry.KID == "" || seen[entry.KID] {
+			return fmt.Errorf("JWT keys need a unique kid (got %q)", entry.KID)
+		}
+		seen[entry.KID] = true
+
+		key := &jwtKey{kid: entry.KID, notBefore: entry.NotBefore}
+		switch {
+		case entry.PrivateKeyFile != "":
+			private, err := readPrivateKey(resolvePath(dir, entry.PrivateKeyFile))
+			if err != nil {
+				return fmt.Errorf("JWT key %s: %w", entry.KID, err)
+			}
+			key.signKey = private
+			key.verifyKey = private.(crypto.Signer).Public()
+		case entry.PublicKeyFile != "":
+			public, err := readPublicKey(resolvePath(dir, entry.PublicKeyFile))
+			if err != nil {
+				return fmt.Errorf("JWT key %s: %w", entry.KID, err)
+			}
+			key.verifyKey = public
+			key.verifyOnly = true
+		default:
+			return fmt.Errorf("JWT key %s needs private_key_file or public_key_file", entry.KID)
+		}
+
+		switch key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			key.method = jwt.SigningMethodRS256
+		case ed25519.PublicKey:
+			key.method = SigningMethodEdDSA
+		case *ecdsa.PublicKey:
+			return fmt.Errorf("JWT key %s: ECDSA keys are not supported, use RSA or Ed25519", entry.KID)
+		default:
+			return fmt.Errorf("JWT key %s: unsupported key type", entry.KID)
+		}
+		s.keys = append(s.keys, key)
+	}
+	return nil
+}
+
+// the newest key allowed to sign at now
+func (s *JWTKeySet) signingKey(now time.Time) (*jwtKey, error) {
+	var current *jwtKey
+	for _, k := range s.keys {
+		if !k.verifyOnly && !k.notBefore.After(now) {
+			current = k
+		}
+	}
+	if current == nil {
+		return nil, errors.New("no JWT signing key is active yet")
+	}
+	return current, nil
+}
+
+// the key a token names, if it may still verify tokens at now
+func (s *JWTKeySet) verificationKey(kid string, now time.Time) *jwtKey {
+	for _, k := range s.keys {
+		if k.kid == kid && s.verifies(k, now) {
+			return k
+		}
+	}
+	return nil
+}
+
+func (s *JWTKeySet) verifies(k *jwtKey, now time.Time) bool {
+	if k.notBefore.After(now) {
+		return false
+	}
+	// HMAC secrets and public-only keys have no successor schedule; they verify while configured
+	if k.verifyOnly {
+		return true
+	}
+	current, err := s.signingKey(now)
+	if err != nil || current == k {
+		return true
+	}
+	supersededAt := current.notBefore
+	for _, other := range s.keys {
+		if !other.verifyOnly && other.notBefore.After(k.notBefore) && !other.notBefore.After(now) && other.notBefore.Before(supersededAt) {
+			supersededAt = other.notBefore
+		}
+	}
+	return now.Before(supersededAt.Add(s.grace))
+}
+
+// the shared secret, for tokens issued before signing keys carried a kid
+func (s *JWTKeySet) legacyHMACKey() *jwtKey {
+	for _, k := range s.keys {
+		if k.method == jwt.SigningMethodHS256 {
+			return k
+		}
+	}
+	return nil
+}
+
+// asymmetric keys other services should trust: those verifying now plus scheduled ones, so caches warm up before rotation
+func (s *JWTKeySet) publicKeys(now time.Time) []*jwtKey {
+	var keys []*jwtKey
+	for _, k := range s.keys {
+		if k.method == jwt.SigningMethodHS256 {
+			continue
+		}
+		if k.notBefore.After(now) || s.verifies(k, now) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// a stable, non-secret identifier for an HMAC secret
+func hmacKeyID(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("kid"))
+	return "hs-" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func readPrivateKey(path string) (interface{}, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported private key format, use PKCS#8 or PKCS#1 PEM")
+}
+
+func readPublicKey(path string) (interface{}, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported public key format, use PKIX or PKCS#1 PEM")
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return block, nil
+}
+
+// SigningMethodEdDSA adds Ed25519 (RFC 8037) to jwt-go, which only ships RSA, ECDSA and HMAC
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+type signingMethodEdDSA struct{}
+
+func init() {
+	jwt.RegisterSigningMethod("EdDSA", func() jwt.SigningMethod { return SigningMethodEdDSA })
+}
+
+func (m *signingMethodEdDSA) Alg() string { return "EdDSA" }
+
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(private, []byte(signingString))), nil
+}
+
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(public, []byte(signingString), sig) {
+		return errors.New("EdDSA signature is invalid")
+	}
+	return nil
+}
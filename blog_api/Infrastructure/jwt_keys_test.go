@@ -0,0 +1,165 @@
+package infrastructure
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestJWTKeySetRotationGrace(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	k1 := &jwtKey{kid: "k1", method: SigningMethodEdDSA, notBefore: t0}
+	k2 := &jwtKey{kid: "k2", method: SigningMethodEdDSA, notBefore: t0.Add(10 * day)}
+	k3 := &jwtKey{kid: "k3", method: SigningMethodEdDSA, notBefore: t0.Add(20 * day)}
+	published := &jwtKey{kid: "ext", method: SigningMethodEdDSA, notBefore: t0, verifyOnly: true}
+	legacy := &jwtKey{kid: "hs-legacy", method: jwt.SigningMethodHS256, verifyOnly: true}
+	set := &JWTKeySet{keys: []*jwtKey{legacy, k1, published, k2, k3}, grace: 7 * day}
+
+	tests := []struct {
+		name string
+		key  *jwtKey
+		at   time.Duration
+		want bool
+	}{
+		{"active key", k1, 1 * day, true},
+		{"superseded key inside grace", k1, 16 * day, true},
+		{"superseded key at grace end", k1, 17 * day, false},
+		// k1 was superseded by k2, not by k3, so k3 taking over does not restart its grace
+		{"superseded key after the next rotation", k1, 21 * day, false},
+		{"scheduled key before its start", k2, 5 * day, false},
+		{"scheduled key once active", k2, 10 * day, true},
+		{"second rotation inside grace", k2, 26 * day, true},
+		{"second rotation after grace", k2, 27 * day, false},
+		{"newest key", k3, 365 * day, true},
+		{"public-only key", published, 365 * day, true},
+		{"legacy HMAC secret", legacy, 365 * day, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := set.verifies(tt.key, t0.Add(tt.at)); got != tt.want {
+				t.Fatalf("verifies(%s) at day %v = %v, want %v", tt.key.kid, tt.at/day, got, tt.want)
+			}
+			got := set.verificationKey(tt.key.kid, t0.Add(tt.at)) != nil
+			if got != tt.want {
+				t.Fatalf("verificationKey(%s) at day %v found = %v, want %v", tt.key.kid, tt.at/day, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJWTKeySetSigningKey(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	k1 := &jwtKey{kid: "k1", method: SigningMethodEdDSA, notBefore: t0}
+	k2 := &jwtKey{kid: "k2", method: SigningMethodEdDSA, notBefore: t0.Add(time.Hour)}
+	published := &jwtKey{kid: "ext", method: SigningMethodEdDSA, notBefore: t0.Add(2 * time.Hour), verifyOnly: true}
+	set := &JWTKeySet{keys: []*jwtKey{k1, k2, published}}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want *jwtKey
+	}{
+		{"before any key", t0.Add(-time.Second), nil},
+		{"first key", t0, k1},
+		{"rotated", t0.Add(time.Hour), k2},
+		{"public-only keys never sign", t0.Add(3 * time.Hour), k2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := set.signingKey(tt.at)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("expected no signing key, got %s", got.kid)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("expected %s, got %v, %v", tt.want.kid, got, err)
+			}
+		})
+	}
+}
+
+func TestNewJWTKeySetFromKeysFile(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeEd25519Key(t, filepath.Join(dir, "old.pem"))
+	writeEd25519Key(t, filepath.Join(dir, "new.pem"))
+	writeEd25519Key(t, filepath.Join(dir, "next.pem"))
+	keysFile := filepath.Join(dir, "keys.json")
+	raw, _ := json.Marshal(map[string]interface{}{"keys": []map[string]interface{}{
+		{"kid": "old", "private_key_file": "old.pem", "not_before": now.Add(-48 * time.Hour)},
+		{"kid": "new", "private_key_file": "new.pem", "not_before": now.Add(-time.Hour)},
+		{"kid": "next", "private_key_file": "next.pem", "not_before": now.Add(24 * time.Hour)},
+	}})
+	if err := os.WriteFile(keysFile, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := NewJWTKeySet(JWTConfig{KeysFile: keysFile, GracePeriod: 24 * time.Hour, Production: true})
+	if err != nil {
+		t.Fatalf("expected keys file to load, got %v", err)
+	}
+	if k, _ := set.signingKey(now); k.kid != "new" || k.method != SigningMethodEdDSA {
+		t.Fatalf("expected new to sign with EdDSA, got %s", k.kid)
+	}
+	if set.verificationKey("old", now) == nil {
+		t.Fatal("expected old to verify inside the grace period")
+	}
+	if set.verificationKey("old", now.Add(24*time.Hour)) != nil {
+		t.Fatal("expected old to stop verifying after the grace period")
+	}
+	var published []string
+	for _, k := range set.publicKeys(now) {
+		published = append(published, k.kid)
+	}
+	if len(published) != 3 {
+		t.Fatalf("expected old, new and the scheduled next key to be published, got %v", published)
+	}
+}
+
+func TestNewJWTKeySetRejectsWeakProductionSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  JWTConfig
+		wantErr bool
+	}{
+		{"no key in production", JWTConfig{Production: true}, true},
+		{"example secret in production", JWTConfig{Secret: "your-secret-key", Production: true}, true},
+		{"short secret in production", JWTConfig{Secret: "short", Production: true}, true},
+		{"long random secret in production", JWTConfig{Secret: "0123456789abcdef0123456789abcdef", Production: true}, false},
+		{"development default", JWTConfig{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJWTKeySet(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func writeEd25519Key(t *testing.T, path string) {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,56 +2,103 @@ package infrastructure
 
 import (
 	services "blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"os"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 )
 
 type JWTServiceImpl struct {
-	secretKey []byte
+	keys *JWTKeySet
 }
 
-func NewJWTService() services.IJWTService {
-	secretKey := os.Getenv("JWT_SECRET_KEY")
-	if secretKey == "" {
-		secretKey = "your-secret-key"
+// fails when no key can sign, so a misconfigured deployment never starts
+func NewJWTService(config JWTConfig) (services.IJWTService, error) {
+	keys, err := NewJWTKeySet(config)
+	if err != nil {
+		return nil, err
 	}
-	
-	return &JWTServiceImpl{
-		secretKey: []byte(secretKey),
+	return &JWTServiceImpl{keys: keys}, nil
+}
+
+// signs with the currently active key and names it in the kid header
+func (j *JWTServiceImpl) sign(claims jwt.MapClaims) (string, error) {
+	key, err := j.keys.signingKey(time.Now())
+	if err != nil {
+		return "", err
 	}
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.signKey)
 }
 
-// generates a new JWT token
-func (j *JWTServiceImpl) GenerateJWT(userID, role string) (string, error) {
+// picks the verification key from the kid header, refusing retired keys and algorithm mismatches
+func (j *JWTServiceImpl) parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		var key *jwtKey
+		if kid == "" {
+			// tokens signed before keys had ids can only come from the shared secret
+			key = j.keys.legacyHMACKey()
+		} else {
+			key = j.keys.verificationKey(kid, time.Now())
+		}
+		if key == nil {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.verifyKey, nil
+	})
+}
+
+func (j *JWTServiceImpl) PublicKeys() []models.PublicSigningKey {
+	keys := j.keys.publicKeys(time.Now())
+	public := make([]models.PublicSigningKey, 0, len(keys))
+	for _, k := range keys {
+		public = append(public, models.PublicSigningKey{
+			KeyID:     k.kid,
+			Algorithm: k.method.Alg(),
+			Key:       k.verifyKey,
+			NotBefore: k.notBefore,
+		})
+	}
+	return public
+}
+
+// generates a new JWT token; sid ties it to the session it was issued for and jti lets it be revoked
+func (j *JWTServiceImpl) GenerateJWT(userID, role, sessionID string) (string, string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
 	claims := jwt.MapClaims{
 		"user_id": userID,
 		"role":    role,
 		"type":    "access",
+		"jti":     jti,
 		"exp":     time.Now().Add(15 * time.Minute).Unix(), // 15 minutes expiration
 		"iat":     time.Now().Unix(),
 	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString(j.secretKey)
+	tokenString, err := j.sign(claims)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	return tokenString, nil
+	return tokenString, jti, nil
 }
 
 //  validates a JWT token
 func (j *JWTServiceImpl) ValidateJWT(tokenString string) (map[string]interface{}, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return j.secretKey, nil
-	})
+	token, err := j.parse(tokenString)
 
 	if err != nil {
 		return nil, err
@@ -81,17 +128,21 @@ func (j *JWTServiceImpl) ValidateJWT(tokenString string) (map[string]interface{}
 
 // generates a new refresh token
 func (j *JWTServiceImpl) GenerateRefreshToken(userID string, role string) (string, error) {
+	// a random ID keeps two refresh tokens minted in the same second distinct
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
 	claims := jwt.MapClaims{
 		"user_id": userID,
         "role":    role,
 		"type":    "refresh",
+		"jti":     jti,
 		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 days expiration
 		"iat":     time.Now().Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString(j.secretKey)
+	tokenString, err := j.sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -100,12 +151,7 @@ func (j *JWTServiceImpl) GenerateRefreshToken(userID string, role string) (strin
 }
 // validates a refresh token
 func (j *JWTServiceImpl) ValidateRefreshToken(tokenString string) (map[string]interface{}, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return j.secretKey, nil
-	})
+	token, err := j.parse(tokenString)
 
 	if err != nil {
 		return nil, err
@@ -132,4 +178,12 @@ func (j *JWTServiceImpl) ValidateRefreshToken(tokenString string) (map[string]in
 	}
 
 	return nil, errors.New("invalid token claims")
-} 
\ No newline at end of file
+}
+
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,289 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mail drivers
+const (
+	EmailDriverSMTP   = "smtp"
+	EmailDriverFile   = "file"
+	EmailDriverMemory = "memory"
+)
+
+// email settings, read once at startup
+type EmailConfig struct {
+	Driver       string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	FileDir      string
+	ResetURL     string
+	VerifyURL    string
+	MagicLinkURL string
+	// pages that post the token from email change approval, confirmation and revert links
+	EmailApproveURL string
+	EmailChangeURL  string
+	EmailRevertURL  string
+	// links in digests point at BlogURL/<blog id>
+	BlogURL        string
+	UnsubscribeURL string
+}
+
+// builds the email config from environment variables
+func LoadEmailConfig() (EmailConfig, error) {
+	config := EmailConfig{
+		Driver:          os.Getenv("EMAIL_DRIVER"),
+		SMTPHost:        os.Getenv("SMTP_HOST"),
+		SMTPUsername:    os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:    os.Getenv("SMTP_PASSWORD"),
+		From:            os.Getenv("EMAIL_FROM"),
+		FileDir:         os.Getenv("EMAIL_FILE_DIR"),
+		ResetURL:        os.Getenv("RESET_URL"),
+		VerifyURL:       os.Getenv("VERIFY_EMAIL_URL"),
+		MagicLinkURL:    os.Getenv("MAGIC_LINK_URL"),
+		EmailApproveURL: os.Getenv("EMAIL_CHANGE_APPROVE_URL"),
+		EmailChangeURL:  os.Getenv("EMAIL_CHANGE_URL"),
+		EmailRevertURL:  os.Getenv("EMAIL_REVERT_URL"),
+		BlogURL:         os.Getenv("BLOG_POST_URL"),
+		UnsubscribeURL:  os.Getenv("DIGEST_UNSUBSCRIBE_URL"),
+	}
+
+	if v := os.Getenv("SMTP_PORT"); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return config, fmt.Errorf("invalid SMTP port: %v", err)
+		}
+		config.SMTPPort = port
+	}
+	if config.Driver == "" {
+		// keep working without a mail server: emails land on disk instead of stdout
+		config.Driver = EmailDriverFile
+		if config.SMTPHost != "" {
+			config.Driver = EmailDriverSMTP
+		}
+	}
+	if config.SMTPPort == 0 {
+		config.SMTPPort = 587
+	}
+	if config.From == "" {
+		config.From = config.SMTPUsername
+	}
+	if config.From == "" {
+		config.From = "no-reply@localhost"
+	}
+	if config.FileDir == "" {
+		config.FileDir = "../mail"
+	}
+	if config.ResetURL == "" {
+		config.ResetURL = "http://localhost:3000/reset-password"
+	}
+	if config.VerifyURL == "" {
+		config.VerifyURL = "http://localhost:3000/verify-email"
+	}
+	if config.MagicLinkURL == "" {
+		config.MagicLinkURL = "http://localhost:3000/magic-link"
+	}
+	if config.EmailApproveURL == "" {
+		config.EmailApproveURL = "http://localhost:3000/approve-email-change"
+	}
+	if config.EmailChangeURL == "" {
+		config.EmailChangeURL = "http://localhost:3000/confirm-email-change"
+	}
+	if config.EmailRevertURL == "" {
+		config.EmailRevertURL = "http://localhost:3000/revert-email-change"
+	}
+	if config.BlogURL == "" {
+		config.BlogURL = "http://localhost:3000/blogs"
+	}
+	if config.UnsubscribeURL == "" {
+		config.UnsubscribeURL = "http://localhost:8080/api/digest/unsubscribe"
+	}
+	return config, nil
+}
+
+// returns the mailer for the configured driver
+func NewMailer(config EmailConfig) (services.IMailer, error) {
+	switch config.Driver {
+	case EmailDriverSMTP:
+		if config.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for the smtp email driver")
+		}
+		return NewSMTPMailer(config), nil
+	case EmailDriverFile:
+		return NewFileMailer(config.FileDir, config.From)
+	case EmailDriverMemory:
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown email driver %q", config.Driver)
+	}
+}
+
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(config EmailConfig) *SMTPMailer {
+	var auth smtp.Auth
+	if config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
+	}
+	return &SMTPMailer{
+		addr: fmt.Sprintf("%s:%d", config.SMTPHost, config.SMTPPort),
+		auth: auth,
+		from: config.From,
+	}
+}
+
+// sends the message through the SMTP server
+func (m *SMTPMailer) Send(message *models.EmailMessage) error {
+	raw, err := buildMIMEMessage(m.from, message)
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{message.To}, raw); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	return nil
+}
+
+// writes each email as an .eml file, handy for local development
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create email directory: %v", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// writes the message to the mail directory
+func (m *FileMailer) Send(message *models.EmailMessage) error {
+	raw, err := buildMIMEMessage(m.from, message)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), sanitizeFileName(message.To))
+	return os.WriteFile(filepath.Join(m.dir, name), raw, 0644)
+}
+
+// keeps sent emails in memory so tests can assert on them
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []models.EmailMessage
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// records the message
+func (m *MemoryMailer) Send(message *models.EmailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, *message)
+	return nil
+}
+
+// returns a copy of every message sent so far
+func (m *MemoryMailer) Messages() []models.EmailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.EmailMessage(nil), m.messages...)
+}
+
+// returns the messages sent to an address
+func (m *MemoryMailer) MessagesTo(email string) []models.EmailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var matched []models.EmailMessage
+	for _, msg := range m.messages {
+		if strings.EqualFold(msg.To, email) {
+			matched = append(matched, msg)
+		}
+	}
+	return matched
+}
+
+// drops all recorded messages
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
+
+// builds a multipart/alternative message with plain text and HTML parts
+func buildMIMEMessage(from string, message *models.EmailMessage) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=\"UTF-8\"", message.TextBody},
+		{"text/html; charset=\"UTF-8\"", message.HTMLBody},
+	}
+	for _, p := range parts {
+		if p.content == "" {
+			continue
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", p.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var raw bytes.Buffer
+	fmt.Fprintf(&raw, "From: %s\r\n", from)
+	fmt.Fprintf(&raw, "To: %s\r\n", message.To)
+	fmt.Fprintf(&raw, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&raw, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	raw.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&raw, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", writer.Boundary())
+	raw.Write(body.Bytes())
+	return raw.Bytes(), nil
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
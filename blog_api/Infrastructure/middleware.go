@@ -2,12 +2,14 @@ package infrastructure
 
 import (
 	"net/http"
+	"time"
 
 	"blog_api/Domain/contracts/services"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtService services.IJWTService) gin.HandlerFunc {
+// checks the access token cookie; revoked tokens are rejected from the in-memory denylist
+func AuthMiddleware(jwtService services.IJWTService, denylist services.ITokenDenylist) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token, err := c.Cookie("access_token")
 		if err != nil || token == "" {
@@ -21,6 +23,11 @@ func AuthMiddleware(jwtService services.IJWTService) gin.HandlerFunc {
 			return
 		}
 
+		if jti, _ := claims["jti"].(string); denylist.IsRevoked(jti) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			return
+		}
+
 		userID, ok := claims["user_id"].(string)
 		if !ok || userID == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
@@ -35,6 +42,12 @@ func AuthMiddleware(jwtService services.IJWTService) gin.HandlerFunc {
 
 		c.Set("user_id", userID)
 		c.Set("role", role)
+		if sessionID, ok := claims["sid"].(string); ok {
+			c.Set("session_id", sessionID)
+		}
+		if exp, ok := claims["exp"].(float64); ok {
+			c.Set("token_expires_at", time.Unix(int64(exp), 0))
+		}
 		c.Next()
 	}
 }
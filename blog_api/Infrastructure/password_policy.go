@@ -0,0 +1,151 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reads the PASSWORD_* settings on top of the default policy
+func LoadPasswordPolicy() (models.PasswordPolicy, error) {
+	policy := models.DefaultPasswordPolicy()
+
+	for name, target := range map[string]*int{
+		"PASSWORD_MIN_LENGTH": &policy.MinLength,
+		"PASSWORD_HISTORY":    &policy.HistorySize,
+	} {
+		if v := os.Getenv(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return policy, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*target = n
+		}
+	}
+	for name, target := range map[string]*bool{
+		"PASSWORD_REQUIRE_UPPER":          &policy.RequireUpper,
+		"PASSWORD_REQUIRE_LOWER":          &policy.RequireLower,
+		"PASSWORD_REQUIRE_DIGIT":          &policy.RequireDigit,
+		"PASSWORD_REQUIRE_SYMBOL":         &policy.RequireSymbol,
+		"PASSWORD_DISALLOW_PERSONAL_INFO": &policy.DisallowPersonalInfo,
+	} {
+		if v := os.Getenv(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return policy, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*target = b
+		}
+	}
+	if v := os.Getenv("PASSWORD_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return policy, fmt.Errorf("invalid PASSWORD_MAX_AGE %q", v)
+		}
+		policy.MaxAge = d
+	}
+
+	if policy.MinLength < 1 {
+		policy.MinLength = 1
+	}
+	if policy.MinLength > policy.MaxLength {
+		return policy, fmt.Errorf("PASSWORD_MIN_LENGTH cannot exceed %d", policy.MaxLength)
+	}
+	return policy, nil
+}
+
+// checks passwords against a local copy of a breached password corpus such as Pwned Passwords.
+// Hashes are upper-case hex SHA-1, as the k-anonymity range API serves them.
+type BreachedPasswordList struct {
+	// a directory of range files named after the first five hash digits (21BD1.txt), lines "SUFFIX:COUNT"
+	dir string
+	// or the whole list from a single file of "HASH" or "HASH:COUNT" lines
+	hashes map[string]struct{}
+}
+
+// path is either a directory of range files, read one prefix at a time, or a single file loaded into memory
+func NewBreachedPasswordList(path string) (services.IBreachedPasswordChecker, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &BreachedPasswordList{dir: path}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &BreachedPasswordList{hashes: make(map[string]struct{})}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		hash, count, ok := parseBreachedLine(scanner.Text())
+		if !ok || count == 0 {
+			continue
+		}
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("%s:%d: expected a 40 digit SHA-1 hash", path, line)
+		}
+		list.hashes[hash] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (l *BreachedPasswordList) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if l.hashes != nil {
+		_, found := l.hashes[hash]
+		return found, nil
+	}
+
+	prefix, suffix := hash[:5], hash[5:]
+	file, err := os.Open(filepath.Join(l.dir, prefix+".txt"))
+	if os.IsNotExist(err) {
+		// no range file means no breached password starts with this prefix
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if candidate, count, ok := parseBreachedLine(scanner.Text()); ok && candidate == suffix && count != 0 {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// splits "HASH:COUNT" into its parts; blank lines and # comments are skipped and a missing count reads as -1
+func parseBreachedLine(line string) (string, int, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", 0, false
+	}
+	hash, countText, hasCount := strings.Cut(line, ":")
+	count := -1
+	if hasCount {
+		// padding entries in range responses carry a count of 0
+		if n, err := strconv.Atoi(strings.TrimSpace(countText)); err == nil {
+			count = n
+		}
+	}
+	return strings.ToUpper(strings.TrimSpace(hash)), count, true
+}
@@ -0,0 +1,24 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runs fn every interval until ctx is cancelled, logging (not stopping on) errors
+func RunPeriodically(ctx context.Context, interval time.Duration, name string, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := fn(ctx); err != nil {
+			log.Printf("%s: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"errors"
+	"sync"
+	"time"
+)
+
+type cachedPermissions struct {
+	permissions map[string]bool
+	expiresAt   time.Time
+}
+
+// resolves role permissions from the role repository and caches them per role name
+type PermissionService struct {
+	roleRepo repositories.IRoleRepository
+	ttl      time.Duration
+	mu       sync.RWMutex
+	cache    map[string]cachedPermissions
+}
+
+func NewPermissionService(roleRepo repositories.IRoleRepository, ttl time.Duration) services.IPermissionService {
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return &PermissionService{
+		roleRepo: roleRepo,
+		ttl:      ttl,
+		cache:    make(map[string]cachedPermissions),
+	}
+}
+
+// checks whether a role grants a permission
+func (s *PermissionService) HasPermission(roleName, permission string) (bool, error) {
+	s.mu.RLock()
+	entry, ok := s.cache[roleName]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.permissions[permission], nil
+	}
+
+	granted := make(map[string]bool)
+	role, err := s.roleRepo.GetRoleByName(roleName)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return false, err
+	}
+	if role != nil {
+		for _, p := range role.Permissions {
+			granted[p] = true
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[roleName] = cachedPermissions{permissions: granted, expiresAt: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+
+	return granted[permission], nil
+}
+
+// drops cached permissions after roles change
+func (s *PermissionService) Invalidate() {
+	s.mu.Lock()
+	s.cache = make(map[string]cachedPermissions)
+	s.mu.Unlock()
+}
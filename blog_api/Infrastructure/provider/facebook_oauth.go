@@ -37,12 +37,12 @@ func NewFacebookOAuthService() services.IOAuthService {
 }
 
 // generates the Facebook OAuth authorization URL
-func (f *FacebookOAuthService) GetAuthURL(state string) (string, error) {
+func (f *FacebookOAuthService) GetAuthURL(state, codeChallenge string) (string, error) {
   return f.config.AuthCodeURL(state, oauth2.AccessTypeOffline), nil
 }
 
 // exchanges authorization code for access token
-func (f *FacebookOAuthService) ExchangeCodeForToken(code string) (*models.OAuthToken, error) {
+func (f *FacebookOAuthService) ExchangeCodeForToken(code, codeVerifier string) (*models.OAuthToken, error) {
   ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
   defer cancel()
 
@@ -65,11 +65,11 @@ func (f *FacebookOAuthService) ExchangeCodeForToken(code string) (*models.OAuthT
 }
 
 // fetches user information from Facebook Graph API
-func (f *FacebookOAuthService) GetUserInfo(accessToken string) (*models.OAuthUserInfo, error) {
+func (f *FacebookOAuthService) GetUserInfo(token *models.OAuthToken) (*models.OAuthUserInfo, error) {
   ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
   defer cancel()
 
-  src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
+  src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token.AccessToken})
   httpClient := oauth2.NewClient(ctx, src)
 
   req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://graph.facebook.com/v18.0/me?fields=id,name,email,picture.type(large)", nil)
@@ -100,6 +100,7 @@ func (f *FacebookOAuthService) GetUserInfo(accessToken string) (*models.OAuthUse
     return nil, fmt.Errorf("failed to decode user info: %v", err)
   }
 
+  // the Graph API does not say whether the email was confirmed, so it is never treated as verified
   return &models.OAuthUserInfo{
     ProviderID: userInfo.ID,
     Email:      userInfo.Email,
@@ -112,3 +113,13 @@ func (f *FacebookOAuthService) GetUserInfo(accessToken string) (*models.OAuthUse
 func (f *FacebookOAuthService) GetProviderName() string {
   return "facebook"
 }
+
+// the Graph API login dialog is used without PKCE; the state check still applies
+func (f *FacebookOAuthService) SupportsPKCE() bool {
+  return false
+}
+
+// Facebook issues long-lived access tokens instead of refresh tokens
+func (f *FacebookOAuthService) RefreshToken(refresh string) (*models.OAuthToken, error) {
+  return nil, services.ErrRefreshNotSupported
+}
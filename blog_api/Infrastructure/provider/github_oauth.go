@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -40,34 +41,36 @@ func NewGitHubOAuthService() services.IOAuthService {
 }
 
 // generates the GitHub OAuth authorization URL
-func (g *GitHubOAuthService) GetAuthURL(state string) (string, error) {
+func (g *GitHubOAuthService) GetAuthURL(state, codeChallenge string) (string, error) {
+    if codeChallenge != "" {
+        return g.config.AuthCodeURL(state, oauth2.SetAuthURLParam("code_challenge", codeChallenge), oauth2.SetAuthURLParam("code_challenge_method", "S256")), nil
+    }
     return g.config.AuthCodeURL(state), nil
 }
 
 // exchanges authorization code for access token
-func (g *GitHubOAuthService) ExchangeCodeForToken(code string) (*models.OAuthToken, error) {
+func (g *GitHubOAuthService) ExchangeCodeForToken(code, codeVerifier string) (*models.OAuthToken, error) {
     ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
     defer cancel()
 
-    tok, err := g.config.Exchange(ctx, code)
+    var opts []oauth2.AuthCodeOption
+    if codeVerifier != "" {
+        opts = append(opts, oauth2.VerifierOption(codeVerifier))
+    }
+    tok, err := g.config.Exchange(ctx, code, opts...)
     if err != nil {
         return nil, fmt.Errorf("failed to exchange code for token: %v", err)
     }
-    // GitHub typically returns only access token; no refresh, no expiry
-    return &models.OAuthToken{
-        AccessToken:  tok.AccessToken,
-        RefreshToken: tok.RefreshToken,
-        ExpiresIn:    0,
-        TokenType:    tok.TokenType,
-    }, nil
+    // OAuth App tokens come without refresh token or expiry; GitHub Apps with expiring tokens send both
+    return toOAuthToken(tok), nil
 }
 
 // fetches user information from GitHub
-func (g *GitHubOAuthService) GetUserInfo(accessToken string) (*models.OAuthUserInfo, error) {
+func (g *GitHubOAuthService) GetUserInfo(token *models.OAuthToken) (*models.OAuthUserInfo, error) {
     ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
     defer cancel()
 
-    src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
+    src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token.AccessToken})
     httpClient := oauth2.NewClient(ctx, src)
 
     // Fetch profile
@@ -98,23 +101,25 @@ func (g *GitHubOAuthService) GetUserInfo(accessToken string) (*models.OAuthUserI
     }
 
 
-    // If email is missing, load from /user/emails
-    if userInfo.Email == "" {
-        req2, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/user/emails", nil)
-        if err == nil {
-            req2.Header.Set("Accept", "application/vnd.github.v3+json")
-            if resp2, err2 := httpClient.Do(req2); err2 == nil && resp2.StatusCode == http.StatusOK {
-                defer resp2.Body.Close()
-                var emails []struct{
-                    Email   string `json:"email"`
-                    Primary bool   `json:"primary"`
-                }
-                if err := json.NewDecoder(resp2.Body).Decode(&emails); err == nil {
-                    for _, e := range emails {
-                        if e.Primary {
-                            userInfo.Email = e.Email
-                            break
-                        }
+    // /user/emails says which address is primary and whether GitHub verified it;
+    // the profile email is used when it is on the list, the primary one otherwise
+    emailVerified := false
+    req2, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/user/emails", nil)
+    if err == nil {
+        req2.Header.Set("Accept", "application/vnd.github.v3+json")
+        if resp2, err2 := httpClient.Do(req2); err2 == nil {
+            defer resp2.Body.Close()
+            var emails []struct{
+                Email    string `json:"email"`
+                Primary  bool   `json:"primary"`
+                Verified bool   `json:"verified"`
+            }
+            if resp2.StatusCode == http.StatusOK && json.NewDecoder(resp2.Body).Decode(&emails) == nil {
+                for _, e := range emails {
+                    if (userInfo.Email == "" && e.Primary) || (userInfo.Email != "" && strings.EqualFold(e.Email, userInfo.Email)) {
+                        userInfo.Email = e.Email
+                        emailVerified = e.Verified
+                        break
                     }
                 }
             }
@@ -122,10 +127,11 @@ func (g *GitHubOAuthService) GetUserInfo(accessToken string) (*models.OAuthUserI
     }
 
     return &models.OAuthUserInfo{
-        ProviderID: fmt.Sprintf("%d", userInfo.ID),
-        Email:      userInfo.Email,
-        Name:       userInfo.Name,
-        Picture:    userInfo.AvatarURL,
+        ProviderID:    fmt.Sprintf("%d", userInfo.ID),
+        Email:         userInfo.Email,
+        EmailVerified: emailVerified,
+        Name:          userInfo.Name,
+        Picture:       userInfo.AvatarURL,
     }, nil
 }
 
@@ -133,3 +139,15 @@ func (g *GitHubOAuthService) GetUserInfo(accessToken string) (*models.OAuthUserI
 func (g *GitHubOAuthService) GetProviderName() string {
   return "github"
 }
+
+// GitHub OAuth apps accept S256 PKCE challenges
+func (g *GitHubOAuthService) SupportsPKCE() bool {
+  return true
+}
+
+// only GitHub Apps with expiring user tokens hand out refresh tokens; OAuth App tokens never expire
+func (g *GitHubOAuthService) RefreshToken(refresh string) (*models.OAuthToken, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    return refreshToken(ctx, g.config, refresh)
+}
@@ -38,18 +38,26 @@ func NewGoogleOAuthService() services.IOAuthService {
 }
 
 // GetAuthURL generates the Google OAuth authorization URL
-func (g *GoogleOAuthService) GetAuthURL(state string) (string, error) {
+func (g *GoogleOAuthService) GetAuthURL(state, codeChallenge string) (string, error) {
   // Request offline access to receive a refresh token; prompt consent to ensure refresh
-  url := g.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+  opts := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline}
+  if codeChallenge != "" {
+    opts = append(opts, oauth2.SetAuthURLParam("code_challenge", codeChallenge), oauth2.SetAuthURLParam("code_challenge_method", "S256"))
+  }
+  url := g.config.AuthCodeURL(state, opts...)
   return url, nil
 }
 
 // ExchangeCodeForToken exchanges authorization code for access token
-func (g *GoogleOAuthService) ExchangeCodeForToken(code string) (*models.OAuthToken, error) {
+func (g *GoogleOAuthService) ExchangeCodeForToken(code, codeVerifier string) (*models.OAuthToken, error) {
   ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
   defer cancel()
 
-  tok, err := g.config.Exchange(ctx, code)
+  var opts []oauth2.AuthCodeOption
+  if codeVerifier != "" {
+    opts = append(opts, oauth2.VerifierOption(codeVerifier))
+  }
+  tok, err := g.config.Exchange(ctx, code, opts...)
   if err != nil {
     return nil, fmt.Errorf("failed to exchange code for token: %v", err)
   }
@@ -71,12 +79,12 @@ func (g *GoogleOAuthService) ExchangeCodeForToken(code string) (*models.OAuthTok
 }
 
 // GetUserInfo fetches user information from Google
-func (g *GoogleOAuthService) GetUserInfo(accessToken string) (*models.OAuthUserInfo, error) {
+func (g *GoogleOAuthService) GetUserInfo(token *models.OAuthToken) (*models.OAuthUserInfo, error) {
   ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
   defer cancel()
 
   // Use oauth2 client with the provided access token
-  src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
+  src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token.AccessToken})
   httpClient := oauth2.NewClient(ctx, src)
 
   req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.googleapis.com/oauth2/v2/userinfo", nil)
@@ -94,20 +102,22 @@ func (g *GoogleOAuthService) GetUserInfo(accessToken string) (*models.OAuthUserI
   }
 
   var userInfo struct {
-    ID      string `json:"id"`
-    Email   string `json:"email"`
-    Name    string `json:"name"`
-    Picture string `json:"picture"`
+    ID            string `json:"id"`
+    Email         string `json:"email"`
+    VerifiedEmail bool   `json:"verified_email"`
+    Name          string `json:"name"`
+    Picture       string `json:"picture"`
   }
   if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
     return nil, fmt.Errorf("failed to decode user info: %v", err)
   }
 
   return &models.OAuthUserInfo{
-    ProviderID: userInfo.ID,
-    Email:      userInfo.Email,
-    Name:       userInfo.Name,
-    Picture:    userInfo.Picture,
+    ProviderID:    userInfo.ID,
+    Email:         userInfo.Email,
+    EmailVerified: userInfo.VerifiedEmail,
+    Name:          userInfo.Name,
+    Picture:       userInfo.Picture,
   }, nil
 }
 
@@ -115,3 +125,15 @@ func (g *GoogleOAuthService) GetUserInfo(accessToken string) (*models.OAuthUserI
 func (g *GoogleOAuthService) GetProviderName() string {
   return "google"
 }
+
+// Google accepts S256 PKCE challenges
+func (g *GoogleOAuthService) SupportsPKCE() bool {
+  return true
+}
+
+// RefreshToken renews the access token with the offline refresh token
+func (g *GoogleOAuthService) RefreshToken(refresh string) (*models.OAuthToken, error) {
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  defer cancel()
+  return refreshToken(ctx, g.config, refresh)
+}
@@ -0,0 +1,547 @@
+package provider
+
+import (
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
+	// registers the EdDSA signing method with jwt-go
+	_ "blog_api/Infrastructure"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/oauth2"
+)
+
+// how often an unknown kid may trigger a JWKS refetch
+const oidcKeyRefetchInterval = time.Minute
+
+// ID token algorithms we verify; "none" and HMAC are never accepted
+var oidcSupportedAlgs = map[string]bool{
+	"RS256": true, "RS384": true, "RS512": true,
+	"ES256": true, "ES384": true, "ES512": true,
+	"EdDSA": true,
+}
+
+var oidcProviderName = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,30}$`)
+
+// which claims hold the profile fields; nested claims use dots, e.g. "profile.picture"
+type OIDCClaimMapping struct {
+	ID            string
+	Email         string
+	EmailVerified string
+	Name          string
+	Picture       string
+}
+
+// one OpenID Connect identity provider, such as a company Keycloak, Okta or Azure AD tenant
+type OIDCProviderConfig struct {
+	// used in the login URL: /api/auth/{name}/login
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+	Claims       OIDCClaimMapping
+	// for the rare issuer that rejects PKCE parameters
+	DisablePKCE bool
+}
+
+// reads OIDC_PROVIDERS=name1,name2 and the OIDC_<NAME>_* settings of each
+func LoadOIDCProviderConfigs() ([]OIDCProviderConfig, error) {
+	var configs []OIDCProviderConfig
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !oidcProviderName.MatchString(name) {
+			return nil, fmt.Errorf("invalid OIDC provider name %q", name)
+		}
+		if name == models.ProviderGoogle || name == models.ProviderGitHub || name == models.ProviderFacebook {
+			return nil, fmt.Errorf("OIDC provider name %q is taken by a built-in provider", name)
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		env := func(key, fallback string) string {
+			if v := strings.TrimSpace(os.Getenv(prefix + key)); v != "" {
+				return v
+			}
+			return fallback
+		}
+		config := OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    strings.TrimSuffix(env("ISSUER", ""), "/"),
+			ClientID:     env("CLIENT_ID", ""),
+			ClientSecret: env("CLIENT_SECRET", ""),
+			RedirectURI:  env("REDIRECT_URI", ""),
+			Scopes:       strings.FieldsFunc(env("SCOPES", "openid email profile"), func(r rune) bool { return r == ' ' || r == ',' }),
+			Claims: OIDCClaimMapping{
+				ID:            env("CLAIM_ID", "sub"),
+				Email:         env("CLAIM_EMAIL", "email"),
+				EmailVerified: env("CLAIM_EMAIL_VERIFIED", "email_verified"),
+				Name:          env("CLAIM_NAME", "name"),
+				Picture:       env("CLAIM_PICTURE", "picture"),
+			},
+			DisablePKCE: env("DISABLE_PKCE", "false") == "true",
+		}
+		if config.IssuerURL == "" || config.ClientID == "" || config.RedirectURI == "" {
+			return nil, fmt.Errorf("OIDC provider %s needs %sISSUER, %sCLIENT_ID and %sREDIRECT_URI", name, prefix, prefix, prefix)
+		}
+		if err := checkIssuerURL(config.IssuerURL); err != nil {
+			return nil, fmt.Errorf("OIDC provider %s: %w", name, err)
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// issuers must use https, except on this machine so a local fake issuer works
+func checkIssuerURL(issuer string) error {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid issuer URL %q", issuer)
+	}
+	if u.Scheme == "https" {
+		return nil
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); u.Scheme == "http" && (host == "localhost" || (ip != nil && ip.IsLoopback())) {
+		return nil
+	}
+	return fmt.Errorf("issuer URL %q must use https", issuer)
+}
+
+type oidcDiscovery struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	UserinfoEndpoint                 string   `json:"userinfo_endpoint"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+type oidcKey struct {
+	alg string
+	key interface{}
+}
+
+// implements IOAuthService for any OpenID Connect provider, configured from its discovery document
+type OIDCOAuthService struct {
+	config     OIDCProviderConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]oidcKey
+	keysAt    time.Time
+}
+
+// discovery is deferred to the first login so an unreachable issuer does not stop the server starting
+func NewOIDCOAuthService(config OIDCProviderConfig) services.IOAuthService {
+	if !containsString(config.Scopes, "openid") {
+		config.Scopes = append([]string{"openid"}, config.Scopes...)
+	}
+	return &OIDCOAuthService{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// loads and caches the discovery document
+func (o *OIDCOAuthService) discover(ctx context.Context) (*oidcDiscovery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.discovery != nil {
+		return o.discovery, nil
+	}
+
+	var doc oidcDiscovery
+	if err := o.getJSON(ctx, o.config.IssuerURL+"/.well-known/openid-configuration", "", &doc); err != nil {
+		return nil, fmt.Errorf("failed to load OIDC discovery document: %v", err)
+	}
+	// a document that names another issuer could let that issuer's tokens through
+	if strings.TrimSuffix(doc.Issuer, "/") != o.config.IssuerURL {
+		return nil, fmt.Errorf("discovery document names issuer %q, expected %q", doc.Issuer, o.config.IssuerURL)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("discovery document is missing endpoints")
+	}
+	o.discovery = &doc
+	return o.discovery, nil
+}
+
+func (o *OIDCOAuthService) oauthConfig(doc *oidcDiscovery) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     o.config.ClientID,
+		ClientSecret: o.config.ClientSecret,
+		RedirectURL:  o.config.RedirectURI,
+		Scopes:       o.config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  doc.AuthorizationEndpoint,
+			TokenURL: doc.TokenEndpoint,
+		},
+	}
+}
+
+// generates the provider's authorization URL from its discovery document
+func (o *OIDCOAuthService) GetAuthURL(state, codeChallenge string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doc, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	var opts []oauth2.AuthCodeOption
+	if codeChallenge != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("code_challenge", codeChallenge), oauth2.SetAuthURLParam("code_challenge_method", "S256"))
+	}
+	return o.oauthConfig(doc).AuthCodeURL(state, opts...), nil
+}
+
+// exchanges the code and verifies the ID token that comes with it
+func (o *OIDCOAuthService) ExchangeCodeForToken(code, codeVerifier string) (*models.OAuthToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doc, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, o.httpClient)
+	var opts []oauth2.AuthCodeOption
+	if codeVerifier != "" {
+		opts = append(opts, oauth2.VerifierOption(codeVerifier))
+	}
+	tok, err := o.oauthConfig(doc).Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code for token: %v", err)
+	}
+
+	idToken, _ := tok.Extra("id_token").(string)
+	if idToken == "" {
+		return nil, errors.New("provider returned no ID token")
+	}
+	if _, err := o.verifyIDToken(ctx, idToken); err != nil {
+		return nil, err
+	}
+
+	out := toOAuthToken(tok)
+	out.IDToken = idToken
+	return out, nil
+}
+
+// refreshes against the discovered token endpoint; request the offline_access scope to be issued a refresh token
+func (o *OIDCOAuthService) RefreshToken(refresh string) (*models.OAuthToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doc, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, o.httpClient)
+	return refreshToken(ctx, o.oauthConfig(doc), refresh)
+}
+
+// reads the profile from the verified ID token, topped up from the userinfo endpoint when there is one
+func (o *OIDCOAuthService) GetUserInfo(token *models.OAuthToken) (*models.OAuthUserInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if token.IDToken == "" {
+		return nil, errors.New("provider returned no ID token")
+	}
+	claims, err := o.verifyIDToken(ctx, token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if doc.UserinfoEndpoint != "" && token.AccessToken != "" {
+		var userinfo map[string]interface{}
+		if err := o.getJSON(ctx, doc.UserinfoEndpoint, token.AccessToken, &userinfo); err != nil {
+			return nil, fmt.Errorf("failed to fetch user info: %v", err)
+		}
+		// OIDC Core 5.3.2: a userinfo response for another subject must be ignored
+		if sub, _ := userinfo["sub"].(string); sub != claims["sub"] {
+			return nil, errors.New("userinfo subject does not match the ID token")
+		}
+		for k, v := range userinfo {
+			if _, ok := claims[k]; !ok {
+				claims[k] = v
+			}
+		}
+	}
+
+	info := &models.OAuthUserInfo{
+		ProviderID: claimString(claims, o.config.Claims.ID),
+		Email:      claimString(claims, o.config.Claims.Email),
+		// some issuers (e.g. AWS Cognito) send the flag as a string
+		EmailVerified: claimString(claims, o.config.Claims.EmailVerified) == "true",
+		Name:          claimString(claims, o.config.Claims.Name),
+		Picture:       claimString(claims, o.config.Claims.Picture),
+	}
+	if info.ProviderID == "" {
+		return nil, fmt.Errorf("ID token has no %q claim", o.config.Claims.ID)
+	}
+	return info, nil
+}
+
+func (o *OIDCOAuthService) GetProviderName() string {
+	return o.config.Name
+}
+
+// sending a challenge to an issuer that ignores PKCE is harmless, so it is on unless disabled
+func (o *OIDCOAuthService) SupportsPKCE() bool {
+	return !o.config.DisablePKCE
+}
+
+// checks signature, issuer, audience and lifetime of an ID token and returns its claims
+func (o *OIDCOAuthService) verifyIDToken(ctx context.Context, raw string) (jwt.MapClaims, error) {
+	doc, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		alg := t.Method.Alg()
+		if !oidcSupportedAlgs[alg] {
+			return nil, fmt.Errorf("ID token algorithm %s is not accepted", alg)
+		}
+		if len(doc.IDTokenSigningAlgValuesSupported) > 0 && !containsString(doc.IDTokenSigningAlgValuesSupported, alg) {
+			return nil, fmt.Errorf("issuer does not sign ID tokens with %s", alg)
+		}
+		kid, _ := t.Header["kid"].(string)
+		return o.signingKey(ctx, doc, kid, alg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %v", err)
+	}
+
+	if iss, _ := claims["iss"].(string); iss != doc.Issuer {
+		return nil, errors.New("invalid ID token: wrong issuer")
+	}
+	var audiences []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		audiences = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+	}
+	if !containsString(audiences, o.config.ClientID) {
+		return nil, errors.New("invalid ID token: not issued for this client")
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != o.config.ClientID {
+		return nil, errors.New("invalid ID token: authorized party is another client")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("invalid ID token: no expiry")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("invalid ID token: no subject")
+	}
+	return claims, nil
+}
+
+// finds the issuer key for kid, refetching the JWKS at most once a minute when the kid is unknown (key rotation)
+func (o *OIDCOAuthService) signingKey(ctx context.Context, doc *oidcDiscovery, kid, alg string) (interface{}, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key, ok := o.lookupKey(kid)
+	if !ok && time.Since(o.keysAt) > oidcKeyRefetchInterval {
+		keys, err := o.fetchKeys(ctx, doc.JWKSURI)
+		if err != nil {
+			return nil, err
+		}
+		o.keys, o.keysAt = keys, time.Now()
+		key, ok = o.lookupKey(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if key.alg != "" && key.alg != alg {
+		return nil, fmt.Errorf("signing key %q is not for %s", kid, alg)
+	}
+	return key.key, nil
+}
+
+// with no kid the token can only be matched when the issuer publishes a single key
+func (o *OIDCOAuthService) lookupKey(kid string) (oidcKey, bool) {
+	if kid == "" {
+		if len(o.keys) == 1 {
+			for _, k := range o.keys {
+				return k, true
+			}
+		}
+		return oidcKey{}, false
+	}
+	key, ok := o.keys[kid]
+	return key, ok
+}
+
+func (o *OIDCOAuthService) fetchKeys(ctx context.Context, jwksURI string) (map[string]oidcKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := o.getJSON(ctx, jwksURI, "", &set); err != nil {
+		return nil, fmt.Errorf("failed to load issuer keys: %v", err)
+	}
+	keys := make(map[string]oidcKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		public, err := jwk.publicKey()
+		if err != nil {
+			// one odd key should not lock everyone out
+			continue
+		}
+		keys[jwk.Kid] = oidcKey{alg: jwk.Alg, key: public}
+	}
+	return keys, nil
+}
+
+func (o *OIDCOAuthService) getJSON(ctx context.Context, endpoint, bearer string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered with status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+// reads a string claim, following dots into nested objects
+func claimString(claims map[string]interface{}, path string) string {
+	if path == "" {
+		return ""
+	}
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = object[part]
+	}
+	switch v := current.(type) {
+	case string:
+		return v
+	case bool:
+		return fmt.Sprintf("%t", v)
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	default:
+		return ""
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"blog_api/Domain/models"
+	"blog_api/Infrastructure/provider/oidctest"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func newTestOIDC(t *testing.T) (*oidctest.Issuer, *OIDCOAuthService) {
+	t.Helper()
+	issuer, err := oidctest.NewIssuer("blog", "secret")
+	if err != nil {
+		t.Fatalf("failed to start issuer: %v", err)
+	}
+	t.Cleanup(issuer.Close)
+	svc := NewOIDCOAuthService(OIDCProviderConfig{
+		Name:         "test",
+		IssuerURL:    issuer.URL(),
+		ClientID:     issuer.ClientID,
+		ClientSecret: issuer.ClientSecret,
+		RedirectURI:  "http://localhost/callback",
+		Claims:       OIDCClaimMapping{ID: "sub", Email: "email", EmailVerified: "email_verified", Name: "name"},
+	})
+	return issuer, svc.(*OIDCOAuthService)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer, svc := newTestOIDC(t)
+	now := time.Now()
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss": issuer.URL(),
+			"aud": issuer.ClientID,
+			"sub": "42",
+			"iat": now.Unix(),
+			"exp": now.Add(time.Hour).Unix(),
+		}
+	}
+	with := func(k string, v interface{}) map[string]interface{} {
+		c := valid()
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		wantErr string
+	}{
+		{"valid", valid(), ""},
+		{"audience list containing the client", with("aud", []interface{}{"other", issuer.ClientID}), ""},
+		{"wrong issuer", with("iss", "https://evil.example.com"), "wrong issuer"},
+		{"wrong audience", with("aud", "another-client"), "not issued for this client"},
+		{"audience list without the client", with("aud", []interface{}{"other"}), "not issued for this client"},
+		{"authorized party is another client", with("azp", "another-client"), "authorized party"},
+		{"expired", with("exp", now.Add(-time.Minute).Unix()), "expired"},
+		{"no expiry", with("exp", nil), "no expiry"},
+		{"no subject", with("sub", nil), "no subject"},
+		{"empty subject", with("sub", ""), "no subject"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := issuer.Sign(tt.claims)
+			if err != nil {
+				t.Fatalf("failed to sign: %v", err)
+			}
+			claims, err := svc.verifyIDToken(context.Background(), raw)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected token to verify, got %v", err)
+				}
+				if claims["sub"] != "42" {
+					t.Fatalf("expected sub 42, got %v", claims["sub"])
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenAlgorithms(t *testing.T) {
+	issuer, svc := newTestOIDC(t)
+	claims := jwt.MapClaims{
+		"iss": issuer.URL(),
+		"aud": issuer.ClientID,
+		"sub": "42",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an HMAC token keyed with the client secret is the classic algorithm-confusion attack
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(issuer.ClientSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs256, err := issuer.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{"none", none, "not accepted"},
+		{"HS256", hmac, "not accepted"},
+		// an algorithm we support, but not one the issuer advertises
+		{"RS384 not advertised", withAlg(rs256, "RS384"), "does not sign ID tokens with RS384"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.verifyIDToken(context.Background(), tt.raw)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// swaps the header algorithm; the check must fail before the signature is looked at
+func withAlg(raw, alg string) string {
+	parts := strings.Split(raw, ".")
+	parts[0] = jwt.EncodeSegment([]byte(`{"alg":"` + alg + `","typ":"JWT"}`))
+	return strings.Join(parts, ".")
+}
+
+func TestGetUserInfo(t *testing.T) {
+	issuer, svc := newTestOIDC(t)
+	issuer.SetUser(map[string]interface{}{"sub": "42", "email": "ada@example.com", "email_verified": true, "name": "Ada"})
+	token := signIn(t, svc)
+
+	info, err := svc.GetUserInfo(token)
+	if err != nil {
+		t.Fatalf("expected user info, got %v", err)
+	}
+	if info.ProviderID != "42" || info.Email != "ada@example.com" || !info.EmailVerified || info.Name != "Ada" {
+		t.Fatalf("unexpected user info %+v", info)
+	}
+
+	// a valid ID token for someone else must not be topped up with this user's profile
+	other, err := issuer.Sign(map[string]interface{}{
+		"iss": issuer.URL(),
+		"aud": issuer.ClientID,
+		"sub": "43",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = svc.GetUserInfo(&models.OAuthToken{AccessToken: token.AccessToken, IDToken: other})
+	if err == nil || !strings.Contains(err.Error(), "subject does not match") {
+		t.Fatalf("expected subject mismatch, got %v", err)
+	}
+}
+
+// runs the authorization code flow against the issuer
+func signIn(t *testing.T, svc *OIDCOAuthService) *models.OAuthToken {
+	t.Helper()
+	authURL, err := svc.GetAuthURL("state", "")
+	if err != nil {
+		t.Fatalf("failed to build auth URL: %v", err)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize failed: %v", err)
+	}
+	resp.Body.Close()
+	back, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || back.Query().Get("code") == "" {
+		t.Fatalf("authorize did not redirect with a code: %q", resp.Header.Get("Location"))
+	}
+	token, err := svc.ExchangeCodeForToken(back.Query().Get("code"), "")
+	if err != nil {
+		t.Fatalf("code exchange failed: %v", err)
+	}
+	return token
+}
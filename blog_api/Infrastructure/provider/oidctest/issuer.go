@@ -0,0 +1,292 @@
+// Package oidctest runs an in-process OpenID Connect issuer so the OIDC provider can be exercised
+// in tests and local development without a real identity provider.
+//
+//	issuer, _ := oidctest.NewIssuer("blog", "secret")
+//	defer issuer.Close()
+//	issuer.SetUser(map[string]interface{}{"sub": "42", "email": "ada@example.com", "email_verified": true})
+//
+// Point OIDC_<NAME>_ISSUER at issuer.URL(). Its authorize endpoint signs the configured user in straight
+// away and redirects back with a code, so a test can follow the redirect and call the callback.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+type authRequest struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	claims        map[string]interface{}
+}
+
+type Issuer struct {
+	ClientID     string
+	ClientSecret string
+
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	keyID  string
+
+	mu            sync.Mutex
+	user          map[string]interface{}
+	codes         map[string]authRequest
+	tokens        map[string]map[string]interface{}
+	refreshTokens map[string]map[string]interface{}
+}
+
+// starts an issuer on a loopback port with a fresh RS256 key
+func NewIssuer(clientID, clientSecret string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	i := &Issuer{
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		key:           key,
+		keyID:         randomString(8),
+		user:          map[string]interface{}{"sub": "test-user", "email": "test@example.com", "email_verified": true, "name": "Test User"},
+		codes:         map[string]authRequest{},
+		tokens:        map[string]map[string]interface{}{},
+		refreshTokens: map[string]map[string]interface{}{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", i.discovery)
+	mux.HandleFunc("/authorize", i.authorize)
+	mux.HandleFunc("/token", i.token)
+	mux.HandleFunc("/userinfo", i.userinfo)
+	mux.HandleFunc("/jwks", i.jwks)
+	i.server = httptest.NewServer(mux)
+	return i, nil
+}
+
+func (i *Issuer) URL() string {
+	return i.server.URL
+}
+
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+// sets the claims of whoever signs in next; "sub" is required
+func (i *Issuer) SetUser(claims map[string]interface{}) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.user = claims
+}
+
+// invalidates every refresh token issued so far, as if the user revoked access at the provider
+func (i *Issuer) RevokeRefreshTokens() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.refreshTokens = map[string]map[string]interface{}{}
+}
+
+// signs arbitrary claims with the issuer key, for tests of tampered or foreign ID tokens
+func (i *Issuer) Sign(claims map[string]interface{}) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims(claims))
+	token.Header["kid"] = i.keyID
+	return token.SignedString(i.key)
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                i.URL(),
+		"authorization_endpoint":                i.URL() + "/authorize",
+		"token_endpoint":                        i.URL() + "/token",
+		"userinfo_endpoint":                     i.URL() + "/userinfo",
+		"jwks_uri":                              i.URL() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// skips the login page: the current user is signed in and sent back with a code
+func (i *Issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != i.ClientID || q.Get("response_type") != "code" {
+		http.Error(w, "unknown client or response type", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURI.Host == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge") != "" && q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "only S256 challenges are supported", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString(16)
+	i.mu.Lock()
+	i.codes[code] = authRequest{
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+		claims:        copyClaims(i.user),
+	}
+	i.mu.Unlock()
+
+	back := redirectURI.Query()
+	back.Set("code", code)
+	if state := q.Get("state"); state != "" {
+		back.Set("state", state)
+	}
+	redirectURI.RawQuery = back.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	grantType := r.PostForm.Get("grant_type")
+	if grantType != "authorization_code" && grantType != "refresh_token" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != i.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(i.ClientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if grantType == "refresh_token" {
+		i.refresh(w, r.PostForm.Get("refresh_token"))
+		return
+	}
+
+	i.mu.Lock()
+	req, found := i.codes[r.PostForm.Get("code")]
+	delete(i.codes, r.PostForm.Get("code")) // codes are single use
+	i.mu.Unlock()
+	if !found || req.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if req.codeChallenge != "" {
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != req.codeChallenge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+			return
+		}
+	}
+
+	now := time.Now()
+	claims := copyClaims(req.claims)
+	claims["iss"] = i.URL()
+	claims["aud"] = i.ClientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(time.Hour).Unix()
+	if req.nonce != "" {
+		claims["nonce"] = req.nonce
+	}
+	idToken, err := i.Sign(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	accessToken, refreshToken := randomString(24), randomString(24)
+	i.mu.Lock()
+	i.tokens[accessToken] = req.claims
+	i.refreshTokens[refreshToken] = req.claims
+	i.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    3600,
+		"id_token":      idToken,
+	})
+}
+
+// rotates the refresh token: the old one stops working once it has been used
+func (i *Issuer) refresh(w http.ResponseWriter, refreshToken string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	claims, ok := i.refreshTokens[refreshToken]
+	delete(i.refreshTokens, refreshToken)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	accessToken, next := randomString(24), randomString(24)
+	i.tokens[accessToken] = claims
+	i.refreshTokens[next] = claims
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"refresh_token": next,
+		"token_type":    "Bearer",
+		"expires_in":    3600,
+	})
+}
+
+func (i *Issuer) userinfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	i.mu.Lock()
+	claims, ok := i.tokens[accessToken]
+	i.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, claims)
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	public := i.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": i.keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func copyClaims(claims map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(claims))
+	for k, v := range claims {
+		out[k] = v
+	}
+	return out
+}
+
+func randomString(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// converts a provider token, turning its absolute expiry into seconds from now
+func toOAuthToken(tok *oauth2.Token) *models.OAuthToken {
+	out := &models.OAuthToken{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		TokenType:    tok.TokenType,
+	}
+	if !tok.Expiry.IsZero() {
+		out.ExpiresIn = int(time.Until(tok.Expiry).Seconds())
+		if out.ExpiresIn < 0 {
+			out.ExpiresIn = 0
+		}
+	}
+	return out
+}
+
+// runs the refresh_token grant against the provider's token endpoint
+func refreshToken(ctx context.Context, config *oauth2.Config, refresh string) (*models.OAuthToken, error) {
+	if refresh == "" {
+		return nil, services.ErrProviderGrantRevoked
+	}
+	// an already expired token forces the token source to go to the provider
+	tok, err := config.TokenSource(ctx, &oauth2.Token{RefreshToken: refresh, Expiry: time.Unix(1, 0)}).Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && (retrieveErr.ErrorCode == "invalid_grant" || retrieveErr.ErrorCode == "unauthorized_client") {
+			return nil, services.ErrProviderGrantRevoked
+		}
+		return nil, fmt.Errorf("failed to refresh token: %v", err)
+	}
+	out := toOAuthToken(tok)
+	// providers that do not rotate refresh tokens echo nothing back; the token source copies the old one in
+	if out.RefreshToken == refresh {
+		out.RefreshToken = ""
+	}
+	return out, nil
+}
@@ -0,0 +1,113 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/services"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Rate limit stores
+const (
+	RateLimitStoreMemory = "memory"
+	RateLimitStoreMongo  = "mongo"
+)
+
+// returns the store for the configured driver
+func NewRateLimitStore(driver string, db *mongo.Database) (services.IRateLimitStore, error) {
+	switch driver {
+	case "", RateLimitStoreMemory:
+		return NewMemoryRateLimitStore(), nil
+	case RateLimitStoreMongo:
+		return NewMongoRateLimitStore(db.Collection("rate_limits"))
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", driver)
+	}
+}
+
+// windowStart aligns windows to the epoch so every instance agrees on their boundaries
+func windowStart(now time.Time, window time.Duration) time.Time {
+	return now.Truncate(window)
+}
+
+type memoryCounter struct {
+	count   int64
+	resetAt time.Time
+}
+
+// keeps counters in this process; quotas are per instance when several run
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	counters  map[string]*memoryCounter
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{counters: make(map[string]*memoryCounter)}
+}
+
+func (s *MemoryRateLimitStore) Hit(ctx context.Context, key string, window time.Duration, now time.Time) (int64, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// expired counters are dropped about once a minute so idle clients do not pile up
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, counter := range s.counters {
+			if !now.Before(counter.resetAt) {
+				delete(s.counters, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	counter, ok := s.counters[key]
+	if !ok || !now.Before(counter.resetAt) {
+		counter = &memoryCounter{resetAt: windowStart(now, window).Add(window)}
+		s.counters[key] = counter
+	}
+	counter.count++
+	return counter.count, counter.resetAt, nil
+}
+
+// shares counters between instances through MongoDB; finished windows expire through a TTL index
+type MongoRateLimitStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoRateLimitStore(collection *mongo.Collection) (*MongoRateLimitStore, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate limit index: %v", err)
+	}
+	return &MongoRateLimitStore{collection: collection}, nil
+}
+
+func (s *MongoRateLimitStore) Hit(ctx context.Context, key string, window time.Duration, now time.Time) (int64, time.Time, error) {
+	start := windowStart(now, window)
+	resetAt := start.Add(window)
+
+	var doc struct {
+		Count int64 `bson:"count"`
+	}
+	err := s.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": key + "|" + strconv.FormatInt(start.Unix(), 10)},
+		bson.M{"$inc": bson.M{"count": 1}, "$setOnInsert": bson.M{"expires_at": resetAt}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return doc.Count, resetAt, nil
+}
@@ -1,22 +1,294 @@
 package infrastructure
 
 import (
+	"blog_api/Domain/contracts/services"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
-func AIRateLimitMiddleware() gin.HandlerFunc {
-	limiter := rate.NewLimiter(rate.Every(time.Minute), 5) 
+// How a policy tells callers apart
+const (
+	// the API key's client, else the signed-in user, else the IP address
+	RateLimitKeyUser = "user"
+	// always the IP address, for endpoints used before signing in
+	RateLimitKeyIP = "ip"
+)
+
+// the tier of callers that are neither signed in nor using an API key
+const RateLimitTierAnonymous = "anonymous"
+
+// a quota shared by every route it lists
+type RateLimitPolicy struct {
+	Name string `json:"name"`
+	// "METHOD /path" or "/path" using the router's patterns (/api/blogs/:id); a trailing /* matches everything below
+	Routes []string `json:"routes"`
+	Limit  int      `json:"limit"`
+	Window Duration `json:"window"`
+	Key    string   `json:"key"`
+	// per-tier limits replacing Limit; tiers are role names, API key tiers or "anonymous"
+	Tiers map[string]int `json:"tiers"`
+}
+
+// a client allowed to identify itself with the X-API-Key header
+type RateLimitAPIKey struct {
+	Name string `json:"name"`
+	// hex SHA-256 of the key, so the config file does not hold the key itself
+	KeySHA256 string `json:"key_sha256"`
+	Tier      string `json:"tier"`
+}
+
+type RateLimitConfig struct {
+	// applies to routes no other policy lists; nil leaves them unlimited
+	Default *RateLimitPolicy  `json:"default"`
+	Routes  []RateLimitPolicy `json:"routes"`
+	APIKeys []RateLimitAPIKey `json:"api_keys"`
+}
+
+// a time.Duration written as "1m" in JSON
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"1m\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// the limits used when RATE_LIMIT_CONFIG is not set
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Default: &RateLimitPolicy{
+			Name: "default", Limit: 300, Window: Duration(time.Minute), Key: RateLimitKeyUser,
+			Tiers: map[string]int{"admin": 3000},
+		},
+		Routes: []RateLimitPolicy{
+			{
+				Name:   "auth",
+				Routes: []string{"POST /api/users/login", "POST /api/users/login/2fa", "POST /api/users/register", "POST /api/auth/refresh"},
+				Limit:  30, Window: Duration(time.Minute), Key: RateLimitKeyIP,
+			},
+			{
+				Name:   "auth-email",
+				Routes: []string{"POST /api/users/forgot-password", "POST /api/users/login/magic-link", "POST /api/users/me/email"},
+				Limit:  5, Window: Duration(time.Minute), Key: RateLimitKeyIP,
+			},
+			{
+				Name:   "ai",
+				Routes: []string{"POST /api/ai/*", "POST /api/blogs/:id/generate-content"},
+				Limit:  5, Window: Duration(time.Minute), Key: RateLimitKeyUser,
+				Tiers: map[string]int{"admin": 50},
+			},
+		},
+	}
+}
 
+// reads the JSON file named by RATE_LIMIT_CONFIG, or returns the defaults
+func LoadRateLimitConfig() (RateLimitConfig, error) {
+	path := os.Getenv("RATE_LIMIT_CONFIG")
+	if path == "" {
+		return DefaultRateLimitConfig(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RateLimitConfig{}, err
+	}
+	var config RateLimitConfig
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return RateLimitConfig{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
+
+type routeMatcher struct {
+	method string
+	path   string
+	prefix bool
+	policy *RateLimitPolicy
+}
+
+// applies rate limit policies to every request
+type RateLimiter struct {
+	store      services.IRateLimitStore
+	jwtService services.IJWTService
+	denylist   services.ITokenDenylist
+	def        *RateLimitPolicy
+	matchers   []routeMatcher
+	apiKeys    map[string]RateLimitAPIKey
+}
+
+func NewRateLimiter(config RateLimitConfig, store services.IRateLimitStore, jwtService services.IJWTService, denylist services.ITokenDenylist) (*RateLimiter, error) {
+	rl := &RateLimiter{store: store, jwtService: jwtService, denylist: denylist, apiKeys: make(map[string]RateLimitAPIKey)}
+	names := make(map[string]bool)
+
+	check := func(p *RateLimitPolicy) error {
+		if p.Name == "" || names[p.Name] {
+			return fmt.Errorf("rate limit policies need unique names, got %q", p.Name)
+		}
+		names[p.Name] = true
+		if p.Limit <= 0 || p.Window <= 0 {
+			return fmt.Errorf("rate limit policy %s needs a positive limit and window", p.Name)
+		}
+		switch p.Key {
+		case "":
+			p.Key = RateLimitKeyUser
+		case RateLimitKeyUser, RateLimitKeyIP:
+		default:
+			return fmt.Errorf("rate limit policy %s: key must be user or ip", p.Name)
+		}
+		return nil
+	}
+
+	if config.Default != nil {
+		def := *config.Default
+		if err := check(&def); err != nil {
+			return nil, err
+		}
+		rl.def = &def
+	}
+	for i := range config.Routes {
+		policy := config.Routes[i]
+		if err := check(&policy); err != nil {
+			return nil, err
+		}
+		if len(policy.Routes) == 0 {
+			return nil, fmt.Errorf("rate limit policy %s lists no routes", policy.Name)
+		}
+		for _, route := range policy.Routes {
+			m := routeMatcher{path: route, policy: &policy}
+			if method, path, ok := strings.Cut(route, " "); ok {
+				m.method, m.path = strings.ToUpper(method), strings.TrimSpace(path)
+			}
+			if strings.HasSuffix(m.path, "/*") {
+				m.path, m.prefix = strings.TrimSuffix(m.path, "*"), true
+			}
+			rl.matchers = append(rl.matchers, m)
+		}
+	}
+	for _, key := range config.APIKeys {
+		hash := strings.ToLower(key.KeySHA256)
+		if key.Name == "" || len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("API key %q needs a name and a hex SHA-256 hash", key.Name)
+		}
+		rl.apiKeys[hash] = key
+	}
+	return rl, nil
+}
+
+// the first policy listing the route, else the default
+func (rl *RateLimiter) policyFor(method, route string) *RateLimitPolicy {
+	for _, m := range rl.matchers {
+		if m.method != "" && m.method != method {
+			continue
+		}
+		if route == m.path || (m.prefix && strings.HasPrefix(route, m.path)) {
+			return m.policy
+		}
+	}
+	return rl.def
+}
+
+// who is calling and which tier they are in
+// the IP is only as trustworthy as the router's trusted proxy list, see TRUSTED_PROXIES
+func (rl *RateLimiter) identify(c *gin.Context, policy *RateLimitPolicy) (string, string) {
+	ip := "ip:" + c.ClientIP()
+	if policy.Key == RateLimitKeyIP {
+		return ip, RateLimitTierAnonymous
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		// unknown keys are ignored so random keys cannot buy fresh quotas
+		if client, ok := rl.apiKeys[hex.EncodeToString(sum[:])]; ok {
+			return "key:" + client.Name, client.Tier
+		}
+	}
+	// this runs before AuthMiddleware, so the token is read here; a bad or revoked token just counts against the IP
+	if token, err := c.Cookie("access_token"); err == nil && token != "" && rl.jwtService != nil {
+		if claims, err := rl.jwtService.ValidateJWT(token); err == nil && !rl.revoked(claims) {
+			userID, _ := claims["user_id"].(string)
+			role, _ := claims["role"].(string)
+			if userID != "" {
+				return "user:" + userID, role
+			}
+		}
+	}
+	return ip, RateLimitTierAnonymous
+}
+
+// same check as AuthMiddleware so a logged-out token loses its role's quota
+func (rl *RateLimiter) revoked(claims map[string]interface{}) bool {
+	if rl.denylist == nil {
+		return false
+	}
+	jti, _ := claims["jti"].(string)
+	return rl.denylist.IsRevoked(jti)
+}
+
+// enforces the matching policy, answering with RateLimit-* headers and 429 plus Retry-After once it is used up
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Too many requests to AI service. Please try again later.",
+		policy := rl.policyFor(c.Request.Method, c.FullPath())
+		if policy == nil {
+			c.Next()
+			return
+		}
+		identity, tier := rl.identify(c, policy)
+		limit := policy.Limit
+		if tierLimit, ok := policy.Tiers[tier]; ok {
+			limit = tierLimit
+		}
+		window := time.Duration(policy.Window)
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		now := time.Now()
+		count, resetAt, err := rl.store.Hit(ctx, policy.Name+"|"+identity, window, now)
+		cancel()
+		if err != nil {
+			// an unreachable store must not take the API down with it
+			log.Printf("rate limit store error, allowing request: %v", err)
+			c.Next()
+			return
+		}
+
+		reset := int(math.Ceil(resetAt.Sub(now).Seconds()))
+		remaining := int64(limit) - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit, int(window.Seconds())))
+		c.Header("RateLimit-Limit", strconv.Itoa(limit))
+		c.Header("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+		if count > int64(limit) {
+			c.Header("Retry-After", strconv.Itoa(reset))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Too many requests, please try again later",
+				"retry_after": reset,
 			})
-			c.Abort()
 			return
 		}
 		c.Next()
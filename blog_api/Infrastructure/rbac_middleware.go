@@ -3,6 +3,7 @@ package infrastructure
 import (
 	"net/http"
 
+	"blog_api/Domain/contracts/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,3 +29,26 @@ func RBACMiddleware(allowedRoles ...string) gin.HandlerFunc {
 		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
 	}
 }
+
+//checks if the user's role grants every required permission
+func RequirePermission(permissionService services.IPermissionService, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if role == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Role not found in token"})
+			return
+		}
+		for _, permission := range permissions {
+			granted, err := permissionService.HasPermission(role, permission)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve permissions"})
+				return
+			}
+			if !granted {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+				return
+			}
+		}
+		c.Next()
+	}
+}
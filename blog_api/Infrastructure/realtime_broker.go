@@ -0,0 +1,161 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Realtime brokers
+const (
+	RealtimeBrokerMemory = "memory"
+	RealtimeBrokerMongo  = "mongo"
+)
+
+// returns the broker for the configured driver
+func NewEventBroker(driver string, db *mongo.Database) (services.IEventBroker, error) {
+	switch driver {
+	case "", RealtimeBrokerMemory:
+		return NewMemoryEventBroker(), nil
+	case RealtimeBrokerMongo:
+		return NewMongoEventBroker(db, "realtime_events", 16<<20)
+	default:
+		return nil, fmt.Errorf("unknown realtime broker %q", driver)
+	}
+}
+
+// delivers events straight back to this instance; fine for a single API process
+type MemoryEventBroker struct {
+	mu      sync.RWMutex
+	deliver func(models.RealtimeEvent)
+}
+
+func NewMemoryEventBroker() *MemoryEventBroker {
+	return &MemoryEventBroker{}
+}
+
+func (b *MemoryEventBroker) Publish(event *models.RealtimeEvent) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.deliver != nil {
+		b.deliver(*event)
+	}
+	return nil
+}
+
+func (b *MemoryEventBroker) Run(ctx context.Context, deliver func(models.RealtimeEvent)) error {
+	b.mu.Lock()
+	b.deliver = deliver
+	b.mu.Unlock()
+
+	<-ctx.Done()
+
+	b.mu.Lock()
+	b.deliver = nil
+	b.mu.Unlock()
+	return nil
+}
+
+// shares events between instances through a capped collection that every instance tails
+type MongoEventBroker struct {
+	collection *mongo.Collection
+}
+
+func NewMongoEventBroker(db *mongo.Database, name string, sizeBytes int64) (*MongoEventBroker, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := db.CreateCollection(ctx, name, options.CreateCollection().SetCapped(true).SetSizeInBytes(sizeBytes))
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Name == "NamespaceExists") {
+		return nil, fmt.Errorf("failed to create realtime collection: %v", err)
+	}
+	return &MongoEventBroker{collection: db.Collection(name)}, nil
+}
+
+type realtimeEventDocument struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Topic     string             `bson:"topic"`
+	Type      string             `bson:"type"`
+	Data      []byte             `bson:"data"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+func (b *MongoEventBroker) Publish(event *models.RealtimeEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id, err := primitive.ObjectIDFromHex(event.ID)
+	if err != nil {
+		id = primitive.NewObjectID()
+	}
+	_, err = b.collection.InsertOne(ctx, realtimeEventDocument{
+		ID:        id,
+		Topic:     event.Topic,
+		Type:      event.Type,
+		Data:      event.Data,
+		CreatedAt: event.CreatedAt,
+	})
+	return err
+}
+
+// tails the capped collection from now on, reopening the cursor whenever it dies
+func (b *MongoEventBroker) Run(ctx context.Context, deliver func(models.RealtimeEvent)) error {
+	// a tailable cursor on an empty capped collection dies at once, so make sure there is a document
+	lastID := primitive.NewObjectID()
+	if _, err := b.collection.InsertOne(ctx, bson.M{"_id": lastID, "type": "marker", "created_at": time.Now()}); err != nil {
+		return err
+	}
+
+	opts := options.Find().SetCursorType(options.TailableAwait).SetMaxAwaitTime(5 * time.Second)
+	for ctx.Err() == nil {
+		cursor, err := b.collection.Find(ctx, bson.M{"_id": bson.M{"$gt": lastID}}, opts)
+		if err != nil {
+			if !sleepCtx(ctx, time.Second) {
+				break
+			}
+			continue
+		}
+		for cursor.Next(ctx) {
+			var doc realtimeEventDocument
+			if err := cursor.Decode(&doc); err != nil {
+				continue
+			}
+			lastID = doc.ID
+			if doc.Topic == "" {
+				continue
+			}
+			deliver(models.RealtimeEvent{
+				ID:        doc.ID.Hex(),
+				Topic:     doc.Topic,
+				Type:      doc.Type,
+				Data:      doc.Data,
+				CreatedAt: doc.CreatedAt,
+			})
+		}
+		cursor.Close(context.Background())
+		if !sleepCtx(ctx, 100*time.Millisecond) {
+			break
+		}
+	}
+	return ctx.Err()
+}
+
+// waits for d, reporting false if ctx was cancelled first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -0,0 +1,113 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// events buffered per stream before a slow client starts missing them
+const subscriptionBuffer = 64
+
+// in-process pub/sub: publishes through the broker and delivers what the broker hands back to local streams
+type EventHub struct {
+	broker services.IEventBroker
+
+	mu     sync.RWMutex
+	topics map[string]map[*eventSubscription]struct{}
+}
+
+func NewEventHub(broker services.IEventBroker) *EventHub {
+	return &EventHub{
+		broker: broker,
+		topics: make(map[string]map[*eventSubscription]struct{}),
+	}
+}
+
+// starts receiving events from the broker until ctx is cancelled
+func (h *EventHub) Run(ctx context.Context) {
+	if err := h.broker.Run(ctx, h.dispatch); err != nil && ctx.Err() == nil {
+		log.Printf("realtime broker stopped: %v", err)
+	}
+}
+
+// publishes an event; failures are logged because realtime delivery is best effort
+func (h *EventHub) Publish(topic, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("realtime: failed to encode %s event: %v", eventType, err)
+		return
+	}
+	event := &models.RealtimeEvent{
+		ID:        primitive.NewObjectID().Hex(),
+		Topic:     topic,
+		Type:      eventType,
+		Data:      payload,
+		CreatedAt: time.Now(),
+	}
+	if err := h.broker.Publish(event); err != nil {
+		log.Printf("realtime: failed to publish %s event: %v", eventType, err)
+	}
+}
+
+func (h *EventHub) Subscribe(topics []string) services.IEventSubscription {
+	sub := &eventSubscription{
+		hub:    h,
+		topics: topics,
+		events: make(chan models.RealtimeEvent, subscriptionBuffer),
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*eventSubscription]struct{})
+		}
+		h.topics[topic][sub] = struct{}{}
+	}
+	return sub
+}
+
+// hands an event to every local subscriber of its topic without blocking on slow ones
+func (h *EventHub) dispatch(event models.RealtimeEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.topics[event.Topic] {
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+func (h *EventHub) unsubscribe(sub *eventSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range sub.topics {
+		delete(h.topics[topic], sub)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+}
+
+type eventSubscription struct {
+	hub    *EventHub
+	topics []string
+	events chan models.RealtimeEvent
+	once   sync.Once
+}
+
+func (s *eventSubscription) Events() <-chan models.RealtimeEvent {
+	return s.events
+}
+
+// stops delivery; the channel is left open so a concurrent dispatch never sends on a closed channel
+func (s *eventSubscription) Close() {
+	s.once.Do(func() { s.hub.unsubscribe(s) })
+}
@@ -0,0 +1,174 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/services"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// marks an envelope-encrypted value; anything without it is a legacy plaintext value
+const envelopePrefix = "v1:"
+
+var cipherKeyID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// a key-encryption key; it only ever wraps the per-value data keys
+type CipherKey struct {
+	ID  string
+	Key []byte
+}
+
+// EnvelopeCipher encrypts every value with a fresh AES-256-GCM data key and stores that data key
+// wrapped by a key-encryption key. Rotating the key-encryption key only re-wraps data keys.
+type EnvelopeCipher struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// reads OAUTH_TOKEN_KEYS ("id:base64key,..." with the active key first). Without it production refuses
+// to start; development falls back to a fixed key so stored tokens survive restarts.
+func LoadCipherKeys() ([]CipherKey, error) {
+	raw := strings.TrimSpace(os.Getenv("OAUTH_TOKEN_KEYS"))
+	if raw == "" {
+		if IsProduction() {
+			return nil, errors.New("OAUTH_TOKEN_KEYS must be set in production")
+		}
+		log.Printf("Warning: OAUTH_TOKEN_KEYS is not set, provider tokens are encrypted with a development key")
+		sum := sha256.Sum256([]byte("blog-api development token key"))
+		return []CipherKey{{ID: "dev", Key: sum[:]}}, nil
+	}
+
+	var keys []CipherKey
+	for _, entry := range strings.Split(raw, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || !cipherKeyID.MatchString(id) {
+			return nil, fmt.Errorf("OAUTH_TOKEN_KEYS entry %q must look like id:base64key", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("OAUTH_TOKEN_KEYS key %s must be 32 bytes, base64 encoded", id)
+		}
+		keys = append(keys, CipherKey{ID: id, Key: key})
+	}
+	return keys, nil
+}
+
+// the first key encrypts; the rest only decrypt
+func NewEnvelopeCipher(keys []CipherKey) (services.ISecretCipher, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one encryption key is required")
+	}
+	c := &EnvelopeCipher{active: keys[0].ID, keys: make(map[string]cipher.AEAD, len(keys))}
+	for _, k := range keys {
+		if _, dup := c.keys[k.ID]; dup {
+			return nil, fmt.Errorf("duplicate encryption key id %s", k.ID)
+		}
+		aead, err := newGCM(k.Key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s: %w", k.ID, err)
+		}
+		c.keys[k.ID] = aead
+	}
+	return c, nil
+}
+
+func (c *EnvelopeCipher) ActiveKeyID() string {
+	return c.active
+}
+
+// produces v1:<key id>:<wrapped data key>:<ciphertext>
+func (c *EnvelopeCipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	// the key id is authenticated so a wrapped key cannot be replayed under another id
+	wrapped, err := seal(c.keys[c.active], dataKey, []byte(c.active))
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(dataAEAD, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + c.active + ":" + base64.RawURLEncoding.EncodeToString(wrapped) + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (c *EnvelopeCipher) Decrypt(ciphertext string) (string, error) {
+	if !strings.HasPrefix(ciphertext, envelopePrefix) {
+		return ciphertext, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(ciphertext, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted value")
+	}
+	kek, ok := c.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("value is encrypted with unknown key %s", parts[0])
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.New("malformed encrypted value")
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.New("malformed encrypted value")
+	}
+	dataKey, err := open(kek, wrapped, []byte(parts[0]))
+	if err != nil {
+		return "", err
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataAEAD, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce || ciphertext
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted value")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, errors.New("encrypted value failed authentication")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,191 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testKey(id string, fill byte) CipherKey {
+	return CipherKey{ID: id, Key: bytes.Repeat([]byte{fill}, 32)}
+}
+
+func TestEnvelopeCipherRoundTrip(t *testing.T) {
+	c, err := NewEnvelopeCipher([]CipherKey{testKey("k1", 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plaintext := range []string{"", "ya29.access-token", "ünïcödé:with:colons", strings.Repeat("x", 4096)} {
+		sealed, err := c.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("encrypt %q: %v", plaintext, err)
+		}
+		if plaintext != "" && (!strings.HasPrefix(sealed, "v1:k1:") || strings.Contains(sealed, plaintext)) {
+			t.Fatalf("expected a v1 envelope under k1 without the plaintext, got %q", sealed)
+		}
+		got, err := c.Decrypt(sealed)
+		if err != nil || got != plaintext {
+			t.Fatalf("round trip of %q gave %q, %v", plaintext, got, err)
+		}
+	}
+
+	// fresh data key and nonces every time
+	a, _ := c.Encrypt("same")
+	b, _ := c.Encrypt("same")
+	if a == b {
+		t.Fatal("expected two encryptions of the same value to differ")
+	}
+}
+
+func TestEnvelopeCipherKeyRotation(t *testing.T) {
+	oldOnly, _ := NewEnvelopeCipher([]CipherKey{testKey("old", 1)})
+	rotated, _ := NewEnvelopeCipher([]CipherKey{testKey("new", 2), testKey("old", 1)})
+	newOnly, _ := NewEnvelopeCipher([]CipherKey{testKey("new", 2)})
+
+	underOld, err := oldOnly.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	underNew, err := rotated.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.ActiveKeyID() != "new" || !strings.HasPrefix(underNew, "v1:new:") {
+		t.Fatalf("expected the first key to encrypt, got %q", underNew)
+	}
+
+	tests := []struct {
+		name    string
+		cipher  interface{ Decrypt(string) (string, error) }
+		value   string
+		wantErr string
+	}{
+		{"retired key still decrypts during rotation", rotated, underOld, ""},
+		{"active key decrypts", rotated, underNew, ""},
+		{"retired key removed", newOnly, underOld, "unknown key old"},
+		{"new key unknown to an old deployment", oldOnly, underNew, "unknown key new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cipher.Decrypt(tt.value)
+			if tt.wantErr == "" {
+				if err != nil || got != "secret" {
+					t.Fatalf("expected secret, got %q, %v", got, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestEnvelopeCipherRejectsTampering(t *testing.T) {
+	c, _ := NewEnvelopeCipher([]CipherKey{testKey("a", 1), testKey("b", 1)})
+	sealed, err := c.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(sealed, ":")
+	flip := func(segment string) string {
+		raw, _ := base64.RawURLEncoding.DecodeString(segment)
+		raw[len(raw)-1] ^= 1
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"flipped ciphertext", strings.Join([]string{parts[0], parts[1], parts[2], flip(parts[3])}, ":"), "failed authentication"},
+		{"flipped wrapped key", strings.Join([]string{parts[0], parts[1], flip(parts[2]), parts[3]}, ":"), "failed authentication"},
+		// same key material under another id: the id is authenticated with the wrapped key
+		{"relabelled key id", strings.Join([]string{parts[0], "b", parts[2], parts[3]}, ":"), "failed authentication"},
+		{"missing segment", strings.Join(parts[:3], ":"), "malformed"},
+		{"bad base64", strings.Join([]string{parts[0], parts[1], "!!", parts[3]}, ":"), "malformed"},
+		{"truncated", strings.Join([]string{parts[0], parts[1], parts[2], "AA"}, ":"), "malformed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Decrypt(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestEnvelopeCipherLegacyPlaintext(t *testing.T) {
+	c, _ := NewEnvelopeCipher([]CipherKey{testKey("k1", 1)})
+	got, err := c.Decrypt("stored-before-encryption")
+	if err != nil || got != "stored-before-encryption" {
+		t.Fatalf("expected legacy value unchanged, got %q, %v", got, err)
+	}
+}
+
+func TestNewEnvelopeCipherValidatesKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []CipherKey
+	}{
+		{"no keys", nil},
+		{"duplicate id", []CipherKey{testKey("k1", 1), testKey("k1", 2)}},
+		{"short key", []CipherKey{{ID: "k1", Key: []byte("too short")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEnvelopeCipher(tt.keys); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadCipherKeys(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	tests := []struct {
+		name    string
+		env     string
+		prod    bool
+		wantIDs []string
+		wantErr bool
+	}{
+		{"development default", "", false, []string{"dev"}, false},
+		{"required in production", "", true, nil, true},
+		{"active key first", "new:" + key + ", old:" + key, true, []string{"new", "old"}, false},
+		{"missing id", key, false, nil, true},
+		{"bad id", "a b:" + key, false, nil, true},
+		{"short key", "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), false, nil, true},
+		{"not base64", "k1:***", false, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OAUTH_TOKEN_KEYS", tt.env)
+			if tt.prod {
+				t.Setenv("ENV", "production")
+			} else {
+				t.Setenv("ENV", "development")
+			}
+			keys, err := LoadCipherKeys()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, k := range keys {
+				ids = append(ids, k.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Fatalf("expected key ids %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package infrastructure
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// revocations made on another instance can carry a slightly different clock; syncs overlap by this much
+const denylistClockSkew = 5 * time.Second
+
+// TokenDenylist keeps the jti of every revoked, still-unexpired access token in memory.
+// Access tokens live 15 minutes, so the set stays small; it is rebuilt from the token repository on start.
+type TokenDenylist struct {
+	tokenRepo  repositories.ITokenRepository
+	maxEntries int
+
+	mu      sync.RWMutex
+	entries map[string]time.Time // jti -> token expiry
+
+	syncMu      sync.Mutex
+	syncedUntil time.Time
+}
+
+func NewTokenDenylist(tokenRepo repositories.ITokenRepository, maxEntries int) *TokenDenylist {
+	if maxEntries <= 0 {
+		maxEntries = 100000
+	}
+	return &TokenDenylist{
+		tokenRepo:  tokenRepo,
+		maxEntries: maxEntries,
+		entries:    make(map[string]time.Time),
+	}
+}
+
+func (d *TokenDenylist) IsRevoked(jti string) bool {
+	if jti == "" {
+		return false
+	}
+	d.mu.RLock()
+	expiresAt, ok := d.entries[jti]
+	d.mu.RUnlock()
+	return ok && time.Now().Before(expiresAt)
+}
+
+// loads revocations newer than the previous sync; the first call loads every unexpired one
+func (d *TokenDenylist) Sync() error {
+	d.syncMu.Lock()
+	defer d.syncMu.Unlock()
+
+	started := time.Now()
+	since := time.Time{}
+	if !d.syncedUntil.IsZero() {
+		since = d.syncedUntil.Add(-denylistClockSkew)
+	}
+	revoked, err := d.tokenRepo.ListRevokedAccessTokens(since)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	for _, t := range revoked {
+		d.entries[t.JTI] = t.ExpiresAt
+	}
+	d.mu.Unlock()
+	d.prune(started)
+	d.syncedUntil = started
+	return nil
+}
+
+// syncs every interval until ctx is cancelled
+func (d *TokenDenylist) Run(ctx context.Context, interval time.Duration) {
+	RunPeriodically(ctx, interval, "token denylist sync", func(ctx context.Context) error {
+		return d.Sync()
+	})
+}
+
+// drops expired entries; if the set is still over its cap the soonest-expiring entries go first
+func (d *TokenDenylist) prune(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for jti, expiresAt := range d.entries {
+		if !now.Before(expiresAt) {
+			delete(d.entries, jti)
+		}
+	}
+	if len(d.entries) <= d.maxEntries {
+		return
+	}
+
+	log.Printf("token denylist: %d revoked tokens exceed the cap of %d, dropping those closest to expiry", len(d.entries), d.maxEntries)
+	jtis := make([]string, 0, len(d.entries))
+	for jti := range d.entries {
+		jtis = append(jtis, jti)
+	}
+	sort.Slice(jtis, func(i, j int) bool { return d.entries[jtis[i]].Before(d.entries[jtis[j]]) })
+	for _, jti := range jtis[:len(jtis)-d.maxEntries] {
+		delete(d.entries, jti)
+	}
+}
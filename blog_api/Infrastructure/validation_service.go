@@ -2,7 +2,13 @@ package infrastructure
 
 import (
 	services "blog_api/Domain/contracts/services"
+	"blog_api/Domain/models"
+	"fmt"
+	"log"
 	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type ValidationError struct {
@@ -14,10 +20,24 @@ func (e *ValidationError) Error() string {
 	return e.Message
 }
 
-type ValidationServiceImpl struct{}
+var (
+	emailPattern    = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	// splits usernames and addresses into the words a password might borrow
+	fragmentSeparator = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// personal info shorter than this is too common to reject passwords over
+const minPersonalFragment = 3
 
-func NewValidationService() services.IValidationService {
-	return &ValidationServiceImpl{}
+type ValidationServiceImpl struct {
+	policy   models.PasswordPolicy
+	breached services.IBreachedPasswordChecker
+}
+
+// breached may be nil to skip the breached password check
+func NewValidationService(policy models.PasswordPolicy, breached services.IBreachedPasswordChecker) services.IValidationService {
+	return &ValidationServiceImpl{policy: policy, breached: breached}
 }
 
 // ValidateEmail validates email format
@@ -27,35 +47,88 @@ func (v *ValidationServiceImpl) ValidateEmail(email string) error {
 	}
 	
 	// Check email format using regex
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(email) {
+	if !emailPattern.MatchString(email) {
 		return &ValidationError{Field: "email", Message: "invalid email format"}
 	}
 	
 	return nil
 }
 
-// ValidatePassword validates password strength
-func (v *ValidationServiceImpl) ValidatePassword(password string) error {
+// ValidatePassword checks a new password against the password policy
+func (v *ValidationServiceImpl) ValidatePassword(password string, personalInfo ...string) error {
 	if password == "" {
 		return &ValidationError{Field: "password", Message: "password is required"}
 	}
-	if len(password) < 8 {
-		return &ValidationError{Field: "password", Message: "password must be at least 8 characters long"}
+	length := utf8.RuneCountInString(password)
+	if length < v.policy.MinLength {
+		return &ValidationError{Field: "password", Message: fmt.Sprintf("password must be at least %d characters long", v.policy.MinLength)}
+	}
+	// bcrypt ignores everything past 72 bytes, so the limit is in bytes
+	if v.policy.MaxLength > 0 && len(password) > v.policy.MaxLength {
+		return &ValidationError{Field: "password", Message: fmt.Sprintf("password must be at most %d bytes long", v.policy.MaxLength)}
 	}
-	if !regexp.MustCompile(`[A-Z]`).MatchString(password) {
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if v.policy.RequireUpper && !upper {
 		return &ValidationError{Field: "password", Message: "password must contain at least one uppercase letter"}
 	}
-	if !regexp.MustCompile(`[a-z]`).MatchString(password) {
+	if v.policy.RequireLower && !lower {
 		return &ValidationError{Field: "password", Message: "password must contain at least one lowercase letter"}
 	}
-	if !regexp.MustCompile(`[0-9]`).MatchString(password) {
+	if v.policy.RequireDigit && !digit {
 		return &ValidationError{Field: "password", Message: "password must contain at least one number"}
 	}
-	
+	if v.policy.RequireSymbol && !symbol {
+		return &ValidationError{Field: "password", Message: "password must contain at least one symbol"}
+	}
+
+	if v.policy.DisallowPersonalInfo && containsPersonalInfo(password, personalInfo) {
+		return &ValidationError{Field: "password", Message: "password must not contain your username, email or name"}
+	}
+
+	if v.breached != nil {
+		breached, err := v.breached.IsBreached(password)
+		if err != nil {
+			// a missing or unreadable list must not stop people from signing up
+			log.Printf("breached password check failed, skipping it: %v", err)
+		} else if breached {
+			return &ValidationError{Field: "password", Message: "this password has appeared in a data breach, choose a different one"}
+		}
+	}
+
 	return nil
 }
 
+// reports whether the password contains any word of the given values; only an email's local part counts
+func containsPersonalInfo(password string, values []string) bool {
+	lowered := strings.ToLower(password)
+	for _, value := range values {
+		if at := strings.LastIndex(value, "@"); at >= 0 {
+			value = value[:at]
+		}
+		value = strings.ToLower(value)
+		fragments := append(fragmentSeparator.Split(value, -1), fragmentSeparator.ReplaceAllString(value, ""))
+		for _, fragment := range fragments {
+			if utf8.RuneCountInString(fragment) >= minPersonalFragment && strings.Contains(lowered, fragment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ValidateUsername validates username format
 func (v *ValidationServiceImpl) ValidateUsername(username string) error {
 	if username == "" {
@@ -67,9 +140,9 @@ func (v *ValidationServiceImpl) ValidateUsername(username string) error {
 	if len(username) > 30 {
 		return &ValidationError{Field: "username", Message: "username must be less than 30 characters"}
 	}
-	if !regexp.MustCompile(`^[a-zA-Z0-9_]+$`).MatchString(username) {
+	if !usernamePattern.MatchString(username) {
 		return &ValidationError{Field: "username", Message: "username can only contain letters, numbers, and underscores"}
 	}
 	
 	return nil
-} 
\ No newline at end of file
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoAuditRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAuditRepository(collection *mongo.Collection) repositories.IAuditRepository {
+	return &MongoAuditRepository{
+		collection: collection,
+	}
+}
+
+// appends an entry to the audit log
+func (r *MongoAuditRepository) CreateEntry(entry *models.AuditEntry) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID := primitive.NewObjectID()
+	doc := bson.M{
+		"_id":         objectID,
+		"actor_id":    entry.ActorID,
+		"action":      entry.Action,
+		"target_type": entry.TargetType,
+		"target_id":   entry.TargetID,
+		"before":      entry.Before,
+		"after":       entry.After,
+		"details":     entry.Details,
+		"ip":          entry.IP,
+		"user_agent":  entry.UserAgent,
+		"created_at":  entry.CreatedAt,
+	}
+
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return err
+	}
+	entry.ID = objectID.Hex()
+	return nil
+}
+
+// retrieves audit entries matching the query, newest first
+func (r *MongoAuditRepository) ListEntries(query *models.AuditQuery) ([]models.AuditEntry, int, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{}
+	if query.ActorID != "" {
+		filter["actor_id"] = query.ActorID
+	}
+	if query.TargetID != "" {
+		filter["target_id"] = query.TargetID
+	}
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+	if query.From != nil || query.To != nil {
+		createdAt := bson.M{}
+		if query.From != nil {
+			createdAt["$gte"] = *query.From
+		}
+		if query.To != nil {
+			createdAt["$lte"] = *query.To
+		}
+		filter["created_at"] = createdAt
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((query.Page - 1) * query.PageSize)).
+		SetLimit(int64(query.PageSize))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var docs []struct {
+		ID         primitive.ObjectID     `bson:"_id"`
+		ActorID    string                 `bson:"actor_id"`
+		Action     string                 `bson:"action"`
+		TargetType string                 `bson:"target_type"`
+		TargetID   string                 `bson:"target_id"`
+		Before     map[string]interface{} `bson:"before"`
+		After      map[string]interface{} `bson:"after"`
+		Details    map[string]interface{} `bson:"details"`
+		IP         string                 `bson:"ip"`
+		UserAgent  string                 `bson:"user_agent"`
+		CreatedAt  primitive.DateTime     `bson:"created_at"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]models.AuditEntry, 0, len(docs))
+	for _, doc := range docs {
+		entries = append(entries, models.AuditEntry{
+			ID:         doc.ID.Hex(),
+			ActorID:    doc.ActorID,
+			Action:     doc.Action,
+			TargetType: doc.TargetType,
+			TargetID:   doc.TargetID,
+			Before:     doc.Before,
+			After:      doc.After,
+			Details:    doc.Details,
+			IP:         doc.IP,
+			UserAgent:  doc.UserAgent,
+			CreatedAt:  doc.CreatedAt.Time(),
+		})
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, int(total), nil
+}
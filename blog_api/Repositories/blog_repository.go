@@ -37,6 +37,34 @@ func BuildFilterID (blogId string) (bson.M, error ){
 }
 
 
+// only published blogs are visible in public listings; blogs created before
+// the review workflow have no status and count as published
+func publishedFilter() bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"status": bson.M{"$exists": false}},
+		bson.M{"status": ""},
+		bson.M{"status": models.BlogStatusPublished},
+	}}
+}
+
+// decodes blogs from a cursor keeping their hex IDs
+func decodeBlogs(ctx context.Context, cursor *mongo.Cursor) ([]models.Blog, error) {
+	var docs []struct {
+		ObjectID    primitive.ObjectID `bson:"_id"`
+		models.Blog `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	blogs := make([]models.Blog, 0, len(docs))
+	for _, doc := range docs {
+		blog := doc.Blog
+		blog.ID = doc.ObjectID.Hex()
+		blogs = append(blogs, blog)
+	}
+	return blogs, nil
+}
+
 func (m *MongoBlogRepository) CreateBlog(blog *models.Blog) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -56,6 +84,7 @@ func (m *MongoBlogRepository) CreateBlog(blog *models.Blog) error {
 		"commentcount": blog.CommentCount,
 		"sharecount":   blog.ShareCount,
 		"aisuggestion": blog.AISuggestion,
+		"status":       blog.Status,
 		"createdat":    blog.CreatedAt,
 		"updatedat":    blog.UpdatedAt,
 	}
@@ -90,7 +119,7 @@ func (m *MongoBlogRepository) GetBlogs(query *models.BlogQuery) ([]models.Blog,
 		SetSkip(int64(skip)).
 		SetLimit(int64(limit))
 
-	filter := bson.M{}
+	filter := publishedFilter()
 	cursor, err := m.blogCollection.Find(context.TODO(), filter, findOptions)
 	if err != nil {
 		return nil, 0, err
@@ -167,7 +196,7 @@ func (bc *MongoBlogRepository) DeleteBlog(BlogID string) error{
 }
 
 func (r *MongoBlogRepository) SearchBlogs(title string, authorID string) (*[]models.Blog, error) {
-    filter := bson.M{}
+    filter := publishedFilter()
 
     if title != "" {
         filter["title"] = bson.M{"$regex": title, "$options": "i"}
@@ -316,6 +345,44 @@ func (bc *MongoBlogRepository) IncrementComment(blogID string) error{
 	return nil
 }
 
+func (bc *MongoBlogRepository) IncrementShare(blogID string) error {
+	objID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objID}
+	update := bson.M{"$inc": bson.M{"sharecount": 1}}
+	_, err = bc.blogCollection.UpdateOne(context.TODO(), filter, update)
+	return err
+}
+
+// published blogs created since the given time matching any of the authors or tags, most liked first
+func (m *MongoBlogRepository) GetTopBlogsSince(since time.Time, authorIDs, tags []string, limit int) ([]models.Blog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conditions := bson.A{publishedFilter(), bson.M{"createdat": bson.M{"$gte": since}}}
+	var matchAny bson.A
+	if len(authorIDs) > 0 {
+		matchAny = append(matchAny, bson.M{"authorid": bson.M{"$in": authorIDs}})
+	}
+	if len(tags) > 0 {
+		matchAny = append(matchAny, bson.M{"tags": bson.M{"$in": tags}})
+	}
+	if len(matchAny) > 0 {
+		conditions = append(conditions, bson.M{"$or": matchAny})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "likecount", Value: -1}, {Key: "commentcount", Value: -1}, {Key: "createdat", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := m.blogCollection.Find(ctx, bson.M{"$and": conditions}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBlogs(ctx, cursor)
+}
+
 func (bc *MongoBlogRepository) DecrementComment(blogID string) error {
     objID, err := primitive.ObjectIDFromHex(blogID)
     if err != nil {
@@ -328,3 +395,125 @@ func (bc *MongoBlogRepository) DecrementComment(blogID string) error {
     _, err = bc.blogCollection.UpdateOne(context.TODO(), filter, update)
     return err
 }
+
+// moves a blog to a new publication state
+func (m *MongoBlogRepository) UpdateBlogStatus(blogID, status string) error {
+	objID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return errors.New("invalid blog ID format")
+	}
+
+	update := bson.M{"$set": bson.M{"status": status, "updatedat": time.Now()}}
+	res, err := m.blogCollection.UpdateByID(context.TODO(), objID, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("blog not found")
+	}
+	return nil
+}
+
+// moves a blog to a new publication state only if it is still in the expected one
+func (m *MongoBlogRepository) TransitionBlogStatus(blogID, fromStatus, toStatus string) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return false, errors.New("invalid blog ID format")
+	}
+
+	filter := bson.M{"status": fromStatus}
+	if fromStatus == "" || fromStatus == models.BlogStatusPublished {
+		filter = publishedFilter()
+	}
+	filter["_id"] = objID
+	update := bson.M{"$set": bson.M{"status": toStatus, "updatedat": time.Now()}}
+	res, err := m.blogCollection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// lists blogs in a given publication state, oldest first
+func (m *MongoBlogRepository) GetBlogsByStatus(status string, page, pageSize int) ([]models.Blog, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"status": status}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "updatedat", Value: 1}}).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+
+	cursor, err := m.blogCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	blogs, err := decodeBlogs(ctx, cursor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := m.blogCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return blogs, int(total), nil
+}
+
+// moves every blog of an author to a new publication state
+func (m *MongoBlogRepository) SetBlogsStatusByAuthor(authorID, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"status": status, "updatedat": time.Now()}}
+	_, err := m.blogCollection.UpdateMany(ctx, bson.M{"authorid": authorID}, update)
+	return err
+}
+
+// deletes every blog of an author along with their interactions and returns the deleted IDs
+func (m *MongoBlogRepository) DeleteBlogsByAuthor(authorID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"authorid": authorID}
+	cursor, err := m.blogCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return []string{}, nil
+	}
+
+	objIDs := make(bson.A, 0, len(docs))
+	blogIDs := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		objIDs = append(objIDs, doc.ID)
+		blogIDs = append(blogIDs, doc.ID.Hex())
+	}
+
+	if _, err := m.interactionCollection.DeleteMany(ctx, bson.M{"blogid": bson.M{"$in": objIDs}}); err != nil {
+		return nil, err
+	}
+	if _, err := m.blogCollection.DeleteMany(ctx, filter); err != nil {
+		return nil, err
+	}
+	return blogIDs, nil
+}
+
+// changes a blog's comment counter by delta
+func (m *MongoBlogRepository) AdjustCommentCount(blogID string, delta int) error {
+	objID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$inc": bson.M{"commentcount": delta}}
+	_, err = m.blogCollection.UpdateOne(context.TODO(), bson.M{"_id": objID}, update)
+	return err
+}
@@ -23,14 +23,17 @@ func NewMongoCommentRepository (commCol *mongo.Collection) repositories.IComment
 	}
 }
 
-func (r *CommentRepository) CreateComment(blogID,userID,content string)(error){
+func (r *CommentRepository) CreateComment(comment *models.Comment)(error){
 	objectID := primitive.NewObjectID()
 	newComment := bson.M{
 		"_id":objectID,
-		"blogId":blogID,
-		"userId":userID,
-		"createdat":time.Now(),
-		"updatedat":time.Now(),
+		"blogId":comment.BlogID,
+		"userId":comment.UserID,
+		"parentId":comment.ParentID,
+		"content":comment.Content,
+		"hidden":false,
+		"createdat":comment.CreatedAt,
+		"updatedat":comment.UpdatedAt,
 
 	}
 	_,err := r.commentCollection.InsertOne(context.Background(),newComment)
@@ -38,6 +41,7 @@ func (r *CommentRepository) CreateComment(blogID,userID,content string)(error){
 	if err != nil{
 		return err
 	}
+	comment.ID = objectID.Hex()
 	return nil
 
 }
@@ -50,7 +54,16 @@ func (r *CommentRepository) GetCommentByID(commentID string) (models.Comment, er
 
     filter := bson.M{"_id": oid}
 
-    var model models.Comment
+    var model struct {
+        ID        primitive.ObjectID `bson:"_id"`
+        BlogID    string             `bson:"blogId"`
+        UserID    string             `bson:"userId"`
+        ParentID  string             `bson:"parentId"`
+        Content   string             `bson:"content"`
+        Hidden    bool               `bson:"hidden"`
+        CreatedAt time.Time          `bson:"createdat"`
+        UpdatedAt time.Time          `bson:"updatedat"`
+    }
     err = r.commentCollection.FindOne(context.Background(), filter).Decode(&model)
     if err != nil {
         if err == mongo.ErrNoDocuments {
@@ -60,10 +73,12 @@ func (r *CommentRepository) GetCommentByID(commentID string) (models.Comment, er
     }
 
     comment := models.Comment{
-        ID:        model.ID,
+        ID:        model.ID.Hex(),
         BlogID:    model.BlogID,
         UserID:    model.UserID,
+        ParentID:  model.ParentID,
         Content:   model.Content,
+        Hidden:    model.Hidden,
         CreatedAt: model.CreatedAt,
         UpdatedAt: model.UpdatedAt,
     }
@@ -131,4 +146,49 @@ func (r *CommentRepository) DeleteComment(commentID string) error {
     return nil
 }
 
+// deletes every comment on a blog
+func (r *CommentRepository) DeleteCommentsByBlogID(blogID string) error {
+	_, err := r.commentCollection.DeleteMany(context.Background(), bson.M{"blogId": blogID})
+	return err
+}
+
+// deletes every comment written by a user and returns the removed count per blog
+func (r *CommentRepository) DeleteCommentsByUserID(userID string) (map[string]int, error) {
+	filter := bson.M{"userId": userID}
+	cursor, err := r.commentCollection.Find(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+	var comments []models.Comment
+	if err := cursor.All(context.Background(), &comments); err != nil {
+		return nil, err
+	}
+
+	removed := make(map[string]int)
+	for _, comment := range comments {
+		removed[comment.BlogID]++
+	}
+
+	if _, err := r.commentCollection.DeleteMany(context.Background(), filter); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// hides or restores every comment written by a user
+func (r *CommentRepository) SetCommentsHiddenByUserID(userID string, hidden bool) error {
+	update := bson.M{"$set": bson.M{"hidden": hidden, "updated_at": time.Now()}}
+	_, err := r.commentCollection.UpdateMany(context.Background(), bson.M{"userId": userID}, update)
+	return err
+}
 
+// hides or restores a single comment
+func (r *CommentRepository) SetCommentHidden(commentID string, hidden bool) error {
+	oid, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$set": bson.M{"hidden": hidden, "updated_at": time.Now()}}
+	_, err = r.commentCollection.UpdateOne(context.Background(), bson.M{"_id": oid}, update)
+	return err
+}
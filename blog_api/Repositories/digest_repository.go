@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoDigestRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoDigestRepository(collection *mongo.Collection) repositories.IDigestRepository {
+	return &MongoDigestRepository{
+		collection: collection,
+	}
+}
+
+type digestSettingsDocument struct {
+	UserID     string     `bson:"user_id"`
+	Frequency  string     `bson:"frequency"`
+	LastSentAt *time.Time `bson:"last_sent_at"`
+	UpdatedAt  time.Time  `bson:"updated_at"`
+}
+
+func (d *digestSettingsDocument) toModel() models.DigestSettings {
+	return models.DigestSettings{
+		UserID:     d.UserID,
+		Frequency:  d.Frequency,
+		LastSentAt: d.LastSentAt,
+		UpdatedAt:  d.UpdatedAt,
+	}
+}
+
+func (r *MongoDigestRepository) GetSettings(userID string) (*models.DigestSettings, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	var doc digestSettingsDocument
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return &models.DigestSettings{UserID: userID, Frequency: models.DigestOff}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	settings := doc.toModel()
+	return &settings, nil
+}
+
+func (r *MongoDigestRepository) SaveFrequency(userID, frequency string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$set":         bson.M{"frequency": frequency, "updated_at": time.Now()},
+			"$setOnInsert": bson.M{"last_sent_at": nil},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *MongoDigestRepository) ListDue(frequency string, sentBefore time.Time, limit int) ([]models.DigestSettings, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{
+		"frequency": frequency,
+		"$or": bson.A{
+			bson.M{"last_sent_at": nil},
+			bson.M{"last_sent_at": bson.M{"$lt": sentBefore}},
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "last_sent_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []digestSettingsDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	settings := make([]models.DigestSettings, 0, len(docs))
+	for i := range docs {
+		settings = append(settings, docs[i].toModel())
+	}
+	return settings, nil
+}
+
+func (r *MongoDigestRepository) ClaimRun(userID string, previous *time.Time, now time.Time) (bool, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{"user_id": userID, "last_sent_at": nil}
+	if previous != nil {
+		filter["last_sent_at"] = *previous
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"last_sent_at": now}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (r *MongoDigestRepository) DeleteSettings(userID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"user_id": userID})
+	return err
+}
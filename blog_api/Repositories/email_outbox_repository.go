@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoEmailOutboxRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoEmailOutboxRepository(collection *mongo.Collection) repositories.IEmailOutboxRepository {
+	return &MongoEmailOutboxRepository{
+		collection: collection,
+	}
+}
+
+type emailOutboxDocument struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	To            string             `bson:"to"`
+	Subject       string             `bson:"subject"`
+	TextBody      string             `bson:"text_body"`
+	HTMLBody      string             `bson:"html_body"`
+	Template      string             `bson:"template"`
+	Status        string             `bson:"status"`
+	Attempts      int                `bson:"attempts"`
+	LastError     string             `bson:"last_error,omitempty"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at"`
+	LockedUntil   *time.Time         `bson:"locked_until,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	SentAt        *time.Time         `bson:"sent_at,omitempty"`
+}
+
+func (d *emailOutboxDocument) toModel() models.EmailMessage {
+	return models.EmailMessage{
+		ID:            d.ID.Hex(),
+		To:            d.To,
+		Subject:       d.Subject,
+		TextBody:      d.TextBody,
+		HTMLBody:      d.HTMLBody,
+		Template:      d.Template,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		LastError:     d.LastError,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+		SentAt:        d.SentAt,
+	}
+}
+
+// stores a message as pending so the worker picks it up
+func (r *MongoEmailOutboxRepository) Enqueue(message *models.EmailMessage) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	now := time.Now()
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = now
+	}
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = now
+	}
+	message.Status = models.EmailStatusPending
+
+	doc := emailOutboxDocument{
+		ID:            primitive.NewObjectID(),
+		To:            message.To,
+		Subject:       message.Subject,
+		TextBody:      message.TextBody,
+		HTMLBody:      message.HTMLBody,
+		Template:      message.Template,
+		Status:        message.Status,
+		NextAttemptAt: message.NextAttemptAt,
+		CreatedAt:     message.CreatedAt,
+	}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return err
+	}
+	message.ID = doc.ID.Hex()
+	return nil
+}
+
+// claims due messages one at a time so concurrent workers never send the same email twice
+func (r *MongoEmailOutboxRepository) ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.EmailMessage, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": models.EmailStatusPending, "next_attempt_at": bson.M{"$lte": now}},
+			// a worker died mid-send; its lease has run out
+			bson.M{"status": models.EmailStatusSending, "locked_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"status":       models.EmailStatusSending,
+		"locked_until": now.Add(lease),
+	}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	messages := []models.EmailMessage{}
+	for len(messages) < limit {
+		var doc emailOutboxDocument
+		err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, doc.toModel())
+	}
+	return messages, nil
+}
+
+// marks a message as delivered
+func (r *MongoEmailOutboxRepository) MarkSent(messageID string, sentAt time.Time) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return err
+	}
+	update := bson.M{
+		"$set":   bson.M{"status": models.EmailStatusSent, "sent_at": sentAt},
+		"$inc":   bson.M{"attempts": 1},
+		"$unset": bson.M{"locked_until": "", "last_error": ""},
+	}
+	res, err := r.collection.UpdateByID(ctx, objectID, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+// records a failed delivery attempt and schedules the retry
+func (r *MongoEmailOutboxRepository) MarkFailed(messageID string, attempts int, lastError string, nextAttemptAt time.Time, giveUp bool) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return err
+	}
+	status := models.EmailStatusPending
+	if giveUp {
+		status = models.EmailStatusFailed
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":          status,
+			"attempts":        attempts,
+			"last_error":      lastError,
+			"next_attempt_at": nextAttemptAt,
+		},
+		"$unset": bson.M{"locked_until": ""},
+	}
+	res, err := r.collection.UpdateByID(ctx, objectID, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoFollowRepository struct {
+	collection    *mongo.Collection
+	tagCollection *mongo.Collection
+}
+
+func NewMongoFollowRepository(collection, tagCollection *mongo.Collection) repositories.IFollowRepository {
+	return &MongoFollowRepository{
+		collection:    collection,
+		tagCollection: tagCollection,
+	}
+}
+
+// records the follow; an upsert keeps repeated requests from creating duplicates
+func (r *MongoFollowRepository) Follow(follow *models.Follow) (bool, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"follower_id": follow.FollowerID, "followee_id": follow.FolloweeID},
+		bson.M{"$setOnInsert": bson.M{
+			"follower_id": follow.FollowerID,
+			"followee_id": follow.FolloweeID,
+			"created_at":  follow.CreatedAt,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount > 0, nil
+}
+
+func (r *MongoFollowRepository) Unfollow(followerID, followeeID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"follower_id": followerID, "followee_id": followeeID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoFollowRepository) IsFollowing(followerID, followeeID string) (bool, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"follower_id": followerID, "followee_id": followeeID})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MongoFollowRepository) CountFollowers(userID string) (int, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"followee_id": userID})
+	return int(count), err
+}
+
+func (r *MongoFollowRepository) CountFollowing(userID string) (int, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"follower_id": userID})
+	return int(count), err
+}
+
+// lists the IDs of the users someone follows
+func (r *MongoFollowRepository) ListFollowingIDs(userID string) ([]string, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"follower_id": userID}, options.Find().SetProjection(bson.M{"followee_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		FolloweeID string `bson:"followee_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.FolloweeID)
+	}
+	return ids, nil
+}
+
+func (r *MongoFollowRepository) FollowTag(userID, tag string) (bool, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	res, err := r.tagCollection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "tag": tag},
+		bson.M{"$setOnInsert": bson.M{"user_id": userID, "tag": tag, "created_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount > 0, nil
+}
+
+func (r *MongoFollowRepository) UnfollowTag(userID, tag string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	res, err := r.tagCollection.DeleteOne(ctx, bson.M{"user_id": userID, "tag": tag})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+// lists a user's followed tags alphabetically
+func (r *MongoFollowRepository) ListFollowedTags(userID string) ([]string, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "tag", Value: 1}})
+	cursor, err := r.tagCollection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		Tag string `bson:"tag"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		tags = append(tags, doc.Tag)
+	}
+	return tags, nil
+}
+
+func (r *MongoFollowRepository) DeleteFollowsByUserID(userID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{bson.M{"follower_id": userID}, bson.M{"followee_id": userID}}}
+	if _, err := r.collection.DeleteMany(ctx, filter); err != nil {
+		return err
+	}
+	_, err := r.tagCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoLoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+// creates the TTL index once at startup so failed logins do not pay for it
+func NewMongoLoginAttemptRepository(collection *mongo.Collection) (repositories.ILoginAttemptRepository, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	// quiet counters are dropped by MongoDB a day after their last failure
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "last_failure_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(24 * 3600),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create login attempt index: %v", err)
+	}
+	return &MongoLoginAttemptRepository{
+		collection: collection,
+	}, nil
+}
+
+type loginThrottleDocument struct {
+	Key            string     `bson:"_id"`
+	Failures       int        `bson:"failures"`
+	FirstFailureAt time.Time  `bson:"first_failure_at"`
+	LastFailureAt  time.Time  `bson:"last_failure_at"`
+	LockedUntil    *time.Time `bson:"locked_until,omitempty"`
+}
+
+func (d *loginThrottleDocument) toModel() *models.LoginThrottle {
+	return &models.LoginThrottle{
+		Key:            d.Key,
+		Failures:       d.Failures,
+		FirstFailureAt: d.FirstFailureAt,
+		LastFailureAt:  d.LastFailureAt,
+		LockedUntil:    d.LockedUntil,
+	}
+}
+
+func (r *MongoLoginAttemptRepository) Get(key string) (*models.LoginThrottle, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	var doc loginThrottleDocument
+	if err := r.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	return doc.toModel(), nil
+}
+
+func (r *MongoLoginAttemptRepository) RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginThrottle, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{
+		"_id":             key,
+		"last_failure_at": bson.M{"$lt": now.Add(-window)},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{
+		"$inc":         bson.M{"failures": 1},
+		"$set":         bson.M{"last_failure_at": now},
+		"$setOnInsert": bson.M{"first_failure_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var doc loginThrottleDocument
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc.toModel(), nil
+}
+
+func (r *MongoLoginAttemptRepository) Lock(key string, until time.Time) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$set": bson.M{"locked_until": until}})
+	return err
+}
+
+func (r *MongoLoginAttemptRepository) Delete(keys ...string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": keys}})
+	return err
+}
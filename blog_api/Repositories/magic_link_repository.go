@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoMagicLinkRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoMagicLinkRepository(collection *mongo.Collection) (repositories.IMagicLinkRepository, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	// lookups go by token hash; MongoDB drops links a day after they expire
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(24 * 3600)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create magic link indexes: %v", err)
+	}
+	return &MongoMagicLinkRepository{
+		collection: collection,
+	}, nil
+}
+
+type magicLinkDocument struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Email     string             `bson:"email"`
+	TokenHash string             `bson:"token_hash"`
+	CreatedAt time.Time          `bson:"created_at"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty"`
+}
+
+func (d *magicLinkDocument) toModel() *models.MagicLink {
+	return &models.MagicLink{
+		ID:        d.ID.Hex(),
+		Email:     d.Email,
+		TokenHash: d.TokenHash,
+		CreatedAt: d.CreatedAt,
+		ExpiresAt: d.ExpiresAt,
+		UsedAt:    d.UsedAt,
+	}
+}
+
+func (r *MongoMagicLinkRepository) Create(link *models.MagicLink) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	doc := magicLinkDocument{
+		ID:        primitive.NewObjectID(),
+		Email:     link.Email,
+		TokenHash: link.TokenHash,
+		CreatedAt: link.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+	}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return err
+	}
+	link.ID = doc.ID.Hex()
+	return nil
+}
+
+func (r *MongoMagicLinkRepository) Consume(tokenHash string, now time.Time) (*models.MagicLink, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	// a single update so two clicks racing each other cannot both sign in
+	filter := bson.M{
+		"token_hash": tokenHash,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var doc magicLinkDocument
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": now}}, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	return doc.toModel(), nil
+}
+
+func (r *MongoMagicLinkRepository) CountSince(email string, since time.Time) (int64, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	return r.collection.CountDocuments(ctx, bson.M{"email": email, "created_at": bson.M{"$gte": since}})
+}
@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoNotificationRepository struct {
+	collection            *mongo.Collection
+	preferencesCollection *mongo.Collection
+}
+
+func NewMongoNotificationRepository(collection, preferencesCollection *mongo.Collection) repositories.INotificationRepository {
+	return &MongoNotificationRepository{
+		collection:            collection,
+		preferencesCollection: preferencesCollection,
+	}
+}
+
+type notificationDocument struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	UserID    string             `bson:"user_id"`
+	ActorID   string             `bson:"actor_id"`
+	Type      string             `bson:"type"`
+	BlogID    string             `bson:"blog_id,omitempty"`
+	CommentID string             `bson:"comment_id,omitempty"`
+	Message   string             `bson:"message"`
+	Read      bool               `bson:"read"`
+	ReadAt    *time.Time         `bson:"read_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+func (d *notificationDocument) toModel() models.Notification {
+	return models.Notification{
+		ID:        d.ID.Hex(),
+		UserID:    d.UserID,
+		ActorID:   d.ActorID,
+		Type:      d.Type,
+		BlogID:    d.BlogID,
+		CommentID: d.CommentID,
+		Message:   d.Message,
+		Read:      d.Read,
+		ReadAt:    d.ReadAt,
+		CreatedAt: d.CreatedAt,
+	}
+}
+
+func (r *MongoNotificationRepository) CreateNotification(notification *models.Notification) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	doc := notificationDocument{
+		ID:        primitive.NewObjectID(),
+		UserID:    notification.UserID,
+		ActorID:   notification.ActorID,
+		Type:      notification.Type,
+		BlogID:    notification.BlogID,
+		CommentID: notification.CommentID,
+		Message:   notification.Message,
+		Read:      false,
+		CreatedAt: notification.CreatedAt,
+	}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return err
+	}
+	notification.ID = doc.ID.Hex()
+	return nil
+}
+
+// lists a user's notifications, newest first
+func (r *MongoNotificationRepository) ListNotifications(userID string, query *models.NotificationQuery) ([]models.Notification, int, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{"user_id": userID}
+	if query.UnreadOnly {
+		filter["read"] = false
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((query.Page - 1) * query.PageSize)).
+		SetLimit(int64(query.PageSize))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	var docs []notificationDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	notifications := make([]models.Notification, 0, len(docs))
+	for i := range docs {
+		notifications = append(notifications, docs[i].toModel())
+	}
+	return notifications, int(total), nil
+}
+
+func (r *MongoNotificationRepository) CountUnread(userID string) (int, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"user_id": userID, "read": false})
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *MongoNotificationRepository) MarkRead(userID, notificationID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(notificationID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "user_id": userID},
+		bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+// marks every unread notification as read and returns how many changed
+func (r *MongoNotificationRepository) MarkAllRead(userID string) (int, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	res, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "read": false},
+		bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(res.ModifiedCount), nil
+}
+
+func (r *MongoNotificationRepository) GetPreferences(userID string) (*models.NotificationPreferences, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	var doc struct {
+		UserID    string          `bson:"user_id"`
+		Types     map[string]bool `bson:"types"`
+		UpdatedAt time.Time       `bson:"updated_at"`
+	}
+	err := r.preferencesCollection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return &models.NotificationPreferences{UserID: userID, Types: map[string]bool{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if doc.Types == nil {
+		doc.Types = map[string]bool{}
+	}
+	return &models.NotificationPreferences{UserID: doc.UserID, Types: doc.Types, UpdatedAt: doc.UpdatedAt}, nil
+}
+
+func (r *MongoNotificationRepository) SavePreferences(prefs *models.NotificationPreferences) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	_, err := r.preferencesCollection.UpdateOne(ctx,
+		bson.M{"user_id": prefs.UserID},
+		bson.M{"$set": bson.M{"types": prefs.Types, "updated_at": prefs.UpdatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *MongoNotificationRepository) DeleteNotificationsByUserID(userID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{bson.M{"user_id": userID}, bson.M{"actor_id": userID}}}
+	if _, err := r.collection.DeleteMany(ctx, filter); err != nil {
+		return err
+	}
+	_, err := r.preferencesCollection.DeleteOne(ctx, bson.M{"user_id": userID})
+	return err
+}
@@ -2,96 +2,409 @@ package repositories
 
 import (
 	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
 	"blog_api/Domain/models"
-	"context"
+	"blog_api/Repositories/database"
+	"fmt"
+	"log"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// implements IOAuthRepository for MongoDB
+// implements IOAuthRepository for MongoDB; provider tokens are encrypted at rest
 type MongoOAuthRepository struct {
 	collection *mongo.Collection
+	cipher     services.ISecretCipher
 }
 
+// a provider account can only be linked once; the unique index is created at startup
+func NewMongoOAuthRepository(collection *mongo.Collection, cipher services.ISecretCipher) (repositories.IOAuthRepository, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
 
-func NewMongoOAuthRepository(collection *mongo.Collection) repositories.IOAuthRepository {
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "provider", Value: 1}, {Key: "provider_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OAuth link index: %v", err)
+	}
 	return &MongoOAuthRepository{
 		collection: collection,
+		cipher:     cipher,
+	}, nil
+}
+
+type oauthUserDocument struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	UserID       string             `bson:"user_id"`
+	Provider     string             `bson:"provider"`
+	ProviderID   string             `bson:"provider_id"`
+	Email        string             `bson:"email"`
+	Name         string             `bson:"name"`
+	Picture      string             `bson:"picture"`
+	AccessToken  string             `bson:"access_token"`
+	RefreshToken string             `bson:"refresh_token"`
+	TokenKeyID   string             `bson:"token_key_id,omitempty"`
+	ExpiresAt    *time.Time         `bson:"expires_at,omitempty"`
+	// last failed background refresh; cleared by the next token write
+	RefreshFailedAt *time.Time `bson:"refresh_failed_at,omitempty"`
+	CreatedAt       time.Time  `bson:"created_at"`
+	UpdatedAt       time.Time  `bson:"updated_at"`
+}
+
+// decrypts the stored tokens; rows written before encryption hold them in plaintext
+func (r *MongoOAuthRepository) toModel(d *oauthUserDocument) (*models.OAuthUser, error) {
+	accessToken, err := r.cipher.Decrypt(d.AccessToken)
+	if err != nil {
+		return nil, err
 	}
+	refreshToken, err := r.cipher.Decrypt(d.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OAuthUser{
+		ID:           d.ID.Hex(),
+		UserID:       d.UserID,
+		Provider:     d.Provider,
+		ProviderID:   d.ProviderID,
+		Email:        d.Email,
+		Name:         d.Name,
+		Picture:      d.Picture,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    d.ExpiresAt,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    d.UpdatedAt,
+	}, nil
 }
 
-// creates a new OAuth user record
+// encrypts both tokens under the active key
+func (r *MongoOAuthRepository) sealTokens(accessToken, refreshToken string) (string, string, error) {
+	sealedAccess, err := r.cipher.Encrypt(accessToken)
+	if err != nil {
+		return "", "", err
+	}
+	sealedRefresh, err := r.cipher.Encrypt(refreshToken)
+	if err != nil {
+		return "", "", err
+	}
+	return sealedAccess, sealedRefresh, nil
+}
+
+// like toModel, but a row whose tokens cannot be decrypted (say its key was retired) comes back without them;
+// signing in, listing and unlinking never need the stored provider tokens
+func (r *MongoOAuthRepository) toLink(d *oauthUserDocument) *models.OAuthUser {
+	m, err := r.toModel(d)
+	if err == nil {
+		return m
+	}
+	log.Printf("oauth: cannot decrypt tokens of link %s, treating them as empty: %v", d.ID.Hex(), err)
+	stripped := *d
+	stripped.AccessToken, stripped.RefreshToken = "", ""
+	m, _ = r.toModel(&stripped)
+	return m
+}
+
+// decrypts each row, logging and skipping those that fail; returns the IDs of the skipped rows
+func (r *MongoOAuthRepository) toModels(docs []oauthUserDocument) ([]models.OAuthUser, []primitive.ObjectID) {
+	out := make([]models.OAuthUser, 0, len(docs))
+	var skipped []primitive.ObjectID
+	for i := range docs {
+		m, err := r.toModel(&docs[i])
+		if err != nil {
+			log.Printf("oauth: skipping link %s with undecryptable tokens: %v", docs[i].ID.Hex(), err)
+			skipped = append(skipped, docs[i].ID)
+			continue
+		}
+		out = append(out, *m)
+	}
+	return out, skipped
+}
+
+// creates a new OAuth user record; a provider account can only be linked once
 func (r *MongoOAuthRepository) CreateOAuthUser(oauthUser *models.OAuthUser) error {
-	oauthUser.ID = primitive.NewObjectID().Hex()
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	accessToken, refreshToken, err := r.sealTokens(oauthUser.AccessToken, oauthUser.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	objectID := primitive.NewObjectID()
+	oauthUser.ID = objectID.Hex()
 	oauthUser.CreatedAt = time.Now()
 	oauthUser.UpdatedAt = time.Now()
 
-	_, err := r.collection.InsertOne(context.TODO(), oauthUser)
+	doc := oauthUserDocument{
+		ID:           objectID,
+		UserID:       oauthUser.UserID,
+		Provider:     oauthUser.Provider,
+		ProviderID:   oauthUser.ProviderID,
+		Email:        oauthUser.Email,
+		Name:         oauthUser.Name,
+		Picture:      oauthUser.Picture,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenKeyID:   r.cipher.ActiveKeyID(),
+		ExpiresAt:    oauthUser.ExpiresAt,
+		CreatedAt:    oauthUser.CreatedAt,
+		UpdatedAt:    oauthUser.UpdatedAt,
+	}
+	_, err = r.collection.InsertOne(ctx, doc)
 	return err
 }
 
 // retrieves OAuth user by provider ID
 func (r *MongoOAuthRepository) GetOAuthUserByProviderID(provider, providerID string) (*models.OAuthUser, error) {
-	filter := bson.M{
+	return r.findOne(bson.M{
 		"provider":    provider,
 		"provider_id": providerID,
-	}
+	})
+}
 
-	var oauthUser models.OAuthUser
-	err := r.collection.FindOne(context.TODO(), filter).Decode(&oauthUser)
-	if err != nil {
+// retrieves OAuth user by email and provider
+func (r *MongoOAuthRepository) GetOAuthUserByEmail(provider, email string) (*models.OAuthUser, error) {
+	return r.findOne(bson.M{
+		"provider": provider,
+		"email":    email,
+	})
+}
+
+func (r *MongoOAuthRepository) findOne(filter bson.M) (*models.OAuthUser, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	var doc oauthUserDocument
+	if err := r.collection.FindOne(ctx, filter).Decode(&doc); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, repositories.ErrNotFound
 		}
 		return nil, err
 	}
+	return r.toLink(&doc), nil
+}
+
+// lists the provider accounts linked to a user, oldest first
+func (r *MongoOAuthRepository) ListOAuthUsersByUserID(userID string) ([]models.OAuthUser, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
 
-	return &oauthUser, nil
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []oauthUserDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	accounts := make([]models.OAuthUser, 0, len(docs))
+	for i := range docs {
+		accounts = append(accounts, *r.toLink(&docs[i]))
+	}
+	return accounts, nil
 }
 
-// retrieves OAuth user by email and provider
-func (r *MongoOAuthRepository) GetOAuthUserByEmail(provider, email string) (*models.OAuthUser, error) {
+// lists links holding a refresh token whose access token expires before the given time, soonest first.
+// Links whose last refresh failed come after the rest, longest-failed first, so they cannot starve the batch.
+func (r *MongoOAuthRepository) ListOAuthUsersExpiringBefore(before time.Time, limit int) ([]models.OAuthUser, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
 	filter := bson.M{
-		"provider": provider,
-		"email":    email,
+		"refresh_token": bson.M{"$nin": bson.A{"", nil}},
+		"expires_at":    bson.M{"$lt": before},
 	}
-
-	var oauthUser models.OAuthUser
-	err := r.collection.FindOne(context.TODO(), filter).Decode(&oauthUser)
+	// a missing refresh_failed_at sorts before any timestamp
+	opts := options.Find().
+		SetSort(bson.D{{Key: "refresh_failed_at", Value: 1}, {Key: "expires_at", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, repositories.ErrNotFound
-		}
 		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	return &oauthUser, nil
+	var docs []oauthUserDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	links, skipped := r.toModels(docs)
+	if len(skipped) > 0 {
+		// count them as failed refreshes so they sort after the links that can still be renewed
+		if _, err := r.collection.UpdateMany(ctx,
+			bson.M{"_id": bson.M{"$in": skipped}},
+			bson.M{"$set": bson.M{"refresh_failed_at": time.Now()}},
+		); err != nil {
+			return nil, err
+		}
+	}
+	return links, nil
 }
 
-// updates an existing OAuth user record
+// re-encrypts up to limit rows still stored in plaintext or under a retired key, returning how many were rewritten.
+// Rows that cannot be decrypted are logged and skipped so they do not block the rest.
+func (r *MongoOAuthRepository) ReencryptTokens(limit int) (int, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	active := r.cipher.ActiveKeyID()
+	filter := bson.M{
+		"token_key_id": bson.M{"$ne": active},
+		"$or": bson.A{
+			bson.M{"access_token": bson.M{"$nin": bson.A{"", nil}}},
+			bson.M{"refresh_token": bson.M{"$nin": bson.A{"", nil}}},
+		},
+	}
+	// walk by _id so rows skipped in one batch are not fetched again by the next
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+
+	rewritten := 0
+	for rewritten < limit {
+		cursor, err := r.collection.Find(ctx, filter, opts)
+		if err != nil {
+			return rewritten, err
+		}
+		var docs []oauthUserDocument
+		if err := cursor.All(ctx, &docs); err != nil {
+			return rewritten, err
+		}
+
+		for i := range docs {
+			m, err := r.toModel(&docs[i])
+			if err != nil {
+				log.Printf("oauth: skipping re-encryption of link %s: %v", docs[i].ID.Hex(), err)
+				continue
+			}
+			accessToken, refreshToken, err := r.sealTokens(m.AccessToken, m.RefreshToken)
+			if err != nil {
+				return rewritten, err
+			}
+			// matching the old ciphertext skips rows a concurrent refresh already rewrote
+			res, err := r.collection.UpdateOne(ctx, bson.M{
+				"_id":           docs[i].ID,
+				"access_token":  storedToken(docs[i].AccessToken),
+				"refresh_token": storedToken(docs[i].RefreshToken),
+			}, bson.M{"$set": bson.M{
+				"access_token":  accessToken,
+				"refresh_token": refreshToken,
+				"token_key_id":  active,
+			}})
+			if err != nil {
+				return rewritten, err
+			}
+			rewritten += int(res.ModifiedCount)
+		}
+
+		if len(docs) < limit {
+			break
+		}
+		filter["_id"] = bson.M{"$gt": docs[len(docs)-1].ID}
+	}
+	return rewritten, nil
+}
+
+// matches a token as it was read; an empty one may be stored as "" or null or be missing entirely
+func storedToken(value string) interface{} {
+	if value == "" {
+		return bson.M{"$in": bson.A{"", nil}}
+	}
+	return value
+}
+
+// records a failed background refresh so the link is retried after the others
+func (r *MongoOAuthRepository) MarkRefreshFailed(oauthUserID string, at time.Time) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(oauthUserID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"refresh_failed_at": at}})
+	return err
+}
+
+// updates the profile and tokens of an existing OAuth user record
 func (r *MongoOAuthRepository) UpdateOAuthUser(oauthUser *models.OAuthUser) error {
-	oauthUser.UpdatedAt = time.Now()
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
 
-	filter := bson.M{"_id": oauthUser.ID}
-	update := bson.M{"$set": oauthUser}
+	objectID, err := primitive.ObjectIDFromHex(oauthUser.ID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+	accessToken, refreshToken, err := r.sealTokens(oauthUser.AccessToken, oauthUser.RefreshToken)
+	if err != nil {
+		return err
+	}
+	oauthUser.UpdatedAt = time.Now()
 
-	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	set := bson.M{
+		"email":         oauthUser.Email,
+		"name":          oauthUser.Name,
+		"picture":       oauthUser.Picture,
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"token_key_id":  r.cipher.ActiveKeyID(),
+		"updated_at":    oauthUser.UpdatedAt,
+	}
+	// fresh tokens clear any earlier refresh failure
+	unset := bson.M{"refresh_failed_at": ""}
+	update := bson.M{"$set": set, "$unset": unset}
+	if oauthUser.ExpiresAt != nil {
+		set["expires_at"] = oauthUser.ExpiresAt
+	} else {
+		unset["expires_at"] = ""
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
 	return err
 }
 
 // links an OAuth user to an existing user account
 func (r *MongoOAuthRepository) LinkOAuthToUser(oauthUserID, userID string) error {
-	filter := bson.M{"_id": oauthUserID}
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(oauthUserID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
 	update := bson.M{
 		"$set": bson.M{
 			"user_id":    userID,
 			"updated_at": time.Now(),
 		},
 	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return err
+}
+
+// removes a user's links to one provider, returning how many were removed
+func (r *MongoOAuthRepository) DeleteOAuthUsersByProvider(userID, provider string) (int64, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	res, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID, "provider": provider})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// removes every OAuth account linked to a user
+func (r *MongoOAuthRepository) DeleteOAuthUsersByUserID(userID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
 
-	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
 	return err
 }
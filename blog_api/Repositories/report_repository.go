@@ -0,0 +1,246 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoReportRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoReportRepository(collection *mongo.Collection) repositories.IReportRepository {
+	return &MongoReportRepository{
+		collection: collection,
+	}
+}
+
+type reportEntryDocument struct {
+	ReporterID string    `bson:"reporter_id"`
+	Reason     string    `bson:"reason"`
+	Details    string    `bson:"details"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+type reportDocument struct {
+	ID             primitive.ObjectID    `bson:"_id"`
+	TargetType     string                `bson:"target_type"`
+	TargetID       string                `bson:"target_id"`
+	TargetOwnerID  string                `bson:"target_owner_id"`
+	Status         string                `bson:"status"`
+	ReportCount    int                   `bson:"report_count"`
+	ReasonCounts   map[string]int        `bson:"reason_counts"`
+	Reports        []reportEntryDocument `bson:"reports"`
+	AutoHidden     bool                  `bson:"auto_hidden"`
+	HiddenFrom     string                `bson:"hidden_from"`
+	Resolution     string                `bson:"resolution"`
+	ResolutionNote string                `bson:"resolution_note"`
+	ResolvedBy     string                `bson:"resolved_by"`
+	ResolvedAt     *time.Time            `bson:"resolved_at"`
+	CreatedAt      time.Time             `bson:"created_at"`
+	LastReportedAt time.Time             `bson:"last_reported_at"`
+}
+
+func (d *reportDocument) toModel() *models.ContentReport {
+	report := &models.ContentReport{
+		ID:             d.ID.Hex(),
+		TargetType:     d.TargetType,
+		TargetID:       d.TargetID,
+		TargetOwnerID:  d.TargetOwnerID,
+		Status:         d.Status,
+		ReportCount:    d.ReportCount,
+		ReasonCounts:   d.ReasonCounts,
+		Reports:        make([]models.ReportEntry, 0, len(d.Reports)),
+		AutoHidden:     d.AutoHidden,
+		HiddenFrom:     d.HiddenFrom,
+		Resolution:     d.Resolution,
+		ResolutionNote: d.ResolutionNote,
+		ResolvedBy:     d.ResolvedBy,
+		ResolvedAt:     d.ResolvedAt,
+		CreatedAt:      d.CreatedAt,
+		LastReportedAt: d.LastReportedAt,
+	}
+	for _, e := range d.Reports {
+		report.Reports = append(report.Reports, models.ReportEntry{
+			ReporterID: e.ReporterID,
+			Reason:     e.Reason,
+			Details:    e.Details,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	return report
+}
+
+// adds a report to the target's open case, creating the case on the first report
+func (r *MongoReportRepository) AddReport(targetType, targetID, ownerID string, entry models.ReportEntry) (*models.ContentReport, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{"target_type": targetType, "target_id": targetID, "status": models.ReportStatusOpen}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"target_owner_id": ownerID,
+			"auto_hidden":     false,
+			"created_at":      entry.CreatedAt,
+		},
+		"$inc": bson.M{
+			"report_count":                  1,
+			"reason_counts." + entry.Reason: 1,
+		},
+		"$push": bson.M{"reports": reportEntryDocument{
+			ReporterID: entry.ReporterID,
+			Reason:     entry.Reason,
+			Details:    entry.Details,
+			CreatedAt:  entry.CreatedAt,
+		}},
+		"$set": bson.M{"last_reported_at": entry.CreatedAt},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var doc reportDocument
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc.toModel(), nil
+}
+
+// reports whether a reader already has a report in the target's open case
+func (r *MongoReportRepository) HasOpenReportFrom(targetType, targetID, reporterID string) (bool, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"target_type":         targetType,
+		"target_id":           targetID,
+		"status":              models.ReportStatusOpen,
+		"reports.reporter_id": reporterID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MongoReportRepository) GetReportByID(reportID string) (*models.ContentReport, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, repositories.ErrNotFound
+	}
+	var doc reportDocument
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	return doc.toModel(), nil
+}
+
+// lists cases with the most reports first, then the most recently reported
+func (r *MongoReportRepository) ListReports(status, targetType string, page, pageSize int) ([]models.ContentReport, int, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if targetType != "" {
+		filter["target_type"] = targetType
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "report_count", Value: -1}, {Key: "last_reported_at", Value: -1}}).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	var docs []reportDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, 0, err
+	}
+
+	reports := make([]models.ContentReport, 0, len(docs))
+	for i := range docs {
+		reports = append(reports, *docs[i].toModel())
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return reports, int(total), nil
+}
+
+// flags a case whose content was hidden automatically
+func (r *MongoReportRepository) MarkAutoHidden(reportID, hiddenFrom string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.UpdateByID(ctx, objectID, bson.M{"$set": bson.M{"auto_hidden": true, "hidden_from": hiddenFrom}})
+	return err
+}
+
+// closes an open case
+func (r *MongoReportRepository) ResolveReport(reportID, status, resolution, note, moderatorID string, resolvedAt time.Time) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return err
+	}
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "status": models.ReportStatusOpen},
+		bson.M{"$set": bson.M{
+			"status":          status,
+			"resolution":      resolution,
+			"resolution_note": note,
+			"resolved_by":     moderatorID,
+			"resolved_at":     resolvedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+// counts stay as they were so moderators still see how often content was reported
+func (r *MongoReportRepository) DeleteReportsByUserID(userID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"target_owner_id": userID}); err != nil {
+		return err
+	}
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"reports.reporter_id": userID},
+		bson.M{"$set": bson.M{"reports.$[entry].reporter_id": ""}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"entry.reporter_id": userID}},
+		}),
+	)
+	return err
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoReviewRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoReviewRepository(collection *mongo.Collection) repositories.IReviewRepository {
+	return &MongoReviewRepository{
+		collection: collection,
+	}
+}
+
+// records a review workflow transition
+func (r *MongoReviewRepository) CreateReview(review *models.BlogReview) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID := primitive.NewObjectID()
+	doc := bson.M{
+		"_id":         objectID,
+		"blog_id":     review.BlogID,
+		"author_id":   review.AuthorID,
+		"actor_id":    review.ActorID,
+		"action":      review.Action,
+		"from_status": review.FromStatus,
+		"to_status":   review.ToStatus,
+		"comment":     review.Comment,
+		"created_at":  review.CreatedAt,
+	}
+
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return err
+	}
+	review.ID = objectID.Hex()
+	return nil
+}
+
+// retrieves the review history of a blog, oldest first
+func (r *MongoReviewRepository) GetReviewsByBlogID(blogID string) ([]models.BlogReview, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"blog_id": blogID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []struct {
+		ID         primitive.ObjectID `bson:"_id"`
+		BlogID     string             `bson:"blog_id"`
+		AuthorID   string             `bson:"author_id"`
+		ActorID    string             `bson:"actor_id"`
+		Action     string             `bson:"action"`
+		FromStatus string             `bson:"from_status"`
+		ToStatus   string             `bson:"to_status"`
+		Comment    string             `bson:"comment"`
+		CreatedAt  primitive.DateTime `bson:"created_at"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	reviews := make([]models.BlogReview, 0, len(docs))
+	for _, doc := range docs {
+		reviews = append(reviews, models.BlogReview{
+			ID:         doc.ID.Hex(),
+			BlogID:     doc.BlogID,
+			AuthorID:   doc.AuthorID,
+			ActorID:    doc.ActorID,
+			Action:     doc.Action,
+			FromStatus: doc.FromStatus,
+			ToStatus:   doc.ToStatus,
+			Comment:    doc.Comment,
+			CreatedAt:  doc.CreatedAt.Time(),
+		})
+	}
+	return reviews, nil
+}
+
+func (r *MongoReviewRepository) DeleteReviewsByAuthorID(authorID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"author_id": authorID})
+	return err
+}
@@ -4,10 +4,13 @@ import (
 	"blog_api/Domain/contracts/repositories"
 	"blog_api/Domain/models"
 	"context"
+	"errors"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 
@@ -34,18 +37,7 @@ func (r *MongoRoleRepository) GetRoleByID(roleID string) (*models.Role, error) {
     if err := r.collection.FindOne(context.TODO(), filter).Decode(&doc); err != nil {
         return nil, err
     }
-    var role models.Role
-    role.ID = objectID.Hex()
-    if v, ok := doc["role"].(string); ok {
-        role.Role = v
-    }
-    if v, ok := doc["created_at"].(primitive.DateTime); ok {
-        role.CreatedAt = v.Time()
-    }
-    if v, ok := doc["updated_at"].(primitive.DateTime); ok {
-        role.UpdatedAt = v.Time()
-    }
-    return &role, nil
+    return documentToRole(doc), nil
 }
 
 // retrieves role ID by role name
@@ -60,3 +52,155 @@ func (r *MongoRoleRepository) GetRoleIDByName(roleName string) (string, error) {
     }
     return "", nil
 }
+
+// retrieves a role by name
+func (r *MongoRoleRepository) GetRoleByName(roleName string) (*models.Role, error) {
+	var doc bson.M
+	if err := r.collection.FindOne(context.TODO(), bson.M{"role": roleName}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	return documentToRole(doc), nil
+}
+
+// lists all roles ordered by name
+func (r *MongoRoleRepository) ListRoles() ([]models.Role, error) {
+	cursor, err := r.collection.Find(context.TODO(), bson.M{}, options.Find().SetSort(bson.D{{Key: "role", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []bson.M
+	if err := cursor.All(context.TODO(), &docs); err != nil {
+		return nil, err
+	}
+
+	roles := make([]models.Role, 0, len(docs))
+	for _, doc := range docs {
+		roles = append(roles, *documentToRole(doc))
+	}
+	return roles, nil
+}
+
+// creates a new role
+func (r *MongoRoleRepository) CreateRole(role *models.Role) error {
+	objectID := primitive.NewObjectID()
+	doc := bson.M{
+		"_id":         objectID,
+		"role":        role.Role,
+		"description": role.Description,
+		"permissions": role.Permissions,
+		"created_at":  role.CreatedAt,
+		"updated_at":  role.UpdatedAt,
+	}
+	if _, err := r.collection.InsertOne(context.TODO(), doc); err != nil {
+		return err
+	}
+	role.ID = objectID.Hex()
+	return nil
+}
+
+// updates a role's description and permissions
+func (r *MongoRoleRepository) UpdateRole(role *models.Role) error {
+	objectID, err := primitive.ObjectIDFromHex(role.ID)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$set": bson.M{
+		"description": role.Description,
+		"permissions": role.Permissions,
+		"updated_at":  role.UpdatedAt,
+	}}
+	res, err := r.collection.UpdateByID(context.TODO(), objectID, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+// deletes a role
+func (r *MongoRoleRepository) DeleteRole(roleID string) error {
+	objectID, err := primitive.ObjectIDFromHex(roleID)
+	if err != nil {
+		return err
+	}
+	res, err := r.collection.DeleteOne(context.TODO(), bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+// creates the role if missing and gives it default permissions if it has none
+func (r *MongoRoleRepository) EnsureRole(roleName string, permissions []string) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(
+		context.TODO(),
+		bson.M{"role": roleName},
+		bson.M{"$setOnInsert": bson.M{"role": roleName, "permissions": permissions, "created_at": now, "updated_at": now}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	// roles created before permissions existed get the defaults once
+	_, err = r.collection.UpdateOne(
+		context.TODO(),
+		bson.M{"role": roleName, "permissions": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"permissions": permissions, "updated_at": now}},
+	)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	return nil
+}
+
+// adds permissions to a role, keeping the ones it already has
+func (r *MongoRoleRepository) GrantPermissions(roleName string, permissions []string) error {
+	_, err := r.collection.UpdateOne(
+		context.TODO(),
+		bson.M{"role": roleName},
+		bson.M{
+			"$addToSet": bson.M{"permissions": bson.M{"$each": permissions}},
+			"$set":      bson.M{"updated_at": time.Now()},
+		},
+	)
+	return err
+}
+
+// converts a BSON document to a Role model
+func documentToRole(doc bson.M) *models.Role {
+	var role models.Role
+	if id, ok := doc["_id"].(primitive.ObjectID); ok {
+		role.ID = id.Hex()
+	}
+	if v, ok := doc["role"].(string); ok {
+		role.Role = v
+	}
+	if v, ok := doc["description"].(string); ok {
+		role.Description = v
+	}
+	role.Permissions = []string{}
+	if perms, ok := doc["permissions"].(primitive.A); ok {
+		for _, p := range perms {
+			if s, ok := p.(string); ok {
+				role.Permissions = append(role.Permissions, s)
+			}
+		}
+	}
+	if v, ok := doc["created_at"].(primitive.DateTime); ok {
+		role.CreatedAt = v.Time()
+	}
+	if v, ok := doc["updated_at"].(primitive.DateTime); ok {
+		role.UpdatedAt = v.Time()
+	}
+	return &role
+}
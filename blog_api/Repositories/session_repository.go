@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"blog_api/Repositories/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoSessionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoSessionRepository(collection *mongo.Collection) repositories.ISessionRepository {
+	return &MongoSessionRepository{
+		collection: collection,
+	}
+}
+
+type sessionDocument struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	UserID     string             `bson:"user_id"`
+	UserAgent  string             `bson:"user_agent"`
+	Device     string             `bson:"device"`
+	IPAddress  string             `bson:"ip_address"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	LastUsedAt time.Time          `bson:"last_used_at"`
+	ExpiresAt  time.Time          `bson:"expires_at"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty"`
+}
+
+func (d *sessionDocument) toModel() models.Session {
+	return models.Session{
+		ID:         d.ID.Hex(),
+		UserID:     d.UserID,
+		UserAgent:  d.UserAgent,
+		Device:     d.Device,
+		IPAddress:  d.IPAddress,
+		CreatedAt:  d.CreatedAt,
+		LastUsedAt: d.LastUsedAt,
+		ExpiresAt:  d.ExpiresAt,
+		RevokedAt:  d.RevokedAt,
+	}
+}
+
+func (r *MongoSessionRepository) Create(session *models.Session) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	doc := sessionDocument{
+		ID:         primitive.NewObjectID(),
+		UserID:     session.UserID,
+		UserAgent:  session.UserAgent,
+		Device:     session.Device,
+		IPAddress:  session.IPAddress,
+		CreatedAt:  session.CreatedAt,
+		LastUsedAt: session.LastUsedAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return err
+	}
+	session.ID = doc.ID.Hex()
+	return nil
+}
+
+func (r *MongoSessionRepository) GetByID(sessionID string) (*models.Session, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return nil, repositories.ErrNotFound
+	}
+	var doc sessionDocument
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	session := doc.toModel()
+	return &session, nil
+}
+
+func (r *MongoSessionRepository) ListActive(userID string, now time.Time) ([]models.Session, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []sessionDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	sessions := make([]models.Session, 0, len(docs))
+	for i := range docs {
+		sessions = append(sessions, docs[i].toModel())
+	}
+	return sessions, nil
+}
+
+func (r *MongoSessionRepository) Touch(sessionID string, client models.SessionClient, usedAt, expiresAt time.Time) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+	update := bson.M{"$set": bson.M{
+		"user_agent":   client.UserAgent,
+		"ip_address":   client.IPAddress,
+		"last_used_at": usedAt,
+		"expires_at":   expiresAt,
+	}}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID, "revoked_at": bson.M{"$exists": false}}, update)
+	return err
+}
+
+func (r *MongoSessionRepository) Revoke(sessionID string, revokedAt time.Time) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+	filter := bson.M{"_id": objectID, "revoked_at": bson.M{"$exists": false}}
+	_, err = r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": revokedAt}})
+	return err
+}
+
+func (r *MongoSessionRepository) RevokeAllForUser(userID string, revokedAt time.Time) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": revokedAt}})
+	return err
+}
@@ -45,6 +45,8 @@ func (r *mongoTokenRepository) StoreAccessToken(accessToken *models.AccessToken)
 		"_id":        objectID,
 		"user_id":    accessToken.UserID,
 		"token":      accessToken.Token,
+		"jti":        accessToken.JTI,
+		"family_id":  accessToken.FamilyID,
 		"expires_at": accessToken.ExpiresAt,
 		"created_at": accessToken.CreatedAt,
 		"updated_at": accessToken.UpdatedAt,
@@ -76,6 +78,7 @@ func (r *mongoTokenRepository) StoreRefreshToken(refreshToken *models.RefreshTok
 		"_id":        objectID,
 		"user_id":    refreshToken.UserID,
 		"token":      refreshToken.Token,
+		"family_id":  refreshToken.FamilyID,
 		"expires_at": refreshToken.ExpiresAt,
 		"created_at": refreshToken.CreatedAt,
 		"updated_at": refreshToken.UpdatedAt,
@@ -96,6 +99,7 @@ func (r *mongoTokenRepository) ValidateAccessToken(token string) (bool, error) {
 		"expires_at": bson.M{
 			"$gt": time.Now(),
 		},
+		"revoked_at": bson.M{"$exists": false},
 	}).Decode(&doc)
 
 	if err != nil {
@@ -119,6 +123,7 @@ func (r *mongoTokenRepository) ValidateRefreshToken(token string) (bool, error)
 		"expires_at": bson.M{
 			"$gt": time.Now(),
 		},
+		"rotated_at": bson.M{"$exists": false},
 	}).Decode(&doc)
 
 	if err != nil {
@@ -131,12 +136,12 @@ func (r *mongoTokenRepository) ValidateRefreshToken(token string) (bool, error)
 	return true, nil
 }
 
-//revokes an access token
+//revokes an access token; the record is kept until expiry so other instances can learn of the revocation
 func (r *mongoTokenRepository) RevokeAccessToken(token string) error {
 	ctx, cancel :=database. DefaultTimeout()
 	defer cancel()
 
-	_, err := r.accessTokensCollection.DeleteOne(ctx, bson.M{"token": token})
+	_, err := r.accessTokensCollection.UpdateOne(ctx, bson.M{"token": token, "revoked_at": bson.M{"$exists": false}}, revokeAccessTokenUpdate())
 	return err
 }
 
@@ -153,10 +158,112 @@ func (r *mongoTokenRepository) RevokeRefreshToken(token string) error {
 func (r *mongoTokenRepository) RevokeAllUserTokens(userID string) error {
 	ctx, cancel :=database. DefaultTimeout()
 	defer cancel()
-	_, err := r.accessTokensCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	_, err := r.accessTokensCollection.UpdateMany(ctx, bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}, revokeAccessTokenUpdate())
 	if err != nil {
 		return err
 	}
 	_, err = r.refreshTokensCollection.DeleteMany(ctx, bson.M{"user_id": userID})
 	return err
-} 
\ No newline at end of file
+}
+
+type refreshTokenDocument struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	UserID    string             `bson:"user_id"`
+	Token     string             `bson:"token"`
+	FamilyID  string             `bson:"family_id,omitempty"`
+	RotatedAt *time.Time         `bson:"rotated_at,omitempty"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+func (d *refreshTokenDocument) toModel() *models.RefreshToken {
+	return &models.RefreshToken{
+		ID:        d.ID.Hex(),
+		UserID:    d.UserID,
+		Token:     d.Token,
+		FamilyID:  d.FamilyID,
+		RotatedAt: d.RotatedAt,
+		ExpiresAt: d.ExpiresAt,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+// loads a refresh token record, including rotated ones kept for reuse detection
+func (r *mongoTokenRepository) GetRefreshToken(token string) (*models.RefreshToken, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	var doc refreshTokenDocument
+	if err := r.refreshTokensCollection.FindOne(ctx, bson.M{"token": token}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	return doc.toModel(), nil
+}
+
+// marks a refresh token as used, reporting false if it had already been rotated
+func (r *mongoTokenRepository) MarkRefreshTokenRotated(token string, rotatedAt time.Time) (bool, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{"token": token, "rotated_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"rotated_at": rotatedAt, "updated_at": rotatedAt}}
+	res, err := r.refreshTokensCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// revokes every access and refresh token issued to a token family
+func (r *mongoTokenRepository) RevokeTokenFamily(familyID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{"family_id": familyID, "revoked_at": bson.M{"$exists": false}}
+	if _, err := r.accessTokensCollection.UpdateMany(ctx, filter, revokeAccessTokenUpdate()); err != nil {
+		return err
+	}
+	_, err := r.refreshTokensCollection.DeleteMany(ctx, bson.M{"family_id": familyID})
+	return err
+}
+
+// unexpired access tokens revoked at or after since
+func (r *mongoTokenRepository) ListRevokedAccessTokens(since time.Time) ([]models.RevokedAccessToken, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{
+		"jti":        bson.M{"$exists": true, "$ne": ""},
+		"revoked_at": bson.M{"$gte": since},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	projection := bson.M{"jti": 1, "expires_at": 1, "revoked_at": 1}
+	cursor, err := r.accessTokensCollection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		JTI       string    `bson:"jti"`
+		ExpiresAt time.Time `bson:"expires_at"`
+		RevokedAt time.Time `bson:"revoked_at"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	revoked := make([]models.RevokedAccessToken, 0, len(docs))
+	for _, d := range docs {
+		revoked = append(revoked, models.RevokedAccessToken{JTI: d.JTI, ExpiresAt: d.ExpiresAt, RevokedAt: d.RevokedAt})
+	}
+	return revoked, nil
+}
+
+func revokeAccessTokenUpdate() bson.M {
+	return bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+}
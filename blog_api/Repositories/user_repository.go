@@ -6,11 +6,13 @@ import (
 	"blog_api/Repositories/database"
 	"context"
 	"errors"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type mongoUserRepository struct {
@@ -38,6 +40,7 @@ func (r *mongoUserRepository) CreateUser(user *models.User) error {
 		"last_name":              user.LastName,
 		"email":                  user.Email,
 		"password":               user.Password,
+		"password_changed_at":    user.PasswordChangedAt,
 		"bio":                    user.Bio,
 		"profile_picture":        user.ProfilePicture,
 		"contact_info":           user.ContactInfo,
@@ -174,6 +177,19 @@ func (r *mongoUserRepository) documentToUser(userData bson.M) (*models.User, err
 		user.Password = password
 	}
 
+	if history, ok := userData["password_history"].(primitive.A); ok {
+		for _, entry := range history {
+			if hash, ok := entry.(string); ok {
+				user.PasswordHistory = append(user.PasswordHistory, hash)
+			}
+		}
+	}
+
+	if changedAt, ok := userData["password_changed_at"].(primitive.DateTime); ok {
+		changedTime := changedAt.Time()
+		user.PasswordChangedAt = &changedTime
+	}
+
 	if bio, ok := userData["bio"].(string); ok {
 		user.Bio = bio
 	}
@@ -194,6 +210,30 @@ func (r *mongoUserRepository) documentToUser(userData bson.M) (*models.User, err
 		user.EmailVerified = emailVerified
 	}
 
+	if enabled, ok := userData["two_factor_enabled"].(bool); ok {
+		user.TwoFactorEnabled = enabled
+	}
+
+	if secret, ok := userData["two_factor_secret"].(string); ok {
+		user.TwoFactorSecret = secret
+	}
+
+	if pending, ok := userData["two_factor_pending_secret"].(string); ok {
+		user.TwoFactorPending = pending
+	}
+
+	if codes, ok := userData["recovery_codes"].(primitive.A); ok {
+		for _, code := range codes {
+			if hash, ok := code.(string); ok {
+				user.RecoveryCodes = append(user.RecoveryCodes, hash)
+			}
+		}
+	}
+
+	if step, ok := userData["two_factor_last_step"].(int64); ok {
+		user.TwoFactorLastStep = step
+	}
+
 	if resetPasswordToken, ok := userData["reset_password_token"].(string); ok {
 		user.ResetPasswordToken = resetPasswordToken
 	}
@@ -203,6 +243,21 @@ func (r *mongoUserRepository) documentToUser(userData bson.M) (*models.User, err
 		user.ResetPasswordExpires = &expiresTime
 	}
 
+	if sentAt, ok := userData["verification_sent_at"].(primitive.DateTime); ok {
+		sentTime := sentAt.Time()
+		user.VerificationSentAt = &sentTime
+	}
+
+	if changedAt, ok := userData["email_changed_at"].(primitive.DateTime); ok {
+		changedTime := changedAt.Time()
+		user.EmailChangedAt = &changedTime
+	}
+
+	if deletedAt, ok := userData["deleted_at"].(primitive.DateTime); ok {
+		deletedTime := time.Unix(int64(deletedAt)/1000, 0)
+		user.DeletedAt = &deletedTime
+	}
+
 	if createdAt, ok := userData["created_at"].(primitive.DateTime); ok {
 		user.CreatedAt = time.Unix(int64(createdAt)/1000, 0)
 	}
@@ -232,6 +287,8 @@ func (r *mongoUserRepository) UpdateUser(user *models.User) error {
 		"last_name":              user.LastName,
 		"email":                  user.Email,
 		"password":               user.Password,
+		"password_history":       user.PasswordHistory,
+		"password_changed_at":    user.PasswordChangedAt,
 		"bio":                    user.Bio,
 		"profile_picture":        user.ProfilePicture,
 		"contact_info":           user.ContactInfo,
@@ -263,15 +320,24 @@ func (r *mongoUserRepository) UpdateUserRole(userID, roleID string) error {
 	return err
 }
 
-// returns the number of admin users
-func (r *mongoUserRepository) GetAdminCount() (int, error) {
+// returns the number of users holding a role
+func (r *mongoUserRepository) CountUsersByRole(roleID string) (int, error) {
 	ctx, cancel := database.DefaultTimeout()
 	defer cancel()
 
-	count, err := r.collection.CountDocuments(ctx, bson.M{"role_id": "admin"})
+	count, err := r.collection.CountDocuments(ctx, bson.M{"role_id": bson.M{"$in": roleIDValues(roleID)}})
 	return int(count), err
 }
 
+// role_id is stored as an ObjectID by role updates and as a string at registration
+func roleIDValues(roleID string) bson.A {
+	values := bson.A{roleID}
+	if oid, err := primitive.ObjectIDFromHex(roleID); err == nil {
+		values = append(values, oid)
+	}
+	return values
+}
+
 // retrieves a user by reset token
 func (r *mongoUserRepository) GetUserByResetToken(token string) (*models.User, error) {
 	ctx, cancel := database.DefaultTimeout()
@@ -300,3 +366,284 @@ func (r *mongoUserRepository) UpdateUserProfile(userID string, updateFields map[
 	_, err = r.collection.UpdateOne(context.TODO(), filter, update)
 	return err
 }
+
+// lists users matching the admin filters, newest first
+func (r *mongoUserRepository) ListUsers(query *models.UserQuery) ([]models.User, int, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	filter := bson.M{}
+	if query.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query.Search), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"email": pattern},
+			bson.M{"username": pattern},
+		}
+	}
+	if query.RoleID != "" {
+		filter["role_id"] = bson.M{"$in": roleIDValues(query.RoleID)}
+	}
+	if query.IsActive != nil {
+		filter["is_active"] = *query.IsActive
+	}
+	if !query.IncludeDeleted {
+		filter["deleted_at"] = nil
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((query.Page - 1) * query.PageSize)).
+		SetLimit(int64(query.PageSize))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]models.User, 0, len(docs))
+	for _, doc := range docs {
+		user, err := r.documentToUser(doc)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *user)
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, int(total), nil
+}
+
+// activates or deactivates a user account
+func (r *mongoUserRepository) SetUserActive(userID string, active bool) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+
+	update := bson.M{"$set": bson.M{"is_active": active, "updated_at": time.Now()}}
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// marks a user as deleted while keeping the record
+func (r *mongoUserRepository) SoftDeleteUser(userID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"is_active":  false,
+		"deleted_at": now,
+		"updated_at": now,
+	}}
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// permanently removes a user record
+func (r *mongoUserRepository) DeleteUser(userID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// marks a user's email as verified
+func (r *mongoUserRepository) MarkEmailVerified(userID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+	update := bson.M{"$set": bson.M{"email_verified": true, "updated_at": time.Now()}}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return err
+}
+
+// swaps the user's address, marking it verified and dropping any reset link sent to the old one
+func (r *mongoUserRepository) ChangeEmail(userID, fromEmail, toEmail string, changedAt time.Time) (bool, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, errors.New("invalid user ID")
+	}
+	update := bson.M{"$set": bson.M{
+		"email":                  toEmail,
+		"email_verified":         true,
+		"email_changed_at":       changedAt,
+		"reset_password_token":   "",
+		"reset_password_expires": nil,
+		"updated_at":             changedAt,
+	}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID, "email": fromEmail}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// records when the last verification email was sent, for resend throttling
+func (r *mongoUserRepository) SetVerificationSentAt(userID string, sentAt time.Time) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"verification_sent_at": sentAt}})
+	return err
+}
+
+// stores an unconfirmed TOTP secret while the user finishes enrollment
+func (r *mongoUserRepository) SetTwoFactorPending(userID, secret string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"two_factor_pending_secret": secret}})
+	return err
+}
+
+// turns on two-factor authentication with a confirmed secret and hashed recovery codes
+func (r *mongoUserRepository) EnableTwoFactor(userID, secret string, recoveryCodes []string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"two_factor_enabled": true,
+			"two_factor_secret":  secret,
+			"recovery_codes":     recoveryCodes,
+			"updated_at":         time.Now(),
+		},
+		"$unset": bson.M{"two_factor_pending_secret": ""},
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return err
+}
+
+// turns off two-factor authentication and forgets the secret and recovery codes
+func (r *mongoUserRepository) DisableTwoFactor(userID string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+	update := bson.M{
+		"$set": bson.M{"two_factor_enabled": false, "updated_at": time.Now()},
+		"$unset": bson.M{
+			"two_factor_secret":         "",
+			"two_factor_pending_secret": "",
+			"recovery_codes":            "",
+			"two_factor_last_step":      "",
+		},
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return err
+}
+
+// swaps the user's recovery codes for a freshly generated set
+func (r *mongoUserRepository) ReplaceRecoveryCodes(userID string, recoveryCodes []string) error {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID")
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"recovery_codes": recoveryCodes}})
+	return err
+}
+
+// removes a recovery code hash, reporting false if it was not (or no longer) there
+func (r *mongoUserRepository) ConsumeRecoveryCode(userID, codeHash string) (bool, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, errors.New("invalid user ID")
+	}
+	filter := bson.M{"_id": objectID, "recovery_codes": codeHash}
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"recovery_codes": codeHash}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// records the TOTP time step just used, reporting false if it (or a later one) was already used
+func (r *mongoUserRepository) ClaimTOTPStep(userID string, step int64) (bool, error) {
+	ctx, cancel := database.DefaultTimeout()
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, errors.New("invalid user ID")
+	}
+	filter := bson.M{
+		"_id": objectID,
+		"$or": bson.A{
+			bson.M{"two_factor_last_step": bson.M{"$exists": false}},
+			bson.M{"two_factor_last_step": bson.M{"$lt": step}},
+		},
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"two_factor_last_step": step}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
@@ -2,21 +2,56 @@ package usecases
 
 import (
 	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
 	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
 	"errors"
 )
 
 
 type AdminUseCase struct {
-	userRepo repositories.IUserRepository
-	roleRepo repositories.IRoleRepository
+	userRepo     repositories.IUserRepository
+	roleRepo     repositories.IRoleRepository
+	blogRepo     repositories.IBlogRepository
+	commentRepo  repositories.ICommentRepository
+	oauthRepo    repositories.IOAuthRepository
+	followRepo   repositories.IFollowRepository
+	notificationRepo repositories.INotificationRepository
+	digestRepo   repositories.IDigestRepository
+	reportRepo   repositories.IReportRepository
+	reviewRepo   repositories.IReviewRepository
+	tokenUseCase usecases.ITokenUseCase
+	permissionSvc services.IPermissionService
 }
 
 
-func NewAdminUseCase(userRepo repositories.IUserRepository, roleRepo repositories.IRoleRepository) usecases.IAdminUseCase {
+func NewAdminUseCase(
+	userRepo repositories.IUserRepository,
+	roleRepo repositories.IRoleRepository,
+	blogRepo repositories.IBlogRepository,
+	commentRepo repositories.ICommentRepository,
+	oauthRepo repositories.IOAuthRepository,
+	followRepo repositories.IFollowRepository,
+	notificationRepo repositories.INotificationRepository,
+	digestRepo repositories.IDigestRepository,
+	reportRepo repositories.IReportRepository,
+	reviewRepo repositories.IReviewRepository,
+	tokenUseCase usecases.ITokenUseCase,
+	permissionSvc services.IPermissionService,
+) usecases.IAdminUseCase {
 	return &AdminUseCase{
-		userRepo: userRepo,
-		roleRepo: roleRepo,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		blogRepo:     blogRepo,
+		commentRepo:  commentRepo,
+		oauthRepo:    oauthRepo,
+		followRepo:   followRepo,
+		notificationRepo: notificationRepo,
+		digestRepo:   digestRepo,
+		reportRepo:   reportRepo,
+		reviewRepo:   reviewRepo,
+		tokenUseCase: tokenUseCase,
+		permissionSvc: permissionSvc,
 	}
 }
 
@@ -29,36 +64,27 @@ func (uc *AdminUseCase) PromoteUser(adminID, targetUserID string) error {
 	if err != nil {
 		return errors.New("acting admin not found")
 	}
-    if admin.RoleID != "admin" {
-        adminRole, err := uc.roleRepo.GetRoleByID(admin.RoleID)
-        if err != nil || adminRole.Role != "admin" {
-            return errors.New("only admins can promote users")
-        }
-    }
+	if !uc.canManageUsers(admin) {
+		return errors.New("only admins can promote users")
+	}
 
 	user, err := uc.userRepo.GetUserByID(targetUserID)
 	if err != nil {
 		return errors.New("target user not found")
 	}
-
-    if user.RoleID == "admin" {
-        return errors.New("user is already an admin")
-    }
-    if user.RoleID != "" {
-        if userRole, err := uc.roleRepo.GetRoleByID(user.RoleID); err == nil && userRole.Role == "admin" {
-            return errors.New("user is already an admin")
-        }
-    }
+	if uc.roleName(user) == models.RoleAdmin {
+		return errors.New("user is already an admin")
+	}
 
 	if !user.IsActive {
 		return errors.New("cannot promote inactive user")
 	}
-	adminRoleID, err := uc.roleRepo.GetRoleIDByName("admin")
+	adminRoleID, err := uc.roleRepo.GetRoleIDByName(models.RoleAdmin)
 	if err != nil {
 		return errors.New("admin role not found")
 	}
 
-	return uc.userRepo.UpdateUserRole(targetUserID, adminRoleID)
+	return uc.changeRole(targetUserID, adminRoleID)
 }
 
 // demotes an admin to user 
@@ -71,29 +97,24 @@ func (uc *AdminUseCase) DemoteUser(adminID, targetUserID string) error {
 	if err != nil {
 		return errors.New("acting admin not found")
 	}
-    if admin.RoleID != "admin" {
-        adminRole, err := uc.roleRepo.GetRoleByID(admin.RoleID)
-        if err != nil || adminRole.Role != "admin" {
-            return errors.New("only admins can demote users")
-        }
-    }
+	if !uc.canManageUsers(admin) {
+		return errors.New("only admins can demote users")
+	}
 
 	user, err := uc.userRepo.GetUserByID(targetUserID)
 	if err != nil {
 		return errors.New("target user not found")
 	}
-
-    if user.RoleID == "admin" {
-        // ok
-    } else {
-        userRole, err := uc.roleRepo.GetRoleByID(user.RoleID)
-        if err != nil || userRole.Role != "admin" {
-            return errors.New("user is not an admin")
-        }
-    }
+	if uc.roleName(user) != models.RoleAdmin {
+		return errors.New("user is not an admin")
+	}
 
 	// Prevent demoting the last admin
-	adminCount, err := uc.userRepo.GetAdminCount()
+	adminRoleID, err := uc.roleRepo.GetRoleIDByName(models.RoleAdmin)
+	if err != nil {
+		return errors.New("admin role not found")
+	}
+	adminCount, err := uc.userRepo.CountUsersByRole(adminRoleID)
 	if err != nil {
 		return errors.New("failed to count admins")
 	}
@@ -102,10 +123,205 @@ func (uc *AdminUseCase) DemoteUser(adminID, targetUserID string) error {
 	}
 
 	// Get user role ID for demotion
-	userRoleID, err := uc.roleRepo.GetRoleIDByName("user")
+	userRoleID, err := uc.roleRepo.GetRoleIDByName(models.RoleUser)
 	if err != nil {
 		return errors.New("user role not found")
 	}
 	
-	return uc.userRepo.UpdateUserRole(targetUserID, userRoleID)
+	return uc.changeRole(targetUserID, userRoleID)
+}
+
+// assigns any non-admin role; admin changes go through promote/demote
+func (uc *AdminUseCase) AssignRole(adminID, targetUserID, roleName string) error {
+	if roleName == models.RoleAdmin {
+		return errors.New("use promote to grant the admin role")
+	}
+	if adminID == targetUserID {
+		return errors.New("admin cannot change their own role")
+	}
+
+	admin, err := uc.userRepo.GetUserByID(adminID)
+	if err != nil {
+		return errors.New("acting admin not found")
+	}
+	if !uc.canManageUsers(admin) {
+		return errors.New("only admins can change user roles")
+	}
+
+	user, err := uc.userRepo.GetUserByID(targetUserID)
+	if err != nil {
+		return errors.New("target user not found")
+	}
+	if uc.roleName(user) == models.RoleAdmin {
+		return errors.New("use demote to change an admin's role")
+	}
+	if !user.IsActive {
+		return errors.New("cannot change the role of an inactive user")
+	}
+
+	roleID, err := uc.roleRepo.GetRoleIDByName(roleName)
+	if err != nil {
+		return errors.New("role not found")
+	}
+
+	return uc.changeRole(targetUserID, roleID)
+}
+
+// tokens carry the role, so the user is signed out everywhere to pick up the new one
+func (uc *AdminUseCase) changeRole(userID, roleID string) error {
+	if err := uc.userRepo.UpdateUserRole(userID, roleID); err != nil {
+		return err
+	}
+	return uc.tokenUseCase.RevokeAllUserTokens(userID)
+}
+
+// lists users for the admin console
+func (uc *AdminUseCase) ListUsers(query *models.UserQuery, roleName string) ([]models.User, int, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+	if roleName != "" {
+		roleID, err := uc.roleRepo.GetRoleIDByName(roleName)
+		if err != nil {
+			return nil, 0, errors.New("role not found")
+		}
+		query.RoleID = roleID
+	}
+	return uc.userRepo.ListUsers(query)
+}
+
+// deactivates an account and signs it out everywhere
+func (uc *AdminUseCase) DeactivateUser(adminID, targetUserID string) error {
+	user, err := uc.manageableUser(adminID, targetUserID)
+	if err != nil {
+		return err
+	}
+	if !user.IsActive {
+		return errors.New("user is already deactivated")
+	}
+	if err := uc.userRepo.SetUserActive(targetUserID, false); err != nil {
+		return err
+	}
+	return uc.tokenUseCase.RevokeAllUserTokens(targetUserID)
+}
+
+// reactivates a deactivated account
+func (uc *AdminUseCase) ReactivateUser(adminID, targetUserID string) error {
+	user, err := uc.manageableUser(adminID, targetUserID)
+	if err != nil {
+		return err
+	}
+	if user.DeletedAt != nil {
+		return errors.New("cannot reactivate a deleted user")
+	}
+	if user.IsActive {
+		return errors.New("user is already active")
+	}
+	return uc.userRepo.SetUserActive(targetUserID, true)
+}
+
+// deletes an account; a soft delete hides the user's content, a hard delete removes it
+func (uc *AdminUseCase) DeleteUser(adminID, targetUserID string, hard bool) error {
+	user, err := uc.manageableUser(adminID, targetUserID)
+	if err != nil {
+		return err
+	}
+	if !hard && user.DeletedAt != nil {
+		return errors.New("user is already deleted")
+	}
+
+	if err := uc.tokenUseCase.RevokeAllUserTokens(targetUserID); err != nil {
+		return err
+	}
+
+	if !hard {
+		if err := uc.blogRepo.SetBlogsStatusByAuthor(targetUserID, models.BlogStatusHidden); err != nil {
+			return err
+		}
+		if err := uc.commentRepo.SetCommentsHiddenByUserID(targetUserID, true); err != nil {
+			return err
+		}
+		return uc.userRepo.SoftDeleteUser(targetUserID)
+	}
+
+	blogIDs, err := uc.blogRepo.DeleteBlogsByAuthor(targetUserID)
+	if err != nil {
+		return err
+	}
+	for _, blogID := range blogIDs {
+		if err := uc.commentRepo.DeleteCommentsByBlogID(blogID); err != nil {
+			return err
+		}
+	}
+
+	removed, err := uc.commentRepo.DeleteCommentsByUserID(targetUserID)
+	if err != nil {
+		return err
+	}
+	for blogID, count := range removed {
+		if err := uc.blogRepo.AdjustCommentCount(blogID, -count); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.reviewRepo.DeleteReviewsByAuthorID(targetUserID); err != nil {
+		return err
+	}
+	if err := uc.reportRepo.DeleteReportsByUserID(targetUserID); err != nil {
+		return err
+	}
+	if err := uc.followRepo.DeleteFollowsByUserID(targetUserID); err != nil {
+		return err
+	}
+	if err := uc.notificationRepo.DeleteNotificationsByUserID(targetUserID); err != nil {
+		return err
+	}
+	if err := uc.digestRepo.DeleteSettings(targetUserID); err != nil {
+		return err
+	}
+
+	// sessions and tokens were revoked above and are kept so revoked access tokens stay denied until they expire
+	if err := uc.oauthRepo.DeleteOAuthUsersByUserID(targetUserID); err != nil {
+		return err
+	}
+	return uc.userRepo.DeleteUser(targetUserID)
+}
+
+// loads a target user that the acting admin is allowed to manage
+func (uc *AdminUseCase) manageableUser(adminID, targetUserID string) (*models.User, error) {
+	if adminID == targetUserID {
+		return nil, errors.New("admin cannot manage their own account")
+	}
+	admin, err := uc.userRepo.GetUserByID(adminID)
+	if err != nil {
+		return nil, errors.New("acting admin not found")
+	}
+	if !uc.canManageUsers(admin) {
+		return nil, errors.New("only admins can manage users")
+	}
+
+	user, err := uc.userRepo.GetUserByID(targetUserID)
+	if err != nil {
+		return nil, errors.New("target user not found")
+	}
+	if uc.roleName(user) == models.RoleAdmin {
+		return nil, errors.New("demote the admin before managing their account")
+	}
+	return user, nil
+}
+
+// resolves a user's role name; legacy accounts store the name itself
+func (uc *AdminUseCase) roleName(user *models.User) string {
+	if role, err := uc.roleRepo.GetRoleByID(user.RoleID); err == nil {
+		return role.Role
+	}
+	return user.RoleID
+}
+
+func (uc *AdminUseCase) canManageUsers(user *models.User) bool {
+	granted, err := uc.permissionSvc.HasPermission(uc.roleName(user), models.PermUserManage)
+	return err == nil && granted
 }
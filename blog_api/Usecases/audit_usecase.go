@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/models"
+	"time"
+)
+
+// upper bound on entries returned by a single export
+const maxAuditExport = 10000
+
+type AuditUseCase struct {
+	auditRepo repositories.IAuditRepository
+	userRepo  repositories.IUserRepository
+	roleRepo  repositories.IRoleRepository
+}
+
+func NewAuditUseCase(
+	auditRepo repositories.IAuditRepository,
+	userRepo repositories.IUserRepository,
+	roleRepo repositories.IRoleRepository,
+) *AuditUseCase {
+	return &AuditUseCase{
+		auditRepo: auditRepo,
+		userRepo:  userRepo,
+		roleRepo:  roleRepo,
+	}
+}
+
+// appends an entry to the audit log
+func (uc *AuditUseCase) Record(entry *models.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	return uc.auditRepo.CreateEntry(entry)
+}
+
+// captures the security-relevant state of a user; nil if the user is gone
+func (uc *AuditUseCase) SnapshotUser(userID string) map[string]interface{} {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil
+	}
+
+	role := user.RoleID
+	if r, err := uc.roleRepo.GetRoleByID(user.RoleID); err == nil {
+		role = r.Role
+	}
+	return map[string]interface{}{
+		"role":      role,
+		"is_active": user.IsActive,
+		"deleted":   user.DeletedAt != nil,
+	}
+}
+
+// captures a role and its permissions; nil if the role is gone
+func (uc *AuditUseCase) SnapshotRole(roleID string) map[string]interface{} {
+	role, err := uc.roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"name":        role.Role,
+		"description": role.Description,
+		"permissions": role.Permissions,
+	}
+}
+
+// returns a page of audit entries, newest first
+func (uc *AuditUseCase) QueryEntries(query *models.AuditQuery) ([]models.AuditEntry, int, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+	return uc.auditRepo.ListEntries(query)
+}
+
+// returns every matching entry up to the export limit
+func (uc *AuditUseCase) ExportEntries(query *models.AuditQuery) ([]models.AuditEntry, error) {
+	query.Page = 1
+	query.PageSize = maxAuditExport
+	entries, _, err := uc.auditRepo.ListEntries(query)
+	return entries, err
+}
@@ -2,21 +2,58 @@ package usecases
 
 import (
 	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
 	"blog_api/Domain/models"
 	"errors"
+	"log"
 	"strings"
 	"time"
 )
 
+// configurable rules for publishing content
+type ContentPolicy struct {
+	// new posts go to the review queue instead of being published directly
+	RequireReview bool
+	// users must verify their email before posting or commenting
+	RequireVerifiedEmail bool
+}
+
+// enforces the verified-email rule for a user about to publish content
+func (p ContentPolicy) checkAuthor(userRepo repositories.IUserRepository, userID string) error {
+	if !p.RequireVerifiedEmail {
+		return nil
+	}
+	user, err := userRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.EmailVerified {
+		return usecases.ErrEmailNotVerified
+	}
+	return nil
+}
+
 type BlogUseCase struct {
-	BlogRepo repositories.IBlogRepository
-	UserRepo repositories.IUserRepository
+	BlogRepo      repositories.IBlogRepository
+	UserRepo      repositories.IUserRepository
+	ReviewRepo    repositories.IReviewRepository
+	Policy        ContentPolicy
+	PermSvc       services.IPermissionService
+	Notifications usecases.INotificationUseCase
+	Events        services.IEventPublisher
 }
 
-func NewBlogUseCase(blogRepo repositories.IBlogRepository,userRepo repositories.IUserRepository) *BlogUseCase {
+func NewBlogUseCase(blogRepo repositories.IBlogRepository, userRepo repositories.IUserRepository, reviewRepo repositories.IReviewRepository, policy ContentPolicy, permSvc services.IPermissionService, notifications usecases.INotificationUseCase, events services.IEventPublisher) *BlogUseCase {
 	return &BlogUseCase{
-		BlogRepo: blogRepo,
-	    UserRepo: userRepo,}
+		BlogRepo:      blogRepo,
+		UserRepo:      userRepo,
+		ReviewRepo:    reviewRepo,
+		Policy:        policy,
+		PermSvc:       permSvc,
+		Notifications: notifications,
+		Events:        events,
+	}
 }
 
 func (uc *BlogUseCase) CreateBlog(blog *models.Blog, AuthorID string) error {
@@ -24,40 +61,57 @@ func (uc *BlogUseCase) CreateBlog(blog *models.Blog, AuthorID string) error {
 	if blog.Title == "" || blog.Content == "" || blog.AuthorID == "" {
 		return errors.New("Please include all required fields")
 	}
+	if err := uc.Policy.checkAuthor(uc.UserRepo, AuthorID); err != nil {
+		return err
+	}
 	blog.AuthorID = AuthorID
 	blog.CreatedAt = time.Now()
 	blog.UpdatedAt = time.Now()
 	blog.LikeCount = 0
 	blog.DislikeCount = 0
+	blog.Status = models.BlogStatusPublished
+	if uc.Policy.RequireReview {
+		blog.Status = models.BlogStatusPendingReview
+	}
 
 	err := uc.BlogRepo.CreateBlog(blog)
 
 	if err != nil {
 		return err
 	}
+
+	if blog.Status == models.BlogStatusPendingReview {
+		return uc.ReviewRepo.CreateReview(&models.BlogReview{
+			BlogID:    blog.ID,
+			AuthorID:  blog.AuthorID,
+			ActorID:   blog.AuthorID,
+			Action:    models.ReviewActionSubmit,
+			ToStatus:  models.BlogStatusPendingReview,
+			CreatedAt: time.Now(),
+		})
+	}
 	return nil
 
 }
 
-func (uc *BlogUseCase) GetBlogs(query *models.BlogQuery)([]models.Blog, int,error){
+func (uc *BlogUseCase) GetBlogs(query *models.BlogQuery) ([]models.Blog, int, error) {
 
-	
-	if query.Page <= 0{
+	if query.Page <= 0 {
 		query.Page = 1
 	}
-	if query.PageSize <= 0{
+	if query.PageSize <= 0 {
 		query.PageSize = 10
 	}
-	if query.SortBy == ""{
+	if query.SortBy == "" {
 		query.SortBy = "recent"
 
 	}
-	blog,total,err := uc.BlogRepo.GetBlogs(query)
-	if err != nil{
-		return nil , 0,err
+	blog, total, err := uc.BlogRepo.GetBlogs(query)
+	if err != nil {
+		return nil, 0, err
 	}
-	 
-	return blog,total,err
+
+	return blog, total, err
 }
 
 func (uc *BlogUseCase) UpdateBlog(input *models.Blog, blogID string, authorID string) (*models.Blog, error) {
@@ -80,6 +134,15 @@ func (uc *BlogUseCase) UpdateBlog(input *models.Blog, blogID string, authorID st
 		return nil, errors.New("blog content must not be empty")
 	}
 
+	// an approved post that is edited has to go through review again; it leaves the public listings
+	// before the new text is saved, and the edit is dropped if a concurrent change got there first
+	if uc.Policy.RequireReview && blog.IsPublished() {
+		if err := transition(uc.BlogRepo, uc.ReviewRepo, &blog, authorID, models.ReviewActionSubmit, models.BlogStatusPendingReview, ""); err != nil {
+			return nil, err
+		}
+		blog.Status = models.BlogStatusPendingReview
+	}
+
 	blog.Title = input.Title
 	blog.Content = input.Content
 	blog.Tags = input.Tags
@@ -92,7 +155,7 @@ func (uc *BlogUseCase) UpdateBlog(input *models.Blog, blogID string, authorID st
 	return updatedBlog, nil
 }
 
-func (uc *BlogUseCase) DeleteBlog(blogID string, authorID string) error {
+func (uc *BlogUseCase) DeleteBlog(blogID string, authorID string, role string) error {
 	if strings.TrimSpace(blogID) == "" {
 		return errors.New("invalid blog ID provided")
 	}
@@ -103,7 +166,10 @@ func (uc *BlogUseCase) DeleteBlog(blogID string, authorID string) error {
 	}
 
 	if blog.AuthorID != authorID {
-		return errors.New("unauthorized access: you are not permitted to delete this blog")
+		canDelete, err := uc.PermSvc.HasPermission(role, models.PermBlogDeleteAny)
+		if err != nil || !canDelete {
+			return errors.New("unauthorized access: you are not permitted to delete this blog")
+		}
 	}
 
 	err = uc.BlogRepo.DeleteBlog(blogID)
@@ -114,7 +180,7 @@ func (uc *BlogUseCase) DeleteBlog(blogID string, authorID string) error {
 	return nil
 }
 
-func (uc *BlogUseCase) SearchBlogs(searchQuery *models.BlogQuery)(*[]models.Blog,error) {
+func (uc *BlogUseCase) SearchBlogs(searchQuery *models.BlogQuery) (*[]models.Blog, error) {
 	var authorID string
 	if searchQuery.Author != "" {
 		user, err := uc.UserRepo.GetUserByUsername(searchQuery.Author)
@@ -122,10 +188,10 @@ func (uc *BlogUseCase) SearchBlogs(searchQuery *models.BlogQuery)(*[]models.Blog
 			return nil, err
 		}
 		authorID = user.ID
-	
+
 	}
 
-	blogs, err := uc.BlogRepo.SearchBlogs(searchQuery.Title,authorID)
+	blogs, err := uc.BlogRepo.SearchBlogs(searchQuery.Title, authorID)
 	if err != nil {
 		return nil, err
 	}
@@ -133,47 +199,90 @@ func (uc *BlogUseCase) SearchBlogs(searchQuery *models.BlogQuery)(*[]models.Blog
 }
 
 func (uc *BlogUseCase) LikeBlog(userID, blogID string) error {
-   
-    liked, err := uc.BlogRepo.HasUserInteraction(userID, blogID, "like")
-    if err != nil {
-        return err
-    }
-    if liked {
-        return errors.New("user has already liked this blog")
-    }
-    err = uc.BlogRepo.AddUserInteraction(userID, blogID, "like")
-    if err != nil {
-        return err
-    }
-
-    err = uc.BlogRepo.IncrementLike(blogID)
-    if err != nil {
-        if rbErr := uc.BlogRepo.RemoveUserInteraction(userID, blogID, "like"); rbErr != nil {
-        }
-        return err
-    }
-    return nil
+
+	liked, err := uc.BlogRepo.HasUserInteraction(userID, blogID, "like")
+	if err != nil {
+		return err
+	}
+	if liked {
+		return errors.New("user has already liked this blog")
+	}
+	err = uc.BlogRepo.AddUserInteraction(userID, blogID, "like")
+	if err != nil {
+		return err
+	}
+
+	err = uc.BlogRepo.IncrementLike(blogID)
+	if err != nil {
+		if rbErr := uc.BlogRepo.RemoveUserInteraction(userID, blogID, "like"); rbErr != nil {
+		}
+		return err
+	}
+	publishBlogCounters(uc.Events, uc.BlogRepo, blogID)
+	uc.notifyAuthor(blogID, userID, models.NotificationLike)
+	return nil
 }
 
-func (uc *BlogUseCase) DislikeBlog(userID, blogID string) error{
-	dislike,err := uc.BlogRepo.HasUserInteraction(userID,blogID,"dislike")
-	if err != nil{
+func (uc *BlogUseCase) DislikeBlog(userID, blogID string) error {
+	dislike, err := uc.BlogRepo.HasUserInteraction(userID, blogID, "dislike")
+	if err != nil {
 		return err
 	}
-	if dislike{
+	if dislike {
 		return errors.New("user has already disliked this blog")
 
 	}
-	err = uc.BlogRepo.AddUserInteraction(userID,blogID,"dislike")
-	if err != nil{
+	err = uc.BlogRepo.AddUserInteraction(userID, blogID, "dislike")
+	if err != nil {
 		return err
 	}
 	err = uc.BlogRepo.IncrementDislike(blogID)
 	if err != nil {
-        if rbErr := uc.BlogRepo.RemoveUserInteraction(userID, blogID, "like"); rbErr != nil {
-        }
-        return err
-    }
-    return nil
-	
-}
\ No newline at end of file
+		if rbErr := uc.BlogRepo.RemoveUserInteraction(userID, blogID, "like"); rbErr != nil {
+		}
+		return err
+	}
+	publishBlogCounters(uc.Events, uc.BlogRepo, blogID)
+	return nil
+
+}
+
+// counts a share once per user and lets the author know
+func (uc *BlogUseCase) ShareBlog(userID, blogID string) error {
+	if _, err := uc.BlogRepo.GetBlogByID(blogID); err != nil {
+		return usecases.ErrBlogNotFound
+	}
+	shared, err := uc.BlogRepo.HasUserInteraction(userID, blogID, "share")
+	if err != nil {
+		return err
+	}
+	if shared {
+		return nil
+	}
+	if err := uc.BlogRepo.AddUserInteraction(userID, blogID, "share"); err != nil {
+		return err
+	}
+	if err := uc.BlogRepo.IncrementShare(blogID); err != nil {
+		uc.BlogRepo.RemoveUserInteraction(userID, blogID, "share")
+		return err
+	}
+	publishBlogCounters(uc.Events, uc.BlogRepo, blogID)
+	uc.notifyAuthor(blogID, userID, models.NotificationShare)
+	return nil
+}
+
+// notifies the blog's author about a reader's interaction; failures never fail the request
+func (uc *BlogUseCase) notifyAuthor(blogID, actorID, notificationType string) {
+	blog, err := uc.BlogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return
+	}
+	if err := uc.Notifications.Notify(&models.Notification{
+		UserID:  blog.AuthorID,
+		ActorID: actorID,
+		Type:    notificationType,
+		BlogID:  blogID,
+	}); err != nil {
+		log.Printf("failed to send %s notification for blog %s: %v", notificationType, blogID, err)
+	}
+}
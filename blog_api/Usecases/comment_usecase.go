@@ -2,31 +2,67 @@ package usecases
 
 import (
 	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
 	"errors"
+	"log"
+	"time"
 )
 
 type CommentUseCase struct {
 	commentRepo repositories.ICommentRepository
 	blogRepo  repositories.IBlogRepository
+	permSvc   services.IPermissionService
+	userRepo  repositories.IUserRepository
+	policy    ContentPolicy
+	notifications usecases.INotificationUseCase
+	events    services.IEventPublisher
 }
 
-func NewCommentUseCases( comRepo repositories.ICommentRepository,blogRepo repositories.IBlogRepository) *CommentUseCase{
+func NewCommentUseCases( comRepo repositories.ICommentRepository,blogRepo repositories.IBlogRepository, permSvc services.IPermissionService, userRepo repositories.IUserRepository, policy ContentPolicy, notifications usecases.INotificationUseCase, events services.IEventPublisher) *CommentUseCase{
 	return &CommentUseCase{
 		commentRepo: comRepo,
 		blogRepo: blogRepo,
+		permSvc: permSvc,
+		userRepo: userRepo,
+		policy: policy,
+		notifications: notifications,
+		events: events,
 	}
 }
 
-func (uc *CommentUseCase) CreateComment(blogID,userID,content string) error{
+// creates a comment, or a reply when parentID is set, and notifies the people involved
+func (uc *CommentUseCase) CreateComment(blogID,userID,content,parentID string) error{
 	if blogID == "" || userID == "" {
         return errors.New("blogID and userID are required")
     }
     if content == "" {
         return errors.New("content cannot be empty")
     }
+	if err := uc.policy.checkAuthor(uc.userRepo, userID); err != nil {
+		return err
+	}
 
 
-	err := uc.commentRepo.CreateComment(blogID,userID,content)
+	var parent models.Comment
+	if parentID != "" {
+		p, err := uc.commentRepo.GetCommentByID(parentID)
+		if err != nil || p.BlogID != blogID || p.Hidden {
+			return usecases.ErrParentCommentNotFound
+		}
+		parent = p
+	}
+
+	comment := &models.Comment{
+		BlogID:    blogID,
+		UserID:    userID,
+		ParentID:  parentID,
+		Content:   content,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	err := uc.commentRepo.CreateComment(comment)
 	if err != nil{
 		return err
 	}
@@ -34,17 +70,55 @@ func (uc *CommentUseCase) CreateComment(blogID,userID,content string) error{
 	if err != nil{
 		return err
 	}
+
+	uc.events.Publish(models.BlogTopic(blogID), models.EventCommentCreated, commentEvent{
+		ID:        comment.ID,
+		BlogID:    blogID,
+		UserID:    userID,
+		ParentID:  parentID,
+		Content:   content,
+		CreatedAt: comment.CreatedAt,
+	})
+	publishBlogCounters(uc.events, uc.blogRepo, blogID)
+	if parent.UserID != "" {
+		uc.notify(parent.UserID, userID, models.NotificationReply, comment)
+	}
+	if blog, err := uc.blogRepo.GetBlogByID(blogID); err == nil && blog.AuthorID != parent.UserID {
+		uc.notify(blog.AuthorID, userID, models.NotificationComment, comment)
+	}
 	return nil
 
 }
 
-func (uc *CommentUseCase) UpdateComment(commentID string, content string) error {
+// failures are logged so a notification problem never loses the comment
+func (uc *CommentUseCase) notify(recipientID, actorID, notificationType string, comment *models.Comment) {
+	if err := uc.notifications.Notify(&models.Notification{
+		UserID:    recipientID,
+		ActorID:   actorID,
+		Type:      notificationType,
+		BlogID:    comment.BlogID,
+		CommentID: comment.ID,
+	}); err != nil {
+		log.Printf("failed to send %s notification for comment %s: %v", notificationType, comment.ID, err)
+	}
+}
+
+func (uc *CommentUseCase) UpdateComment(commentID, userID, content string) error {
 	// Check if the comment exists
 	err := uc.commentRepo.CheckCommentExist(commentID)
 	if err != nil {
 		return err
 	}
 
+	comment, err := uc.commentRepo.GetCommentByID(commentID)
+	if err != nil {
+		return errors.New("comment not found")
+	}
+	// only the author may edit a comment; moderators can only remove it
+	if comment.UserID != userID {
+		return usecases.ErrNotCommentAuthor
+	}
+
 	// Attempt to update the comment
 	err = uc.commentRepo.UpdateComment(commentID, content)
 	if err != nil {
@@ -54,7 +128,7 @@ func (uc *CommentUseCase) UpdateComment(commentID string, content string) error
 	return nil
 }
 
-func (uc *CommentUseCase) DeleteComment(commentID string) error {
+func (uc *CommentUseCase) DeleteComment(commentID, userID, role string) error {
     if commentID == "" {
         return errors.New("commentID cannot be empty")
     }
@@ -67,6 +141,12 @@ func (uc *CommentUseCase) DeleteComment(commentID string) error {
 	if err != nil{
 		return errors.New("comment not found")
 	}
+	if comment.UserID != userID {
+		canModerate, err := uc.permSvc.HasPermission(role, models.PermCommentModerate)
+		if err != nil || !canModerate {
+			return usecases.ErrNotCommentAuthor
+		}
+	}
 
 	blogID := comment.BlogID
 	err = uc.commentRepo.DeleteComment(commentID)
@@ -77,5 +157,6 @@ func (uc *CommentUseCase) DeleteComment(commentID string) error {
 	if err != nil{
 		return err
 	}
+	publishBlogCounters(uc.events, uc.blogRepo, blogID)
 	return nil
 }
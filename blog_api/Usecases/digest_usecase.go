@@ -0,0 +1,211 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"log"
+	"time"
+)
+
+// how digests are compiled and scheduled
+type DigestConfig struct {
+	// subscriptions handled per database round trip
+	BatchSize int
+	// posts per section
+	FollowedLimit int
+	TrendingLimit int
+	// a digest counts as due this long before its full period has passed, so an hourly job does not drift
+	Slack time.Duration
+}
+
+// unsubscribe links keep working for a year
+const digestUnsubscribeTTL = 365 * 24 * time.Hour
+
+type DigestUseCase struct {
+	digestRepo   repositories.IDigestRepository
+	followRepo   repositories.IFollowRepository
+	blogRepo     repositories.IBlogRepository
+	userRepo     repositories.IUserRepository
+	emailSvc     services.IEmailService
+	actionTokens services.IActionTokenService
+	config       DigestConfig
+}
+
+func NewDigestUseCase(digestRepo repositories.IDigestRepository, followRepo repositories.IFollowRepository, blogRepo repositories.IBlogRepository, userRepo repositories.IUserRepository, emailSvc services.IEmailService, actionTokens services.IActionTokenService, config DigestConfig) *DigestUseCase {
+	return &DigestUseCase{
+		digestRepo:   digestRepo,
+		followRepo:   followRepo,
+		blogRepo:     blogRepo,
+		userRepo:     userRepo,
+		emailSvc:     emailSvc,
+		actionTokens: actionTokens,
+		config:       config,
+	}
+}
+
+func (uc *DigestUseCase) GetSettings(userID string) (*models.DigestSettings, error) {
+	return uc.digestRepo.GetSettings(userID)
+}
+
+func (uc *DigestUseCase) UpdateFrequency(userID, frequency string) (*models.DigestSettings, error) {
+	if !models.IsValidDigestFrequency(frequency) {
+		return nil, usecases.ErrInvalidDigestFrequency
+	}
+	if err := uc.digestRepo.SaveFrequency(userID, frequency); err != nil {
+		return nil, err
+	}
+	return uc.digestRepo.GetSettings(userID)
+}
+
+func (uc *DigestUseCase) Unsubscribe(token string) error {
+	action, err := uc.actionTokens.Verify(models.ActionDigestUnsubscribe, token)
+	if err != nil {
+		return usecases.ErrInvalidUnsubscribeToken
+	}
+	return uc.digestRepo.SaveFrequency(action.Subject, models.DigestOff)
+}
+
+func (uc *DigestUseCase) PreviewDigest(userID string) (*models.Digest, error) {
+	settings, err := uc.digestRepo.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	frequency := settings.Frequency
+	if frequency == models.DigestOff {
+		frequency = models.DigestWeekly
+	}
+	now := time.Now()
+	return uc.buildDigest(userID, frequency, now.Add(-models.DigestPeriod(frequency)))
+}
+
+func (uc *DigestUseCase) SendDueDigests(now time.Time) (int, error) {
+	sent := 0
+	for _, frequency := range []string{models.DigestDaily, models.DigestWeekly} {
+		period := models.DigestPeriod(frequency)
+		cutoff := now.Add(-period + uc.config.Slack)
+		for {
+			due, err := uc.digestRepo.ListDue(frequency, cutoff, uc.config.BatchSize)
+			if err != nil {
+				return sent, err
+			}
+			for i := range due {
+				ok, err := uc.sendDigest(&due[i], now)
+				if err != nil {
+					return sent, err
+				}
+				if ok {
+					sent++
+				}
+			}
+			if len(due) < uc.config.BatchSize {
+				break
+			}
+		}
+	}
+	return sent, nil
+}
+
+// claims and sends one user's digest; only claim failures are returned so one bad address never stops the run
+func (uc *DigestUseCase) sendDigest(settings *models.DigestSettings, now time.Time) (bool, error) {
+	claimed, err := uc.digestRepo.ClaimRun(settings.UserID, settings.LastSentAt, now)
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		return false, nil
+	}
+
+	user, err := uc.userRepo.GetUserByID(settings.UserID)
+	if err != nil || !user.IsActive || user.DeletedAt != nil || !user.EmailVerified {
+		return false, nil
+	}
+
+	period := models.DigestPeriod(settings.Frequency)
+	since := now.Add(-period)
+	// pick up where the last digest stopped unless it was long ago
+	if settings.LastSentAt != nil && settings.LastSentAt.After(now.Add(-2*period)) {
+		since = *settings.LastSentAt
+	}
+	digest, err := uc.buildDigest(user.ID, settings.Frequency, since)
+	if err != nil {
+		log.Printf("digest: failed to build digest for %s: %v", user.ID, err)
+		return false, nil
+	}
+	if digest.IsEmpty() {
+		return false, nil
+	}
+
+	token, err := uc.actionTokens.Issue(models.ActionDigestUnsubscribe, user.ID, nil, digestUnsubscribeTTL)
+	if err != nil {
+		log.Printf("digest: failed to issue unsubscribe token for %s: %v", user.ID, err)
+		return false, nil
+	}
+	if err := uc.emailSvc.SendDigestEmail(user.Email, digest, token); err != nil {
+		log.Printf("digest: failed to send digest to %s: %v", user.ID, err)
+		return false, nil
+	}
+	return true, nil
+}
+
+// collects top new posts from followed authors and tags, then trending posts the reader has not seen in the first list
+func (uc *DigestUseCase) buildDigest(userID, frequency string, since time.Time) (*models.Digest, error) {
+	digest := &models.Digest{Frequency: frequency, Since: since}
+
+	authorIDs, err := uc.followRepo.ListFollowingIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := uc.followRepo.ListFollowedTags(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	authorNames := map[string]string{}
+	if len(authorIDs) > 0 || len(tags) > 0 {
+		followed, err := uc.blogRepo.GetTopBlogsSince(since, authorIDs, tags, uc.config.FollowedLimit+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, blog := range followed {
+			if blog.AuthorID == userID || len(digest.FollowedPosts) >= uc.config.FollowedLimit {
+				continue
+			}
+			seen[blog.ID] = true
+			digest.FollowedPosts = append(digest.FollowedPosts, uc.digestItem(blog, authorNames))
+		}
+	}
+
+	trending, err := uc.blogRepo.GetTopBlogsSince(since, nil, nil, uc.config.TrendingLimit+len(seen)+1)
+	if err != nil {
+		return nil, err
+	}
+	for _, blog := range trending {
+		if seen[blog.ID] || blog.AuthorID == userID || len(digest.TrendingPosts) >= uc.config.TrendingLimit {
+			continue
+		}
+		digest.TrendingPosts = append(digest.TrendingPosts, uc.digestItem(blog, authorNames))
+	}
+	return digest, nil
+}
+
+func (uc *DigestUseCase) digestItem(blog models.Blog, authorNames map[string]string) models.DigestItem {
+	name, ok := authorNames[blog.AuthorID]
+	if !ok {
+		name = "an unknown author"
+		if author, err := uc.userRepo.GetUserByID(blog.AuthorID); err == nil {
+			name = author.Username
+		}
+		authorNames[blog.AuthorID] = name
+	}
+	return models.DigestItem{
+		BlogID:       blog.ID,
+		Title:        blog.Title,
+		AuthorName:   name,
+		Tags:         blog.Tags,
+		LikeCount:    blog.LikeCount,
+		CommentCount: blog.CommentCount,
+	}
+}
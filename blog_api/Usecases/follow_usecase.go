@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+type FollowUseCase struct {
+	followRepo    repositories.IFollowRepository
+	userRepo      repositories.IUserRepository
+	notifications usecases.INotificationUseCase
+}
+
+func NewFollowUseCase(followRepo repositories.IFollowRepository, userRepo repositories.IUserRepository, notifications usecases.INotificationUseCase) *FollowUseCase {
+	return &FollowUseCase{
+		followRepo:    followRepo,
+		userRepo:      userRepo,
+		notifications: notifications,
+	}
+}
+
+func (uc *FollowUseCase) FollowUser(followerID, followeeID string) error {
+	if followerID == followeeID {
+		return usecases.ErrCannotFollowSelf
+	}
+	followee, err := uc.userRepo.GetUserByID(followeeID)
+	if err != nil || followee.DeletedAt != nil || !followee.IsActive {
+		return usecases.ErrUserNotFound
+	}
+
+	created, err := uc.followRepo.Follow(&models.Follow{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		return usecases.ErrAlreadyFollowing
+	}
+
+	if err := uc.notifications.Notify(&models.Notification{
+		UserID:  followeeID,
+		ActorID: followerID,
+		Type:    models.NotificationFollow,
+	}); err != nil {
+		log.Printf("failed to notify %s about new follower: %v", followeeID, err)
+	}
+	return nil
+}
+
+func (uc *FollowUseCase) UnfollowUser(followerID, followeeID string) error {
+	err := uc.followRepo.Unfollow(followerID, followeeID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return usecases.ErrNotFollowing
+	}
+	return err
+}
+
+func (uc *FollowUseCase) GetFollowStats(userID string) (int, int, error) {
+	if _, err := uc.userRepo.GetUserByID(userID); err != nil {
+		return 0, 0, usecases.ErrUserNotFound
+	}
+	followers, err := uc.followRepo.CountFollowers(userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	following, err := uc.followRepo.CountFollowing(userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return followers, following, nil
+}
+
+func (uc *FollowUseCase) FollowTag(userID, tag string) error {
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	created, err := uc.followRepo.FollowTag(userID, tag)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return usecases.ErrAlreadyFollowingTag
+	}
+	return nil
+}
+
+func (uc *FollowUseCase) UnfollowTag(userID, tag string) error {
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	err = uc.followRepo.UnfollowTag(userID, tag)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return usecases.ErrNotFollowingTag
+	}
+	return err
+}
+
+func (uc *FollowUseCase) ListFollowedTags(userID string) ([]string, error) {
+	return uc.followRepo.ListFollowedTags(userID)
+}
+
+// tags are matched exactly as authors write them, minus surrounding spaces
+func normalizeTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || len(tag) > 50 {
+		return "", usecases.ErrInvalidTag
+	}
+	return tag, nil
+}
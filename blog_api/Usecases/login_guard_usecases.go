@@ -0,0 +1,191 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// thresholds for the login guard
+type LoginGuardConfig struct {
+	// failures on one account before it is locked
+	AccountLimit int
+	// failures from one IP address, across accounts, before it is locked
+	IPLimit int
+	// failures older than this are forgotten
+	Window time.Duration
+	// how long a lock lasts
+	LockoutDuration time.Duration
+	// wait after the first failure on an account, doubled by each further failure up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+type LoginGuardUseCase struct {
+	attemptRepo repositories.ILoginAttemptRepository
+	userRepo    repositories.IUserRepository
+	emailSvc    services.IEmailService
+	config      LoginGuardConfig
+}
+
+func NewLoginGuardUseCase(
+	attemptRepo repositories.ILoginAttemptRepository,
+	userRepo repositories.IUserRepository,
+	emailSvc services.IEmailService,
+	config LoginGuardConfig,
+) *LoginGuardUseCase {
+	return &LoginGuardUseCase{
+		attemptRepo: attemptRepo,
+		userRepo:    userRepo,
+		emailSvc:    emailSvc,
+		config:      config,
+	}
+}
+
+var guardScopes = []string{models.GuardScopeLogin, models.GuardScopeForgotPassword, models.GuardScopeResetPassword, models.GuardScopeReauthenticate}
+
+func (uc *LoginGuardUseCase) Check(scope, identifier, ip string) (time.Duration, error) {
+	now := time.Now()
+	var wait time.Duration
+
+	if identifier != "" {
+		accountKey, _ := uc.accountKey(scope, identifier)
+		t, err := uc.attemptRepo.Get(accountKey)
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			return 0, err
+		}
+		if t != nil {
+			wait = maxDuration(wait, uc.lockWait(t, now))
+			if now.Sub(t.LastFailureAt) < uc.config.Window {
+				wait = maxDuration(wait, t.LastFailureAt.Add(uc.delayAfter(t.Failures)).Sub(now))
+			}
+		}
+	}
+	if ip != "" {
+		t, err := uc.attemptRepo.Get(ipKey(scope, ip))
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			return 0, err
+		}
+		if t != nil {
+			wait = maxDuration(wait, uc.lockWait(t, now))
+		}
+	}
+
+	if wait > 0 {
+		return wait, usecases.ErrTooManyAttempts
+	}
+	return 0, nil
+}
+
+func (uc *LoginGuardUseCase) RecordFailure(scope, identifier, ip string) (bool, error) {
+	now := time.Now()
+	locked := false
+
+	if identifier != "" {
+		accountKey, user := uc.accountKey(scope, identifier)
+		t, err := uc.attemptRepo.RecordFailure(accountKey, now, uc.config.Window)
+		if err != nil {
+			return false, err
+		}
+		if uc.config.AccountLimit > 0 && t.Failures >= uc.config.AccountLimit && uc.lockWait(t, now) == 0 {
+			until := now.Add(uc.config.LockoutDuration)
+			if err := uc.attemptRepo.Lock(accountKey, until); err != nil {
+				return false, err
+			}
+			locked = true
+			// only real accounts hear about it, and only about sign-in; the reset flows just slow down
+			if user != nil && scope == models.GuardScopeLogin {
+				if err := uc.emailSvc.SendAccountLockedEmail(user.Email, until); err != nil {
+					log.Printf("failed to send lockout email to %s: %v", user.ID, err)
+				}
+			}
+		}
+	}
+
+	if ip != "" {
+		key := ipKey(scope, ip)
+		t, err := uc.attemptRepo.RecordFailure(key, now, uc.config.Window)
+		if err != nil {
+			return locked, err
+		}
+		if uc.config.IPLimit > 0 && t.Failures >= uc.config.IPLimit && uc.lockWait(t, now) == 0 {
+			if err := uc.attemptRepo.Lock(key, now.Add(uc.config.LockoutDuration)); err != nil {
+				return locked, err
+			}
+		}
+	}
+	return locked, nil
+}
+
+func (uc *LoginGuardUseCase) RecordSuccess(scope, identifier string) error {
+	if identifier == "" {
+		return nil
+	}
+	key, _ := uc.accountKey(scope, identifier)
+	return uc.attemptRepo.Delete(key)
+}
+
+func (uc *LoginGuardUseCase) Unlock(userID string) error {
+	if _, err := uc.userRepo.GetUserByID(userID); err != nil {
+		return usecases.ErrUserNotFound
+	}
+	keys := make([]string, 0, len(guardScopes))
+	for _, scope := range guardScopes {
+		keys = append(keys, scope+":account:"+userID)
+	}
+	return uc.attemptRepo.Delete(keys...)
+}
+
+// failures on an email address and on the matching username count together; unknown names are tracked
+// as typed so guessing at them is throttled just the same
+func (uc *LoginGuardUseCase) accountKey(scope, identifier string) (string, *models.User) {
+	if scope == models.GuardScopeReauthenticate {
+		return scope + ":account:" + identifier, nil
+	}
+	user, err := uc.userRepo.GetUserByEmail(identifier)
+	if err != nil {
+		user, err = uc.userRepo.GetUserByUsername(identifier)
+	}
+	if err == nil && user != nil {
+		return scope + ":account:" + user.ID, user
+	}
+	return scope + ":name:" + strings.ToLower(strings.TrimSpace(identifier)), nil
+}
+
+func ipKey(scope, ip string) string {
+	return scope + ":ip:" + ip
+}
+
+func (uc *LoginGuardUseCase) lockWait(t *models.LoginThrottle, now time.Time) time.Duration {
+	if t.LockedUntil == nil || !t.LockedUntil.After(now) {
+		return 0
+	}
+	return t.LockedUntil.Sub(now)
+}
+
+// BaseDelay, 2×BaseDelay, 4×BaseDelay… capped at MaxDelay
+func (uc *LoginGuardUseCase) delayAfter(failures int) time.Duration {
+	if failures <= 0 || uc.config.BaseDelay <= 0 {
+		return 0
+	}
+	delay := uc.config.BaseDelay
+	for i := 1; i < failures && delay < uc.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if uc.config.MaxDelay > 0 && delay > uc.config.MaxDelay {
+		delay = uc.config.MaxDelay
+	}
+	return delay
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,186 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// settings for password-less sign-in
+type MagicLinkConfig struct {
+	// how long an emailed link stays valid
+	TTL time.Duration
+	// whether a link sent to an unknown address creates an account
+	AllowSignup bool
+	// links sent to one address per hour; further requests are dropped silently
+	MaxPerHour int
+}
+
+type MagicLinkUseCase struct {
+	userRepo  repositories.IUserRepository
+	linkRepo  repositories.IMagicLinkRepository
+	roleRepo  repositories.IRoleRepository
+	validator services.IValidationService
+	emailSvc  services.IEmailService
+	config    MagicLinkConfig
+}
+
+func NewMagicLinkUseCase(
+	userRepo repositories.IUserRepository,
+	linkRepo repositories.IMagicLinkRepository,
+	roleRepo repositories.IRoleRepository,
+	validator services.IValidationService,
+	emailSvc services.IEmailService,
+	config MagicLinkConfig,
+) *MagicLinkUseCase {
+	return &MagicLinkUseCase{
+		userRepo:  userRepo,
+		linkRepo:  linkRepo,
+		roleRepo:  roleRepo,
+		validator: validator,
+		emailSvc:  emailSvc,
+		config:    config,
+	}
+}
+
+// only an invalid address is reported; unknown, deactivated and throttled addresses get the same answer as valid ones,
+// and so do lookup and delivery failures, which only registered addresses could otherwise run into
+func (uc *MagicLinkUseCase) RequestLink(email string) error {
+	email = strings.TrimSpace(email)
+	if err := uc.validator.ValidateEmail(email); err != nil {
+		return err
+	}
+	if err := uc.sendLink(email); err != nil {
+		log.Printf("magic link for %s not sent: %v", emailLogID(email), err)
+	}
+	return nil
+}
+
+func (uc *MagicLinkUseCase) sendLink(email string) error {
+	user, err := uc.findUser(email)
+	if err != nil {
+		return err
+	}
+	if user == nil && !uc.config.AllowSignup {
+		return nil
+	}
+	if user != nil && !user.IsActive {
+		return nil
+	}
+
+	now := time.Now()
+	sent, err := uc.linkRepo.CountSince(email, now.Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if uc.config.MaxPerHour > 0 && sent >= int64(uc.config.MaxPerHour) {
+		log.Printf("magic link for %s not sent: %d links in the last hour", emailLogID(email), sent)
+		return nil
+	}
+
+	token, err := randomURLToken(32)
+	if err != nil {
+		return err
+	}
+	link := &models.MagicLink{
+		Email:     email,
+		TokenHash: hashMagicLinkToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(uc.config.TTL),
+	}
+	if err := uc.linkRepo.Create(link); err != nil {
+		return err
+	}
+	return uc.emailSvc.SendMagicLinkEmail(email, token, uc.config.TTL)
+}
+
+func (uc *MagicLinkUseCase) ConsumeLink(token string) (*models.MagicLinkLogin, error) {
+	if token == "" {
+		return nil, usecases.ErrInvalidMagicLink
+	}
+	link, err := uc.linkRepo.Consume(hashMagicLinkToken(token), time.Now())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, usecases.ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+
+	user, err := uc.findUser(link.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		// sign-up may have been switched off after the link was sent
+		if !uc.config.AllowSignup {
+			return nil, usecases.ErrInvalidMagicLink
+		}
+		user, err = uc.createUser(link.Email)
+		if err != nil {
+			return nil, err
+		}
+		return &models.MagicLinkLogin{User: user, IsNewUser: true}, nil
+	}
+
+	if !user.IsActive {
+		return nil, usecases.ErrAccountDeactivated
+	}
+	// following the link proves the address belongs to the user
+	if !user.EmailVerified {
+		if err := uc.userRepo.MarkEmailVerified(user.ID); err != nil {
+			return nil, err
+		}
+		user.EmailVerified = true
+	}
+	return &models.MagicLinkLogin{User: user}, nil
+}
+
+// nil without an error when no account uses the address
+func (uc *MagicLinkUseCase) findUser(email string) (*models.User, error) {
+	exists, err := uc.userRepo.CheckEmailExists(email)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return uc.userRepo.GetUserByEmail(email)
+}
+
+// a password-less account with a verified email, like one created through OAuth
+func (uc *MagicLinkUseCase) createUser(email string) (*models.User, error) {
+	user := &models.User{
+		Email:         email,
+		EmailVerified: true,
+		Username:      email,
+		IsActive:      true,
+		RoleID:        "user",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if uc.roleRepo != nil {
+		if roleID, err := uc.roleRepo.GetRoleIDByName("user"); err == nil && roleID != "" {
+			user.RoleID = roleID
+		}
+	}
+	if err := uc.userRepo.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// links are looked up by hash so a database leak does not hand out sign-ins
+// a short stable stand-in for an address in logs, so they do not collect who asked to sign in
+func emailLogID(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return "email:" + hex.EncodeToString(sum[:6])
+}
+
+func hashMagicLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type NotificationUseCase struct {
+	notificationRepo repositories.INotificationRepository
+	userRepo         repositories.IUserRepository
+	blogRepo         repositories.IBlogRepository
+	events           services.IEventPublisher
+}
+
+func NewNotificationUseCase(notificationRepo repositories.INotificationRepository, userRepo repositories.IUserRepository, blogRepo repositories.IBlogRepository, events services.IEventPublisher) *NotificationUseCase {
+	return &NotificationUseCase{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		blogRepo:         blogRepo,
+		events:           events,
+	}
+}
+
+// stores a notification unless the actor is the recipient or the recipient opted out of the type
+func (uc *NotificationUseCase) Notify(notification *models.Notification) error {
+	if notification.UserID == "" || notification.UserID == notification.ActorID {
+		return nil
+	}
+	prefs, err := uc.notificationRepo.GetPreferences(notification.UserID)
+	if err != nil {
+		return err
+	}
+	if !prefs.Enabled(notification.Type) {
+		return nil
+	}
+
+	if notification.Message == "" {
+		notification.Message = uc.describe(notification)
+	}
+	notification.CreatedAt = time.Now()
+	if err := uc.notificationRepo.CreateNotification(notification); err != nil {
+		return err
+	}
+
+	unread, _ := uc.notificationRepo.CountUnread(notification.UserID)
+	uc.events.Publish(models.UserTopic(notification.UserID), models.EventNotificationCreated, notificationEvent{
+		ID:          notification.ID,
+		Type:        notification.Type,
+		ActorID:     notification.ActorID,
+		BlogID:      notification.BlogID,
+		CommentID:   notification.CommentID,
+		Message:     notification.Message,
+		CreatedAt:   notification.CreatedAt,
+		UnreadCount: unread,
+	})
+	return nil
+}
+
+// lists a user's notifications along with the total and unread counts
+func (uc *NotificationUseCase) ListNotifications(userID string, query *models.NotificationQuery) ([]models.Notification, int, int, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+	notifications, total, err := uc.notificationRepo.ListNotifications(userID, query)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	unread, err := uc.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+func (uc *NotificationUseCase) UnreadCount(userID string) (int, error) {
+	return uc.notificationRepo.CountUnread(userID)
+}
+
+func (uc *NotificationUseCase) MarkRead(userID, notificationID string) error {
+	err := uc.notificationRepo.MarkRead(userID, notificationID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return usecases.ErrNotificationNotFound
+	}
+	return err
+}
+
+func (uc *NotificationUseCase) MarkAllRead(userID string) (int, error) {
+	return uc.notificationRepo.MarkAllRead(userID)
+}
+
+func (uc *NotificationUseCase) GetPreferences(userID string) (*models.NotificationPreferences, error) {
+	return uc.notificationRepo.GetPreferences(userID)
+}
+
+// merges the given per-type settings into the user's preferences
+func (uc *NotificationUseCase) UpdatePreferences(userID string, types map[string]bool) (*models.NotificationPreferences, error) {
+	for t := range types {
+		if !models.IsValidNotificationType(t) {
+			return nil, usecases.ErrInvalidNotificationType
+		}
+	}
+	prefs, err := uc.notificationRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	for t, enabled := range types {
+		prefs.Types[t] = enabled
+	}
+	prefs.UpdatedAt = time.Now()
+	if err := uc.notificationRepo.SavePreferences(prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// builds the human readable message, e.g. `alice commented on "My post"`
+func (uc *NotificationUseCase) describe(notification *models.Notification) string {
+	actor := "Someone"
+	if user, err := uc.userRepo.GetUserByID(notification.ActorID); err == nil && user.Username != "" {
+		actor = user.Username
+	}
+	title := "your post"
+	if notification.BlogID != "" {
+		if blog, err := uc.blogRepo.GetBlogByID(notification.BlogID); err == nil && blog.Title != "" {
+			title = fmt.Sprintf("%q", blog.Title)
+		}
+	}
+
+	switch notification.Type {
+	case models.NotificationComment:
+		return fmt.Sprintf("%s commented on %s", actor, title)
+	case models.NotificationReply:
+		return fmt.Sprintf("%s replied to your comment on %s", actor, title)
+	case models.NotificationLike:
+		return fmt.Sprintf("%s liked %s", actor, title)
+	case models.NotificationShare:
+		return fmt.Sprintf("%s shared %s", actor, title)
+	case models.NotificationFollow:
+		return fmt.Sprintf("%s started following you", actor)
+	default:
+		return fmt.Sprintf("New activity from %s", actor)
+	}
+}
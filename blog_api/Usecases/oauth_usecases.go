@@ -5,17 +5,35 @@ import (
 	"blog_api/Domain/contracts/services"
 	"blog_api/Domain/contracts/usecases"
 	"blog_api/Domain/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 )
 
+// how long a user has to finish signing in at the provider
+const oauthFlowTTL = 10 * time.Minute
+
+// settings for the browser side of the OAuth flow
+type OAuthFlowConfig struct {
+	// origins such as https://app.example.com a login may return to; relative paths are always allowed
+	AllowedRedirectOrigins []string
+}
+
 type OAuthUseCase struct {
 	userRepo      repositories.IUserRepository
 	oauthRepo     repositories.IOAuthRepository
 	oauthServices map[string]services.IOAuthService
 	tokenUseCase  usecases.ITokenUseCase
 	roleRepo      repositories.IRoleRepository
+	twoFactor     usecases.ITwoFactorUseCase
+	actionTokens  services.IActionTokenService
+	flow          OAuthFlowConfig
 }
 
 func NewOAuthUseCase(
@@ -24,6 +42,9 @@ func NewOAuthUseCase(
 	oauthServices map[string]services.IOAuthService,
 	tokenUseCase usecases.ITokenUseCase,
 	roleRepo repositories.IRoleRepository,
+	twoFactor usecases.ITwoFactorUseCase,
+	actionTokens services.IActionTokenService,
+	flow OAuthFlowConfig,
 ) *OAuthUseCase {
 	return &OAuthUseCase{
 		userRepo:      userRepo,
@@ -31,37 +52,118 @@ func NewOAuthUseCase(
 		oauthServices: oauthServices,
 		tokenUseCase:  tokenUseCase,
 		roleRepo:      roleRepo,
+		twoFactor:     twoFactor,
+		actionTokens:  actionTokens,
+		flow:          flow,
 	}
 }
 
-// InitiateOAuthFlow starts the OAuth flow for a specific provider
-func (uc *OAuthUseCase) InitiateOAuthFlow(provider string) (string, error) {
+// InitiateOAuthFlow starts the OAuth flow for a specific provider.
+// The returned flow token binds the random state, the PKCE verifier and the redirect URL to the browser.
+func (uc *OAuthUseCase) InitiateOAuthFlow(provider, redirectURL string) (*models.OAuthAuthorization, error) {
 	oauthService, exists := uc.oauthServices[provider]
 	if !exists {
-		return "", fmt.Errorf("unsupported OAuth provider: %s", provider)
+		return nil, fmt.Errorf("unsupported OAuth provider: %s", provider)
+	}
+	if !uc.redirectAllowed(redirectURL) {
+		return nil, usecases.ErrInvalidRedirectURL
 	}
 
-	authURL, err := oauthService.GetAuthURL("")
+	state, err := randomURLToken(32)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate auth URL: %v", err)
+		return nil, err
 	}
+	var verifier, challenge string
+	if oauthService.SupportsPKCE() {
+		if verifier, err = randomURLToken(32); err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256([]byte(verifier))
+		challenge = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	authURL, err := oauthService.GetAuthURL(state, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate auth URL: %v", err)
+	}
+	data := map[string]string{"state": state, "verifier": verifier, "redirect": redirectURL}
+	flowToken, err := uc.actionTokens.Issue(models.ActionOAuthFlow, provider, data, oauthFlowTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OAuthAuthorization{
+		AuthURL:   authURL,
+		FlowToken: flowToken,
+		ExpiresAt: time.Now().Add(oauthFlowTTL),
+	}, nil
+}
+
+// checks that the callback belongs to a flow this browser started for this provider
+func (uc *OAuthUseCase) verifyFlow(provider, state, flowToken string) (*models.OAuthFlow, error) {
+	if state == "" || flowToken == "" {
+		return nil, usecases.ErrInvalidOAuthState
+	}
+	claims, err := uc.actionTokens.Verify(models.ActionOAuthFlow, flowToken)
+	if err != nil || claims.Subject != provider {
+		return nil, usecases.ErrInvalidOAuthState
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Data["state"]), []byte(state)) != 1 {
+		return nil, usecases.ErrInvalidOAuthState
+	}
+	return &models.OAuthFlow{
+		Provider:     provider,
+		State:        state,
+		CodeVerifier: claims.Data["verifier"],
+		RedirectURL:  claims.Data["redirect"],
+	}, nil
+}
 
-	return authURL, nil
+// accepts relative paths on this host and absolute URLs on a configured origin
+func (uc *OAuthUseCase) redirectAllowed(redirectURL string) bool {
+	if redirectURL == "" {
+		return true
+	}
+	// browsers treat a backslash like a slash, so "/\evil.com" would leave the site
+	if strings.ContainsAny(redirectURL, "\\\r\n\t") {
+		return false
+	}
+	u, err := url.Parse(redirectURL)
+	if err != nil || u.User != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return strings.HasPrefix(redirectURL, "/") && !strings.HasPrefix(redirectURL, "//")
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return false
+	}
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+	for _, allowed := range uc.flow.AllowedRedirectOrigins {
+		if origin == strings.ToLower(strings.TrimSuffix(allowed, "/")) {
+			return true
+		}
+	}
+	return false
 }
 
 // HandleOAuthCallback processes the OAuth callback and creates/links user
-func (uc *OAuthUseCase) HandleOAuthCallback(provider, code, state string) (*models.OAuthLoginResult, error) {
+func (uc *OAuthUseCase) HandleOAuthCallback(provider, code, state, flowToken string, client models.SessionClient) (*models.OAuthLoginResult, error) {
 	oauthService, exists := uc.oauthServices[provider]
 	if !exists {
 		return nil, fmt.Errorf("unsupported OAuth provider: %s", provider)
 	}
+	flow, err := uc.verifyFlow(provider, state, flowToken)
+	if err != nil {
+		return nil, err
+	}
 
-	token, err := oauthService.ExchangeCodeForToken(code)
+	token, err := oauthService.ExchangeCodeForToken(code, flow.CodeVerifier)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code for token: %v", err)
 	}
 
-	userInfo, err := oauthService.GetUserInfo(token.AccessToken)
+	userInfo, err := oauthService.GetUserInfo(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %v", err)
 	}
@@ -79,21 +181,39 @@ func (uc *OAuthUseCase) HandleOAuthCallback(provider, code, state string) (*mode
 		if err != nil {
 			return nil, fmt.Errorf("failed to get linked user: %v", err)
 		}
+		if !canSignIn(user) {
+			return nil, usecases.ErrAccountDeactivated
+		}
 
-		existingOAuthUser.AccessToken = token.AccessToken
-		existingOAuthUser.RefreshToken = token.RefreshToken
+		applyProviderToken(existingOAuthUser, token)
 		existingOAuthUser.UpdatedAt = time.Now()
 
 		if err := uc.oauthRepo.UpdateOAuthUser(existingOAuthUser); err != nil {
 			return nil, fmt.Errorf("failed to update OAuth user: %v", err)
 		}
 	} else {
-		existingUser, err := uc.userRepo.GetUserByEmail(userInfo.Email)
-		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
-			return nil, fmt.Errorf("failed to check existing user: %v", err)
+		var existingUser *models.User
+		if userInfo.Email != "" {
+			exists, err := uc.userRepo.CheckEmailExists(userInfo.Email)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check existing user: %v", err)
+			}
+			if exists {
+				if existingUser, err = uc.userRepo.GetUserByEmail(userInfo.Email); err != nil {
+					return nil, fmt.Errorf("failed to check existing user: %v", err)
+				}
+			}
 		}
 
 		if existingUser != nil {
+			// Anyone can open a provider account under someone else's address, and anyone can register
+			// one here; linking by email is only safe when both sides have proven they own it.
+			if !userInfo.EmailVerified || !existingUser.EmailVerified {
+				return nil, usecases.ErrOAuthAccountExists
+			}
+			if !canSignIn(existingUser) {
+				return nil, usecases.ErrAccountDeactivated
+			}
 			user = existingUser
 			isNewUser = false
 
@@ -106,7 +226,7 @@ func (uc *OAuthUseCase) HandleOAuthCallback(provider, code, state string) (*mode
 				AccessToken:  token.AccessToken,
 				RefreshToken: token.RefreshToken,
 				UserID:       user.ID,
-				ExpiresAt:    nil,
+				ExpiresAt:    providerTokenExpiry(token),
 				CreatedAt:    time.Now(),
 				UpdatedAt:    time.Now(),
 			}
@@ -116,12 +236,13 @@ func (uc *OAuthUseCase) HandleOAuthCallback(provider, code, state string) (*mode
 			}
 		} else {
 			user = &models.User{
-				Email:     userInfo.Email,
-				FirstName: userInfo.Name,
-				Username:  userInfo.Email,
-				IsActive:  true,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
+				Email:         userInfo.Email,
+				EmailVerified: userInfo.EmailVerified && userInfo.Email != "",
+				FirstName:     userInfo.Name,
+				Username:      userInfo.Email,
+				IsActive:      true,
+				CreatedAt:     time.Now(),
+				UpdatedAt:     time.Now(),
 			}
 
 			if uc.roleRepo != nil {
@@ -147,7 +268,7 @@ func (uc *OAuthUseCase) HandleOAuthCallback(provider, code, state string) (*mode
 				AccessToken:  token.AccessToken,
 				RefreshToken: token.RefreshToken,
 				UserID:       user.ID,
-				ExpiresAt:    nil,
+				ExpiresAt:    providerTokenExpiry(token),
 				CreatedAt:    time.Now(),
 				UpdatedAt:    time.Now(),
 			}
@@ -160,7 +281,16 @@ func (uc *OAuthUseCase) HandleOAuthCallback(provider, code, state string) (*mode
 		}
 	}
 
-	accessTokenModel, refreshTokenModel, err := uc.tokenUseCase.GenerateAndStoreTokens(user.ID, user.RoleID)
+	// the provider proves the first factor only, 2FA still applies
+	challenge, err := uc.twoFactor.BeginLogin(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start two-factor login: %v", err)
+	}
+	if challenge != nil {
+		return &models.OAuthLoginResult{User: user, IsNewUser: isNewUser, TwoFactor: challenge, RedirectURL: flow.RedirectURL}, nil
+	}
+
+	accessTokenModel, refreshTokenModel, err := uc.tokenUseCase.GenerateAndStoreTokens(user.ID, user.RoleID, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %v", err)
 	}
@@ -170,22 +300,32 @@ func (uc *OAuthUseCase) HandleOAuthCallback(provider, code, state string) (*mode
 		AccessToken:  accessTokenModel.Token,
 		RefreshToken: refreshTokenModel.Token,
 		IsNewUser:    isNewUser,
+		RedirectURL:  flow.RedirectURL,
 	}, nil
 }
 
+// deactivated, suspended and deleted accounts cannot sign in with any method
+func canSignIn(user *models.User) bool {
+	return user.IsActive && user.DeletedAt == nil
+}
+
 // LinkOAuthToExistingUser links an OAuth account to an existing user
-func (uc *OAuthUseCase) LinkOAuthToExistingUser(provider, code, userID string) error {
+func (uc *OAuthUseCase) LinkOAuthToExistingUser(provider, code, state, flowToken, userID string) error {
 	oauthService, exists := uc.oauthServices[provider]
 	if !exists {
 		return fmt.Errorf("unsupported OAuth provider: %s", provider)
 	}
+	flow, err := uc.verifyFlow(provider, state, flowToken)
+	if err != nil {
+		return err
+	}
 
-	token, err := oauthService.ExchangeCodeForToken(code)
+	token, err := oauthService.ExchangeCodeForToken(code, flow.CodeVerifier)
 	if err != nil {
 		return fmt.Errorf("failed to exchange code for token: %v", err)
 	}
 
-	userInfo, err := oauthService.GetUserInfo(token.AccessToken)
+	userInfo, err := oauthService.GetUserInfo(token)
 	if err != nil {
 		return fmt.Errorf("failed to get user info: %v", err)
 	}
@@ -208,9 +348,123 @@ func (uc *OAuthUseCase) LinkOAuthToExistingUser(provider, code, userID string) e
 		AccessToken:  token.AccessToken,
 		RefreshToken: token.RefreshToken,
 		UserID:       userID,
+		ExpiresAt:    providerTokenExpiry(token),
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
 	return uc.oauthRepo.CreateOAuthUser(oauthUser)
 }
+
+// the provider accounts a user can sign in with, and whether a password is set as well
+func (uc *OAuthUseCase) ListLinkedAccounts(userID string) (*models.LinkedAccounts, error) {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, usecases.ErrUserNotFound
+	}
+	accounts, err := uc.oauthRepo.ListOAuthUsersByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LinkedAccounts{Accounts: accounts, HasPassword: user.Password != ""}, nil
+}
+
+// removes the user's links to a provider unless that would leave no way to sign in
+func (uc *OAuthUseCase) UnlinkProvider(userID, provider string) error {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return usecases.ErrUserNotFound
+	}
+	accounts, err := uc.oauthRepo.ListOAuthUsersByUserID(userID)
+	if err != nil {
+		return err
+	}
+	found, remaining := false, 0
+	for _, account := range accounts {
+		if account.Provider == provider {
+			found = true
+		} else {
+			remaining++
+		}
+	}
+	if !found {
+		return usecases.ErrOAuthLinkNotFound
+	}
+	if user.Password == "" && remaining == 0 {
+		return usecases.ErrLastSignInMethod
+	}
+	_, err = uc.oauthRepo.DeleteOAuthUsersByProvider(userID, provider)
+	return err
+}
+
+// how many links a single refresh pass renews; the rest wait for the next pass
+const oauthRefreshBatchSize = 100
+
+// renews provider access tokens that expire before the given time, returning how many were renewed.
+// Links whose grant the provider revoked lose their tokens so they are not retried; other failures
+// are marked so the next pass tries the remaining links first.
+func (uc *OAuthUseCase) RefreshExpiringTokens(before time.Time) (int, error) {
+	expiring, err := uc.oauthRepo.ListOAuthUsersExpiringBefore(before, oauthRefreshBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	var firstErr error
+	for i := range expiring {
+		link := &expiring[i]
+		service, ok := uc.oauthServices[link.Provider]
+		if !ok {
+			continue
+		}
+		token, err := service.RefreshToken(link.RefreshToken)
+		switch {
+		case errors.Is(err, services.ErrProviderGrantRevoked):
+			link.AccessToken, link.RefreshToken, link.ExpiresAt = "", "", nil
+		case errors.Is(err, services.ErrRefreshNotSupported):
+			link.RefreshToken = ""
+		case err != nil:
+			if firstErr == nil {
+				firstErr = fmt.Errorf("refreshing %s token for user %s: %w", link.Provider, link.UserID, err)
+			}
+			if err := uc.oauthRepo.MarkRefreshFailed(link.ID, time.Now()); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			continue
+		default:
+			applyProviderToken(link, token)
+			refreshed++
+		}
+		if err := uc.oauthRepo.UpdateOAuthUser(link); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return refreshed, firstErr
+}
+
+// stores a fresh provider token; a refresh that returns no new refresh token keeps the old one
+func applyProviderToken(link *models.OAuthUser, token *models.OAuthToken) {
+	link.AccessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		link.RefreshToken = token.RefreshToken
+	}
+	link.ExpiresAt = providerTokenExpiry(token)
+}
+
+// nil when the provider did not say when the token expires
+func providerTokenExpiry(token *models.OAuthToken) *time.Time {
+	if token.ExpiresIn <= 0 {
+		return nil
+	}
+	expiresAt := time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return &expiresAt
+}
+
+// n random bytes, base64url encoded; also a valid PKCE verifier for n=32
+func randomURLToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
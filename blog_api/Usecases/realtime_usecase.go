@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"time"
+)
+
+// how many blogs one stream may follow
+const maxStreamBlogs = 20
+
+type RealtimeUseCase struct {
+	blogRepo repositories.IBlogRepository
+}
+
+func NewRealtimeUseCase(blogRepo repositories.IBlogRepository) *RealtimeUseCase {
+	return &RealtimeUseCase{blogRepo: blogRepo}
+}
+
+// a reader may follow published blogs; authors may also follow their own drafts
+func (uc *RealtimeUseCase) StreamTopics(userID, role string, blogIDs []string) ([]string, error) {
+	if len(blogIDs) > maxStreamBlogs {
+		return nil, usecases.ErrTooManyStreamTopics
+	}
+	topics := []string{models.UserTopic(userID)}
+	seen := map[string]bool{}
+	for _, blogID := range blogIDs {
+		if blogID == "" || seen[blogID] {
+			continue
+		}
+		seen[blogID] = true
+		blog, err := uc.blogRepo.GetBlogByID(blogID)
+		if err != nil {
+			return nil, usecases.ErrBlogNotFound
+		}
+		visible := blog.Status == "" || blog.Status == models.BlogStatusPublished
+		if !visible && blog.AuthorID != userID {
+			return nil, usecases.ErrBlogNotFound
+		}
+		topics = append(topics, models.BlogTopic(blogID))
+	}
+	return topics, nil
+}
+
+// payload of a comment.created event
+type commentEvent struct {
+	ID        string    `json:"id"`
+	BlogID    string    `json:"blog_id"`
+	UserID    string    `json:"user_id"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// payload of a blog.counters event
+type blogCountersEvent struct {
+	BlogID       string `json:"blog_id"`
+	LikeCount    int    `json:"like_count"`
+	DislikeCount int    `json:"dislike_count"`
+	CommentCount int    `json:"comment_count"`
+	ShareCount   int    `json:"share_count"`
+}
+
+// payload of a notification.created event
+type notificationEvent struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	ActorID     string    `json:"actor_id"`
+	BlogID      string    `json:"blog_id,omitempty"`
+	CommentID   string    `json:"comment_id,omitempty"`
+	Message     string    `json:"message"`
+	CreatedAt   time.Time `json:"created_at"`
+	UnreadCount int       `json:"unread_count"`
+}
+
+// sends the blog's current counters to everyone watching it
+func publishBlogCounters(events services.IEventPublisher, blogRepo repositories.IBlogRepository, blogID string) {
+	blog, err := blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return
+	}
+	events.Publish(models.BlogTopic(blogID), models.EventBlogCounters, blogCountersEvent{
+		BlogID:       blogID,
+		LikeCount:    blog.LikeCount,
+		DislikeCount: blog.DislikeCount,
+		CommentCount: blog.CommentCount,
+		ShareCount:   blog.ShareCount,
+	})
+}
@@ -0,0 +1,326 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+const maxReportDetailsLength = 1000
+
+// configurable rules for reader reports
+type ReportPolicy struct {
+	// blogs and comments are hidden automatically once an open case reaches this many reports; 0 disables
+	AutoHideThreshold int
+}
+
+type ReportUseCase struct {
+	reportRepo   repositories.IReportRepository
+	blogRepo     repositories.IBlogRepository
+	commentRepo  repositories.ICommentRepository
+	userRepo     repositories.IUserRepository
+	roleRepo     repositories.IRoleRepository
+	tokenUseCase usecases.ITokenUseCase
+	emailSvc     services.IEmailService
+	permSvc      services.IPermissionService
+	policy       ReportPolicy
+}
+
+func NewReportUseCase(
+	reportRepo repositories.IReportRepository,
+	blogRepo repositories.IBlogRepository,
+	commentRepo repositories.ICommentRepository,
+	userRepo repositories.IUserRepository,
+	roleRepo repositories.IRoleRepository,
+	tokenUseCase usecases.ITokenUseCase,
+	emailSvc services.IEmailService,
+	permSvc services.IPermissionService,
+	policy ReportPolicy,
+) *ReportUseCase {
+	return &ReportUseCase{
+		reportRepo:   reportRepo,
+		blogRepo:     blogRepo,
+		commentRepo:  commentRepo,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		tokenUseCase: tokenUseCase,
+		emailSvc:     emailSvc,
+		permSvc:      permSvc,
+		policy:       policy,
+	}
+}
+
+// files a report, folding it into the target's open case
+func (uc *ReportUseCase) ReportContent(reporterID, targetType, targetID, reason, details string) (*models.ContentReport, error) {
+	if !models.IsValidReportReason(reason) {
+		return nil, usecases.ErrInvalidReportReason
+	}
+	details = strings.TrimSpace(details)
+	if reason == models.ReportReasonOther && details == "" {
+		return nil, usecases.ErrReportDetailsRequired
+	}
+	if len(details) > maxReportDetailsLength {
+		details = details[:maxReportDetailsLength]
+	}
+
+	ownerID, err := uc.targetOwner(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID == reporterID {
+		return nil, usecases.ErrCannotReportOwnContent
+	}
+
+	already, err := uc.reportRepo.HasOpenReportFrom(targetType, targetID, reporterID)
+	if err != nil {
+		return nil, err
+	}
+	if already {
+		return nil, usecases.ErrAlreadyReported
+	}
+
+	report, err := uc.reportRepo.AddReport(targetType, targetID, ownerID, models.ReportEntry{
+		ReporterID: reporterID,
+		Reason:     reason,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.policy.AutoHideThreshold > 0 && report.ReportCount >= uc.policy.AutoHideThreshold && !report.AutoHidden {
+		if err := uc.autoHide(report); err != nil {
+			log.Printf("failed to auto-hide reported %s %s: %v", targetType, targetID, err)
+		}
+	}
+	return report, nil
+}
+
+// returns moderation cases, the most reported first
+func (uc *ReportUseCase) GetModerationQueue(status, targetType string, page, pageSize int) ([]models.ContentReport, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	return uc.reportRepo.ListReports(status, targetType, page, pageSize)
+}
+
+func (uc *ReportUseCase) GetReport(reportID string) (*models.ContentReport, error) {
+	report, err := uc.reportRepo.GetReportByID(reportID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, usecases.ErrReportNotFound
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+// applies a moderator decision and closes the case
+func (uc *ReportUseCase) ResolveReport(reportID, moderatorID, action, note string) (*models.ContentReport, error) {
+	note = strings.TrimSpace(note)
+	report, err := uc.GetReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, usecases.ErrReportClosed
+	}
+	if !moderationActionAllowed(report.TargetType, action) {
+		return nil, usecases.ErrInvalidModerationAction
+	}
+
+	status := models.ReportStatusActioned
+	switch action {
+	case models.ModerationDismiss:
+		status = models.ReportStatusDismissed
+		if report.AutoHidden {
+			if err := uc.setHidden(report, false); err != nil {
+				return nil, err
+			}
+		}
+	case models.ModerationHide:
+		if err := uc.setHidden(report, true); err != nil {
+			return nil, err
+		}
+	case models.ModerationDelete:
+		if err := uc.deleteTarget(report); err != nil {
+			return nil, err
+		}
+	case models.ModerationWarn:
+		if note == "" {
+			return nil, usecases.ErrModerationNoteRequired
+		}
+	case models.ModerationSuspend:
+		if err := uc.suspendOwner(report.TargetOwnerID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.reportRepo.ResolveReport(reportID, status, action, note, moderatorID, time.Now()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, usecases.ErrReportClosed
+		}
+		return nil, err
+	}
+	if action != models.ModerationDismiss {
+		uc.notifyOwner(report, action, note)
+	}
+	return uc.GetReport(reportID)
+}
+
+// resolves who owns the reported content
+func (uc *ReportUseCase) targetOwner(targetType, targetID string) (string, error) {
+	switch targetType {
+	case models.ReportTargetBlog:
+		blog, err := uc.blogRepo.GetBlogByID(targetID)
+		if err != nil {
+			return "", usecases.ErrReportTargetNotFound
+		}
+		return blog.AuthorID, nil
+	case models.ReportTargetComment:
+		comment, err := uc.commentRepo.GetCommentByID(targetID)
+		if err != nil {
+			return "", usecases.ErrReportTargetNotFound
+		}
+		return comment.UserID, nil
+	case models.ReportTargetUser:
+		user, err := uc.userRepo.GetUserByID(targetID)
+		if err != nil || user.DeletedAt != nil {
+			return "", usecases.ErrReportTargetNotFound
+		}
+		return user.ID, nil
+	default:
+		return "", usecases.ErrInvalidReportTarget
+	}
+}
+
+func moderationActionAllowed(targetType, action string) bool {
+	switch action {
+	case models.ModerationDismiss, models.ModerationWarn, models.ModerationSuspend:
+		return true
+	case models.ModerationHide, models.ModerationDelete:
+		return targetType == models.ReportTargetBlog || targetType == models.ReportTargetComment
+	default:
+		return false
+	}
+}
+
+// hides reported content once the threshold is crossed
+func (uc *ReportUseCase) autoHide(report *models.ContentReport) error {
+	hiddenFrom := ""
+	switch report.TargetType {
+	case models.ReportTargetBlog:
+		blog, err := uc.blogRepo.GetBlogByID(report.TargetID)
+		if err != nil {
+			return err
+		}
+		if blog.Status == models.BlogStatusHidden {
+			return nil
+		}
+		hiddenFrom = blog.Status
+		if err := uc.blogRepo.UpdateBlogStatus(report.TargetID, models.BlogStatusHidden); err != nil {
+			return err
+		}
+	case models.ReportTargetComment:
+		if err := uc.commentRepo.SetCommentHidden(report.TargetID, true); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	report.AutoHidden = true
+	report.HiddenFrom = hiddenFrom
+	return uc.reportRepo.MarkAutoHidden(report.ID, hiddenFrom)
+}
+
+// hides or restores the reported blog or comment
+func (uc *ReportUseCase) setHidden(report *models.ContentReport, hidden bool) error {
+	switch report.TargetType {
+	case models.ReportTargetBlog:
+		status := models.BlogStatusHidden
+		if !hidden {
+			status = report.HiddenFrom
+			if status == "" {
+				status = models.BlogStatusPublished
+			}
+		}
+		if err := uc.blogRepo.UpdateBlogStatus(report.TargetID, status); err != nil {
+			return usecases.ErrReportTargetNotFound
+		}
+	case models.ReportTargetComment:
+		if err := uc.commentRepo.SetCommentHidden(report.TargetID, hidden); err != nil {
+			return usecases.ErrReportTargetNotFound
+		}
+	}
+	return nil
+}
+
+// removes the reported blog (with its comments) or comment
+func (uc *ReportUseCase) deleteTarget(report *models.ContentReport) error {
+	switch report.TargetType {
+	case models.ReportTargetBlog:
+		if err := uc.blogRepo.DeleteBlog(report.TargetID); err != nil {
+			return usecases.ErrReportTargetNotFound
+		}
+		return uc.commentRepo.DeleteCommentsByBlogID(report.TargetID)
+	case models.ReportTargetComment:
+		comment, err := uc.commentRepo.GetCommentByID(report.TargetID)
+		if err != nil {
+			return usecases.ErrReportTargetNotFound
+		}
+		if err := uc.commentRepo.DeleteComment(report.TargetID); err != nil {
+			return err
+		}
+		return uc.blogRepo.DecrementComment(comment.BlogID)
+	}
+	return nil
+}
+
+// deactivates the content owner and signs them out everywhere
+func (uc *ReportUseCase) suspendOwner(ownerID string) error {
+	owner, err := uc.userRepo.GetUserByID(ownerID)
+	if err != nil {
+		return usecases.ErrReportTargetNotFound
+	}
+
+	roleName := owner.RoleID
+	if role, err := uc.roleRepo.GetRoleByID(owner.RoleID); err == nil {
+		roleName = role.Role
+	}
+	if protected, err := uc.permSvc.HasPermission(roleName, models.PermUserManage); err != nil {
+		return err
+	} else if protected {
+		return usecases.ErrProtectedAccount
+	}
+
+	if owner.IsActive {
+		if err := uc.userRepo.SetUserActive(ownerID, false); err != nil {
+			return err
+		}
+	}
+	return uc.tokenUseCase.RevokeAllUserTokens(ownerID)
+}
+
+// emails the content owner about the decision; failures are only logged
+func (uc *ReportUseCase) notifyOwner(report *models.ContentReport, action, note string) {
+	owner, err := uc.userRepo.GetUserByID(report.TargetOwnerID)
+	if err != nil {
+		return
+	}
+	contentType := report.TargetType
+	if contentType == models.ReportTargetUser {
+		contentType = "account"
+	}
+	if err := uc.emailSvc.SendModerationNoticeEmail(owner.Email, action, contentType, note); err != nil {
+		log.Printf("failed to send moderation notice to %s: %v", owner.ID, err)
+	}
+}
@@ -0,0 +1,152 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"log"
+	"strings"
+	"time"
+)
+
+type ReviewUseCase struct {
+	blogRepo   repositories.IBlogRepository
+	reviewRepo repositories.IReviewRepository
+	userRepo   repositories.IUserRepository
+	emailSvc   services.IEmailService
+	permSvc    services.IPermissionService
+}
+
+func NewReviewUseCase(
+	blogRepo repositories.IBlogRepository,
+	reviewRepo repositories.IReviewRepository,
+	userRepo repositories.IUserRepository,
+	emailSvc services.IEmailService,
+	permSvc services.IPermissionService,
+) *ReviewUseCase {
+	return &ReviewUseCase{
+		blogRepo:   blogRepo,
+		reviewRepo: reviewRepo,
+		userRepo:   userRepo,
+		emailSvc:   emailSvc,
+		permSvc:    permSvc,
+	}
+}
+
+// resubmits a blog to the review queue after changes were requested
+func (uc *ReviewUseCase) SubmitForReview(blogID, authorID string) error {
+	blog, err := uc.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return usecases.ErrBlogNotFound
+	}
+	if blog.AuthorID != authorID {
+		return usecases.ErrNotBlogAuthor
+	}
+	if blog.Status != models.BlogStatusChangesRequested {
+		return usecases.ErrInvalidReviewState
+	}
+
+	return uc.transition(&blog, authorID, models.ReviewActionSubmit, models.BlogStatusPendingReview, "")
+}
+
+// lists blogs waiting for a reviewer
+func (uc *ReviewUseCase) GetReviewQueue(page, pageSize int) ([]models.Blog, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return uc.blogRepo.GetBlogsByStatus(models.BlogStatusPendingReview, page, pageSize)
+}
+
+// publishes a blog that is pending review
+func (uc *ReviewUseCase) ApproveBlog(blogID, reviewerID, comment string) error {
+	return uc.decide(blogID, reviewerID, models.ReviewActionApprove, models.BlogStatusPublished, comment, false)
+}
+
+// rejects a blog that is pending review
+func (uc *ReviewUseCase) RejectBlog(blogID, reviewerID, comment string) error {
+	return uc.decide(blogID, reviewerID, models.ReviewActionReject, models.BlogStatusRejected, comment, true)
+}
+
+// sends a blog back to its author for changes
+func (uc *ReviewUseCase) RequestChanges(blogID, reviewerID, comment string) error {
+	return uc.decide(blogID, reviewerID, models.ReviewActionRequestChanges, models.BlogStatusChangesRequested, comment, true)
+}
+
+// returns the review history of a blog to its author or to a reviewer
+func (uc *ReviewUseCase) GetReviewHistory(blogID, userID, role string) ([]models.BlogReview, error) {
+	blog, err := uc.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return nil, usecases.ErrBlogNotFound
+	}
+	if blog.AuthorID != userID {
+		if canReview, err := uc.permSvc.HasPermission(role, models.PermBlogReview); err != nil || !canReview {
+			return nil, usecases.ErrNotBlogAuthor
+		}
+	}
+	return uc.reviewRepo.GetReviewsByBlogID(blogID)
+}
+
+// applies a reviewer decision and notifies the author
+func (uc *ReviewUseCase) decide(blogID, reviewerID, action, toStatus, comment string, commentRequired bool) error {
+	comment = strings.TrimSpace(comment)
+	if commentRequired && comment == "" {
+		return usecases.ErrReviewCommentRequired
+	}
+
+	blog, err := uc.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return usecases.ErrBlogNotFound
+	}
+	if blog.AuthorID == reviewerID {
+		return usecases.ErrCannotReviewOwnBlog
+	}
+	if blog.Status != models.BlogStatusPendingReview {
+		return usecases.ErrInvalidReviewState
+	}
+
+	if err := uc.transition(&blog, reviewerID, action, toStatus, comment); err != nil {
+		return err
+	}
+
+	author, err := uc.userRepo.GetUserByID(blog.AuthorID)
+	if err != nil {
+		log.Printf("review: author %s of blog %s not found: %v", blog.AuthorID, blogID, err)
+		return nil
+	}
+	if err := uc.emailSvc.SendReviewDecisionEmail(author.Email, blog.Title, action, comment); err != nil {
+		log.Printf("review: failed to notify author of blog %s: %v", blogID, err)
+	}
+	return nil
+}
+
+// moves a blog to a new status and records the transition
+func (uc *ReviewUseCase) transition(blog *models.Blog, actorID, action, toStatus, comment string) error {
+	return transition(uc.blogRepo, uc.reviewRepo, blog, actorID, action, toStatus, comment)
+}
+
+// shared by the review flow and by edits that send a published blog back to review
+func transition(blogRepo repositories.IBlogRepository, reviewRepo repositories.IReviewRepository, blog *models.Blog, actorID, action, toStatus, comment string) error {
+	// conditional on the status we read so two concurrent decisions cannot both apply
+	moved, err := blogRepo.TransitionBlogStatus(blog.ID, blog.Status, toStatus)
+	if err != nil {
+		return err
+	}
+	if !moved {
+		return usecases.ErrInvalidReviewState
+	}
+
+	return reviewRepo.CreateReview(&models.BlogReview{
+		BlogID:     blog.ID,
+		AuthorID:   blog.AuthorID,
+		ActorID:    actorID,
+		Action:     action,
+		FromStatus: blog.Status,
+		ToStatus:   toStatus,
+		Comment:    comment,
+		CreatedAt:  time.Now(),
+	})
+}
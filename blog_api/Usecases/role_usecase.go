@@ -0,0 +1,143 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,29}$`)
+
+type RoleUseCase struct {
+	roleRepo      repositories.IRoleRepository
+	userRepo      repositories.IUserRepository
+	permissionSvc services.IPermissionService
+}
+
+func NewRoleUseCase(
+	roleRepo repositories.IRoleRepository,
+	userRepo repositories.IUserRepository,
+	permissionSvc services.IPermissionService,
+) *RoleUseCase {
+	return &RoleUseCase{
+		roleRepo:      roleRepo,
+		userRepo:      userRepo,
+		permissionSvc: permissionSvc,
+	}
+}
+
+func (uc *RoleUseCase) ListRoles() ([]models.Role, error) {
+	return uc.roleRepo.ListRoles()
+}
+
+func (uc *RoleUseCase) GetRole(roleID string) (*models.Role, error) {
+	role, err := uc.roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return nil, usecases.ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// creates a custom role with a set of permissions
+func (uc *RoleUseCase) CreateRole(name, description string, permissions []string) (*models.Role, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, usecases.ErrInvalidRoleName
+	}
+	perms, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.roleRepo.GetRoleByName(name); err == nil {
+		return nil, usecases.ErrRoleExists
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Role:        name,
+		Description: strings.TrimSpace(description),
+		Permissions: perms,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := uc.roleRepo.CreateRole(role); err != nil {
+		return nil, err
+	}
+	uc.permissionSvc.Invalidate()
+	return role, nil
+}
+
+// replaces a role's description and permissions
+func (uc *RoleUseCase) UpdateRole(roleID, description string, permissions []string) (*models.Role, error) {
+	role, err := uc.roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return nil, usecases.ErrRoleNotFound
+	}
+	perms, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = strings.TrimSpace(description)
+	role.Permissions = perms
+	if role.Role == models.RoleAdmin && (!role.HasPermission(models.PermUserManage) || !role.HasPermission(models.PermRoleManage)) {
+		return nil, usecases.ErrAdminRoleLockout
+	}
+	role.UpdatedAt = time.Now()
+
+	if err := uc.roleRepo.UpdateRole(role); err != nil {
+		return nil, err
+	}
+	uc.permissionSvc.Invalidate()
+	return role, nil
+}
+
+// deletes a custom role that no user holds
+func (uc *RoleUseCase) DeleteRole(roleID string) error {
+	role, err := uc.roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return usecases.ErrRoleNotFound
+	}
+	if models.IsBuiltInRole(role.Role) {
+		return usecases.ErrBuiltInRole
+	}
+
+	count, err := uc.userRepo.CountUsersByRole(roleID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return usecases.ErrRoleInUse
+	}
+
+	if err := uc.roleRepo.DeleteRole(roleID); err != nil {
+		return err
+	}
+	uc.permissionSvc.Invalidate()
+	return nil
+}
+
+// validates and de-duplicates permission names
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool)
+	perms := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		if !models.IsValidPermission(p) {
+			return nil, fmt.Errorf("%w: %s", usecases.ErrInvalidPermission, p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+	return perms, nil
+}
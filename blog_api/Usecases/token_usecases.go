@@ -3,15 +3,25 @@ package usecases
 import (
 	repositories "blog_api/Domain/contracts/repositories"
 	services "blog_api/Domain/contracts/services"
+	usecases "blog_api/Domain/contracts/usecases"
 	"blog_api/Domain/models"
+	"errors"
+	"log"
+	"strings"
 	"time"
 )
 
+// how long a session lasts without being refreshed; matches the refresh token lifetime
+const sessionTTL = 7 * 24 * time.Hour
+
 
 type TokenUseCase struct {
-	tokenRepo repositories.ITokenRepository
-	jwtSvc    services.IJWTService
-    roleRepo  repositories.IRoleRepository
+	tokenRepo   repositories.ITokenRepository
+	jwtSvc      services.IJWTService
+    roleRepo    repositories.IRoleRepository
+	sessionRepo repositories.ISessionRepository
+	denylist    services.ITokenDenylist
+	userRepo    repositories.IUserRepository
 }
 
 
@@ -19,11 +29,17 @@ func NewTokenUseCase(
     tokenRepo repositories.ITokenRepository,
     jwtSvc services.IJWTService,
     roleRepo repositories.IRoleRepository,
+	sessionRepo repositories.ISessionRepository,
+	denylist services.ITokenDenylist,
+	userRepo repositories.IUserRepository,
 ) *TokenUseCase {
 	return &TokenUseCase{
-		tokenRepo: tokenRepo,
-		jwtSvc:    jwtSvc,
-        roleRepo:  roleRepo,
+		tokenRepo:   tokenRepo,
+		jwtSvc:      jwtSvc,
+        roleRepo:    roleRepo,
+		sessionRepo: sessionRepo,
+		denylist:    denylist,
+		userRepo:    userRepo,
 	}
 }
 
@@ -42,15 +58,23 @@ func (uc *TokenUseCase) StoreTokens(accessToken *models.AccessToken, refreshToke
 	return nil
 }
 
-// generates and stores tokens with proper expiration times
-func (uc *TokenUseCase) GenerateAndStoreTokens(userID, roleID string) (*models.AccessToken, *models.RefreshToken, error) {
+// generates and stores tokens for a new login; the session it opens is also the refresh token family
+func (uc *TokenUseCase) GenerateAndStoreTokens(userID, roleID string, client models.SessionClient) (*models.AccessToken, *models.RefreshToken, error) {
+	session, err := uc.openSession(userID, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return uc.issueTokens(userID, roleID, session.ID)
+}
+
+func (uc *TokenUseCase) issueTokens(userID, roleID, familyID string) (*models.AccessToken, *models.RefreshToken, error) {
     roleForClaim := roleID
     if uc.roleRepo != nil && len(roleID) == 24 {
         if role, err := uc.roleRepo.GetRoleByID(roleID); err == nil && role != nil && role.Role != "" {
             roleForClaim = role.Role
         }
     }
-    accessTokenString, err := uc.jwtSvc.GenerateJWT(userID, roleForClaim)
+    accessTokenString, jti, err := uc.jwtSvc.GenerateJWT(userID, roleForClaim, familyID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -66,6 +90,8 @@ func (uc *TokenUseCase) GenerateAndStoreTokens(userID, roleID string) (*models.A
 	accessToken := &models.AccessToken{
 		UserID:    userID,
 		Token:     accessTokenString,
+		JTI:       jti,
+		FamilyID:  familyID,
 		ExpiresAt: accessExpiresAt,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -73,6 +99,7 @@ func (uc *TokenUseCase) GenerateAndStoreTokens(userID, roleID string) (*models.A
 	refreshToken := &models.RefreshToken{
 		UserID:    userID,
 		Token:     refreshTokenString,
+		FamilyID:  familyID,
 		ExpiresAt: refreshExpiresAt,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -86,29 +113,145 @@ func (uc *TokenUseCase) GenerateAndStoreTokens(userID, roleID string) (*models.A
 	return accessToken, refreshToken, nil
 }
 
-// generates a new access token with proper expiration
-func (uc *TokenUseCase) RefreshAccessToken(userID, roleID string) (*models.AccessToken, error) {
-	accessTokenString, err := uc.jwtSvc.GenerateJWT(userID, roleID)
+// exchanges a refresh token for a new token pair in the same family; the old refresh token stops working.
+// Presenting a token that was already exchanged means it leaked, so the whole family is revoked.
+func (uc *TokenUseCase) RotateRefreshToken(token string, client models.SessionClient) (*models.AccessToken, *models.RefreshToken, error) {
+	claims, err := uc.jwtSvc.ValidateRefreshToken(token)
 	if err != nil {
-		return nil, err
+		return nil, nil, usecases.ErrInvalidRefreshToken
 	}
-	
-	accessExpiresAt := time.Now().Add(15 * time.Minute) // 15 minutes for refresh
-	
-	accessToken := &models.AccessToken{
-		UserID:    userID,
-		Token:     accessTokenString,
-		ExpiresAt: accessExpiresAt,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	userID, _ := claims["user_id"].(string)
+	if userID == "" {
+		return nil, nil, usecases.ErrInvalidRefreshToken
+	}
+
+	stored, err := uc.tokenRepo.GetRefreshToken(token)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil, usecases.ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+	if stored.UserID != userID || time.Now().After(stored.ExpiresAt) {
+		return nil, nil, usecases.ErrInvalidRefreshToken
+	}
+
+	// the role and account state are read fresh, so a demotion or deactivation is not carried forward
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, usecases.ErrInvalidRefreshToken
+	}
+	if !user.IsActive || user.DeletedAt != nil {
+		if err := uc.revokeFamily(stored); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, usecases.ErrAccountDeactivated
+	}
+
+	rotated, err := uc.tokenRepo.MarkRefreshTokenRotated(token, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !rotated {
+		if err := uc.revokeFamily(stored); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, usecases.ErrRefreshTokenReused
+	}
+
+	familyID := stored.FamilyID
+	if familyID == "" {
+		// tokens issued before sessions existed open one on their first rotation
+		session, err := uc.openSession(userID, client)
+		if err != nil {
+			return nil, nil, err
+		}
+		familyID = session.ID
+	} else {
+		now := time.Now()
+		if err := uc.sessionRepo.Touch(familyID, client, now, now.Add(sessionTTL)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return uc.issueTokens(userID, user.RoleID, familyID)
+}
+
+func (uc *TokenUseCase) revokeFamily(stored *models.RefreshToken) error {
+	if stored.FamilyID == "" {
+		return uc.RevokeAllUserTokens(stored.UserID)
+	}
+	return uc.revokeSession(stored.FamilyID)
+}
+
+// lists the devices a user is signed in on
+func (uc *TokenUseCase) ListSessions(userID string) ([]models.Session, error) {
+	return uc.sessionRepo.ListActive(userID, time.Now())
+}
+
+// signs one of the user's devices out
+func (uc *TokenUseCase) RevokeSession(userID, sessionID string) error {
+	session, err := uc.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return usecases.ErrSessionNotFound
+		}
+		return err
+	}
+	if session.UserID != userID || session.RevokedAt != nil {
+		return usecases.ErrSessionNotFound
 	}
+	return uc.revokeSession(sessionID)
+}
 
-	err = uc.tokenRepo.StoreAccessToken(accessToken)
+// signs every device but the current one out, returning how many were revoked
+func (uc *TokenUseCase) RevokeOtherSessions(userID, currentSessionID string) (int, error) {
+	sessions, err := uc.sessionRepo.ListActive(userID, time.Now())
 	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+		if err := uc.revokeSession(session.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+func (uc *TokenUseCase) openSession(userID string, client models.SessionClient) (*models.Session, error) {
+	now := time.Now()
+	session := &models.Session{
+		UserID:     userID,
+		UserAgent:  client.UserAgent,
+		Device:     describeDevice(client.UserAgent),
+		IPAddress:  client.IPAddress,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(sessionTTL),
+	}
+	if err := uc.sessionRepo.Create(session); err != nil {
 		return nil, err
 	}
-	
-	return accessToken, nil
+	return session, nil
+}
+
+func (uc *TokenUseCase) revokeSession(sessionID string) error {
+	if err := uc.tokenRepo.RevokeTokenFamily(sessionID); err != nil {
+		return err
+	}
+	uc.syncDenylist()
+	return uc.sessionRepo.Revoke(sessionID, time.Now())
+}
+
+// makes a revocation take effect on this instance right away; other instances pick it up on their next sync
+func (uc *TokenUseCase) syncDenylist() {
+	if err := uc.denylist.Sync(); err != nil {
+		log.Printf("failed to sync token denylist: %v", err)
+	}
 }
 
 // validates an access token
@@ -133,7 +276,11 @@ func (uc *TokenUseCase) ValidateRefreshToken(token string) (bool, error) {
 
 // revokes a specific access token
 func (uc *TokenUseCase) RevokeAccessToken(token string) error {
-	return uc.tokenRepo.RevokeAccessToken(token)
+	if err := uc.tokenRepo.RevokeAccessToken(token); err != nil {
+		return err
+	}
+	uc.syncDenylist()
+	return nil
 }
 
 // revokes a specific refresh token
@@ -141,7 +288,47 @@ func (uc *TokenUseCase) RevokeRefreshToken(token string) error {
 	return uc.tokenRepo.RevokeRefreshToken(token)
 }
 
-// revokes all tokens for a user
+// revokes all tokens and sessions for a user
 func (uc *TokenUseCase) RevokeAllUserTokens(userID string) error {
-	return uc.tokenRepo.RevokeAllUserTokens(userID)
-} 
\ No newline at end of file
+	if err := uc.tokenRepo.RevokeAllUserTokens(userID); err != nil {
+		return err
+	}
+	uc.syncDenylist()
+	return uc.sessionRepo.RevokeAllForUser(userID, time.Now())
+}
+
+// a short human label such as "Firefox on Windows" for the sessions list
+func describeDevice(userAgent string) string {
+	browsers := []struct{ marker, name string }{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Chrome/", "Chrome"}, {"Firefox/", "Firefox"},
+		{"Safari/", "Safari"}, {"PostmanRuntime", "Postman"}, {"curl/", "curl"},
+	}
+	systems := []struct{ marker, name string }{
+		{"Windows", "Windows"}, {"Android", "Android"}, {"iPhone", "iOS"}, {"iPad", "iPadOS"},
+		{"Mac OS X", "macOS"}, {"CrOS", "ChromeOS"}, {"Linux", "Linux"},
+	}
+
+	browser, system := "", ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.marker) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.marker) {
+			system = s.name
+			break
+		}
+	}
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Unknown device"
+	}
+}
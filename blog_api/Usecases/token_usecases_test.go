@@ -0,0 +1,338 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	repositories "blog_api/Domain/contracts/repositories"
+	services "blog_api/Domain/contracts/services"
+	usecases "blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+)
+
+// in-memory stand-ins; the embedded interfaces panic if the use case calls anything unexpected
+
+type fakeJWTService struct {
+	services.IJWTService
+	n       int
+	holders map[string]string // refresh token -> user id
+}
+
+func (f *fakeJWTService) GenerateJWT(userID, role, sessionID string) (string, string, error) {
+	f.n++
+	return fmt.Sprintf("access-%d", f.n), fmt.Sprintf("jti-%d", f.n), nil
+}
+
+func (f *fakeJWTService) GenerateRefreshToken(userID, role string) (string, error) {
+	f.n++
+	token := fmt.Sprintf("refresh-%d", f.n)
+	f.holders[token] = userID
+	return token, nil
+}
+
+func (f *fakeJWTService) ValidateRefreshToken(token string) (map[string]interface{}, error) {
+	userID, ok := f.holders[token]
+	if !ok {
+		return nil, errors.New("signature is invalid")
+	}
+	return map[string]interface{}{"user_id": userID}, nil
+}
+
+type fakeTokenRepo struct {
+	repositories.ITokenRepository
+	refresh         map[string]*models.RefreshToken
+	revokedFamilies []string
+	revokedUsers    []string
+}
+
+func (f *fakeTokenRepo) StoreAccessToken(*models.AccessToken) error { return nil }
+
+func (f *fakeTokenRepo) StoreRefreshToken(t *models.RefreshToken) error {
+	f.refresh[t.Token] = t
+	return nil
+}
+
+func (f *fakeTokenRepo) GetRefreshToken(token string) (*models.RefreshToken, error) {
+	t, ok := f.refresh[token]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	stored := *t
+	return &stored, nil
+}
+
+func (f *fakeTokenRepo) MarkRefreshTokenRotated(token string, rotatedAt time.Time) (bool, error) {
+	t, ok := f.refresh[token]
+	if !ok || t.RotatedAt != nil {
+		return false, nil
+	}
+	t.RotatedAt = &rotatedAt
+	return true, nil
+}
+
+func (f *fakeTokenRepo) RevokeTokenFamily(familyID string) error {
+	f.revokedFamilies = append(f.revokedFamilies, familyID)
+	for token, t := range f.refresh {
+		if t.FamilyID == familyID {
+			delete(f.refresh, token)
+		}
+	}
+	return nil
+}
+
+func (f *fakeTokenRepo) RevokeAllUserTokens(userID string) error {
+	f.revokedUsers = append(f.revokedUsers, userID)
+	for token, t := range f.refresh {
+		if t.UserID == userID {
+			delete(f.refresh, token)
+		}
+	}
+	return nil
+}
+
+type fakeSessionRepo struct {
+	repositories.ISessionRepository
+	created []*models.Session
+	touched []string
+	revoked []string
+}
+
+func (f *fakeSessionRepo) Create(session *models.Session) error {
+	session.ID = fmt.Sprintf("session-%d", len(f.created)+1)
+	f.created = append(f.created, session)
+	return nil
+}
+
+func (f *fakeSessionRepo) Touch(sessionID string, client models.SessionClient, usedAt, expiresAt time.Time) error {
+	f.touched = append(f.touched, sessionID)
+	return nil
+}
+
+func (f *fakeSessionRepo) Revoke(sessionID string, revokedAt time.Time) error {
+	f.revoked = append(f.revoked, sessionID)
+	return nil
+}
+
+func (f *fakeSessionRepo) RevokeAllForUser(userID string, revokedAt time.Time) error { return nil }
+
+type fakeDenylist struct {
+	services.ITokenDenylist
+	syncs int
+}
+
+func (f *fakeDenylist) Sync() error {
+	f.syncs++
+	return nil
+}
+
+type fakeUserRepo struct {
+	repositories.IUserRepository
+	users map[string]*models.User
+}
+
+func (f *fakeUserRepo) GetUserByID(userID string) (*models.User, error) {
+	u, ok := f.users[userID]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return u, nil
+}
+
+type tokenFixture struct {
+	uc       *TokenUseCase
+	jwt      *fakeJWTService
+	tokens   *fakeTokenRepo
+	sessions *fakeSessionRepo
+	denylist *fakeDenylist
+	users    *fakeUserRepo
+}
+
+func newTokenFixture() *tokenFixture {
+	f := &tokenFixture{
+		jwt:      &fakeJWTService{holders: map[string]string{}},
+		tokens:   &fakeTokenRepo{refresh: map[string]*models.RefreshToken{}},
+		sessions: &fakeSessionRepo{},
+		denylist: &fakeDenylist{},
+		users: &fakeUserRepo{users: map[string]*models.User{
+			"alice": {ID: "alice", RoleID: "user", IsActive: true},
+			"bob":   {ID: "bob", RoleID: "user", IsActive: true},
+		}},
+	}
+	f.uc = NewTokenUseCase(f.tokens, f.jwt, nil, f.sessions, f.denylist, f.users)
+	return f
+}
+
+func (f *tokenFixture) login(t *testing.T, userID string) string {
+	t.Helper()
+	_, refresh, err := f.uc.GenerateAndStoreTokens(userID, "user", models.SessionClient{})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	return refresh.Token
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	client := models.SessionClient{UserAgent: "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"}
+	tests := []struct {
+		name string
+		// returns the token to present
+		setup   func(t *testing.T, f *tokenFixture) string
+		wantErr error
+		check   func(t *testing.T, f *tokenFixture)
+	}{
+		{
+			name:  "rotates within the session",
+			setup: func(t *testing.T, f *tokenFixture) string { return f.login(t, "alice") },
+			check: func(t *testing.T, f *tokenFixture) {
+				if len(f.sessions.touched) != 1 || f.sessions.touched[0] != "session-1" {
+					t.Fatalf("expected session-1 to be touched, got %v", f.sessions.touched)
+				}
+				if len(f.tokens.revokedFamilies) != 0 {
+					t.Fatalf("expected nothing revoked, got %v", f.tokens.revokedFamilies)
+				}
+			},
+		},
+		{
+			name: "reused token revokes the family",
+			setup: func(t *testing.T, f *tokenFixture) string {
+				stolen := f.login(t, "alice")
+				if _, _, err := f.uc.RotateRefreshToken(stolen, client); err != nil {
+					t.Fatalf("first rotation failed: %v", err)
+				}
+				return stolen
+			},
+			wantErr: usecases.ErrRefreshTokenReused,
+			check: func(t *testing.T, f *tokenFixture) {
+				if len(f.tokens.revokedFamilies) != 1 || f.tokens.revokedFamilies[0] != "session-1" {
+					t.Fatalf("expected family session-1 revoked, got %v", f.tokens.revokedFamilies)
+				}
+				if len(f.sessions.revoked) != 1 || f.denylist.syncs == 0 {
+					t.Fatalf("expected the session revoked and the denylist synced, got %v, %d syncs", f.sessions.revoked, f.denylist.syncs)
+				}
+				// the token the legitimate holder got from the first rotation is gone too
+				if len(f.tokens.refresh) != 0 {
+					t.Fatalf("expected no refresh tokens left in the family, got %d", len(f.tokens.refresh))
+				}
+			},
+		},
+		{
+			name: "reuse only revokes the affected family",
+			setup: func(t *testing.T, f *tokenFixture) string {
+				stolen := f.login(t, "alice")
+				f.login(t, "alice")
+				if _, _, err := f.uc.RotateRefreshToken(stolen, client); err != nil {
+					t.Fatalf("first rotation failed: %v", err)
+				}
+				return stolen
+			},
+			wantErr: usecases.ErrRefreshTokenReused,
+			check: func(t *testing.T, f *tokenFixture) {
+				if len(f.tokens.revokedFamilies) != 1 || f.tokens.revokedFamilies[0] != "session-1" {
+					t.Fatalf("expected only session-1 revoked, got %v", f.tokens.revokedFamilies)
+				}
+				if len(f.tokens.refresh) != 1 {
+					t.Fatalf("expected the other device's token to survive, got %d tokens", len(f.tokens.refresh))
+				}
+			},
+		},
+		{
+			name: "reused legacy token without a family revokes everything",
+			setup: func(t *testing.T, f *tokenFixture) string {
+				legacy := "legacy"
+				f.jwt.holders[legacy] = "alice"
+				rotated := time.Now().Add(-time.Minute)
+				f.tokens.refresh[legacy] = &models.RefreshToken{UserID: "alice", Token: legacy, RotatedAt: &rotated, ExpiresAt: time.Now().Add(time.Hour)}
+				return legacy
+			},
+			wantErr: usecases.ErrRefreshTokenReused,
+			check: func(t *testing.T, f *tokenFixture) {
+				if len(f.tokens.revokedUsers) != 1 || f.tokens.revokedUsers[0] != "alice" {
+					t.Fatalf("expected all of alice's tokens revoked, got %v", f.tokens.revokedUsers)
+				}
+			},
+		},
+		{
+			name: "legacy token opens a session on first rotation",
+			setup: func(t *testing.T, f *tokenFixture) string {
+				legacy := "legacy"
+				f.jwt.holders[legacy] = "alice"
+				f.tokens.refresh[legacy] = &models.RefreshToken{UserID: "alice", Token: legacy, ExpiresAt: time.Now().Add(time.Hour)}
+				return legacy
+			},
+			check: func(t *testing.T, f *tokenFixture) {
+				if len(f.sessions.created) != 1 || f.sessions.created[0].Device != "Firefox on Linux" {
+					t.Fatalf("expected one session for the presenting client, got %v", f.sessions.created)
+				}
+			},
+		},
+		{
+			name:    "bad signature",
+			setup:   func(t *testing.T, f *tokenFixture) string { return "forged" },
+			wantErr: usecases.ErrInvalidRefreshToken,
+		},
+		{
+			name: "signed but never stored",
+			setup: func(t *testing.T, f *tokenFixture) string {
+				f.jwt.holders["unknown"] = "alice"
+				return "unknown"
+			},
+			wantErr: usecases.ErrInvalidRefreshToken,
+		},
+		{
+			name: "stored for another user",
+			setup: func(t *testing.T, f *tokenFixture) string {
+				token := f.login(t, "bob")
+				f.jwt.holders[token] = "alice"
+				return token
+			},
+			wantErr: usecases.ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired",
+			setup: func(t *testing.T, f *tokenFixture) string {
+				token := f.login(t, "alice")
+				f.tokens.refresh[token].ExpiresAt = time.Now().Add(-time.Second)
+				return token
+			},
+			wantErr: usecases.ErrInvalidRefreshToken,
+		},
+		{
+			name: "deactivated account",
+			setup: func(t *testing.T, f *tokenFixture) string {
+				token := f.login(t, "alice")
+				f.users.users["alice"].IsActive = false
+				return token
+			},
+			wantErr: usecases.ErrAccountDeactivated,
+			check: func(t *testing.T, f *tokenFixture) {
+				if len(f.tokens.revokedFamilies) != 1 {
+					t.Fatalf("expected the family revoked, got %v", f.tokens.revokedFamilies)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTokenFixture()
+			token := tt.setup(t, f)
+			access, refresh, err := f.uc.RotateRefreshToken(token, client)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("expected rotation, got %v", err)
+				}
+				if refresh.Token == token || refresh.FamilyID == "" || access.FamilyID != refresh.FamilyID {
+					t.Fatalf("expected a fresh pair in one family, got %+v / %+v", access, refresh)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, f)
+			}
+		})
+	}
+}
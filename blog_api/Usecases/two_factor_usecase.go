@@ -0,0 +1,351 @@
+package usecases
+
+import (
+	"blog_api/Domain/contracts/repositories"
+	"blog_api/Domain/contracts/services"
+	"blog_api/Domain/contracts/usecases"
+	"blog_api/Domain/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// how long a password-verified login waits for its second factor
+	twoFactorLoginTTL = 5 * time.Minute
+	// how long an admin has to enroll when the policy forces 2FA at login
+	twoFactorSetupTTL = 15 * time.Minute
+	// wrong codes a login challenge survives; the user then has to enter their password again
+	twoFactorChallengeAttempts = 5
+
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// which accounts must use two-factor authentication
+type TwoFactorPolicy struct {
+	// accounts whose role can manage users or roles must enroll before they can sign in
+	RequireForAdmins bool
+}
+
+type TwoFactorUseCase struct {
+	userRepo     repositories.IUserRepository
+	roleRepo     repositories.IRoleRepository
+	permSvc      services.IPermissionService
+	passwordSvc  services.IPasswordService
+	totp         services.ITOTPService
+	actionTokens services.IActionTokenService
+	attemptRepo  repositories.ILoginAttemptRepository
+	policy       TwoFactorPolicy
+}
+
+func NewTwoFactorUseCase(
+	userRepo repositories.IUserRepository,
+	roleRepo repositories.IRoleRepository,
+	permSvc services.IPermissionService,
+	passwordSvc services.IPasswordService,
+	totp services.ITOTPService,
+	actionTokens services.IActionTokenService,
+	attemptRepo repositories.ILoginAttemptRepository,
+	policy TwoFactorPolicy,
+) *TwoFactorUseCase {
+	return &TwoFactorUseCase{
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		permSvc:      permSvc,
+		passwordSvc:  passwordSvc,
+		totp:         totp,
+		actionTokens: actionTokens,
+		attemptRepo:  attemptRepo,
+		policy:       policy,
+	}
+}
+
+// decides whether a user who passed the password check needs a second step
+func (uc *TwoFactorUseCase) BeginLogin(user *models.User) (*models.TwoFactorChallenge, error) {
+	switch {
+	case user.TwoFactorEnabled:
+		return uc.challenge(user.ID, models.ActionTwoFactorLogin, models.TwoFactorChallengeCode, twoFactorLoginTTL)
+	case uc.isRequired(user):
+		return uc.challenge(user.ID, models.ActionTwoFactorSetup, models.TwoFactorChallengeSetup, twoFactorSetupTTL)
+	default:
+		return nil, nil
+	}
+}
+
+func (uc *TwoFactorUseCase) ChallengeUser(challengeToken string) (*models.User, error) {
+	return uc.userForChallenge(models.ActionTwoFactorLogin, challengeToken)
+}
+
+// checks the second factor for a pending login, accepting either a TOTP code or a recovery code
+func (uc *TwoFactorUseCase) CompleteLogin(challengeToken, code, recoveryCode string) (*models.TwoFactorLogin, error) {
+	user, err := uc.userForChallenge(models.ActionTwoFactorLogin, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, usecases.ErrInvalidTwoFactorChallenge
+	}
+
+	// challenges are stateless tokens, so their wrong codes are counted by a hash of the token
+	tokenHash := sha256.Sum256([]byte(challengeToken))
+	attemptKey := "two_factor:challenge:" + hex.EncodeToString(tokenHash[:])
+	attempts, err := uc.attemptRepo.Get(attemptKey)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, err
+	}
+	if attempts != nil && attempts.Failures >= twoFactorChallengeAttempts {
+		return nil, usecases.ErrInvalidTwoFactorChallenge
+	}
+
+	if recoveryCode != "" {
+		used, err := uc.userRepo.ConsumeRecoveryCode(user.ID, hashRecoveryCode(recoveryCode))
+		if err != nil {
+			return nil, err
+		}
+		if !used {
+			return nil, uc.challengeFailed(attemptKey)
+		}
+		return &models.TwoFactorLogin{
+			User:              user,
+			Method:            models.TwoFactorMethodRecoveryCode,
+			RecoveryCodesLeft: len(user.RecoveryCodes) - 1,
+		}, nil
+	}
+
+	if err := uc.verifyCode(user.ID, user.TwoFactorSecret, code); err != nil {
+		if errors.Is(err, usecases.ErrInvalidTwoFactorCode) {
+			return nil, uc.challengeFailed(attemptKey)
+		}
+		return nil, err
+	}
+	return &models.TwoFactorLogin{
+		User:              user,
+		Method:            models.TwoFactorMethodTOTP,
+		RecoveryCodesLeft: len(user.RecoveryCodes),
+	}, nil
+}
+
+// counts a wrong code against the challenge and reports it
+func (uc *TwoFactorUseCase) challengeFailed(attemptKey string) error {
+	if _, err := uc.attemptRepo.RecordFailure(attemptKey, time.Now(), twoFactorLoginTTL); err != nil {
+		return err
+	}
+	return usecases.ErrInvalidTwoFactorCode
+}
+
+// starts enrollment for an account the policy forced into setup at login
+func (uc *TwoFactorUseCase) StartRequiredEnrollment(challengeToken string) (*models.TwoFactorEnrollment, error) {
+	user, err := uc.userForChallenge(models.ActionTwoFactorSetup, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return uc.startEnrollment(user)
+}
+
+// confirms a forced enrollment; the caller signs the user in on success
+func (uc *TwoFactorUseCase) ConfirmRequiredEnrollment(challengeToken, code string) (*models.User, []string, error) {
+	user, err := uc.userForChallenge(models.ActionTwoFactorSetup, challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	codes, err := uc.confirmEnrollment(user, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, codes, nil
+}
+
+// generates a new secret for a signed-in user; 2FA stays off until it is confirmed
+func (uc *TwoFactorUseCase) StartEnrollment(userID string) (*models.TwoFactorEnrollment, error) {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, usecases.ErrUserNotFound
+	}
+	return uc.startEnrollment(user)
+}
+
+// turns 2FA on once the user proves their app produces valid codes, returning the recovery codes
+func (uc *TwoFactorUseCase) ConfirmEnrollment(userID, code string) ([]string, error) {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, usecases.ErrUserNotFound
+	}
+	return uc.confirmEnrollment(user, code)
+}
+
+// turns 2FA off after re-checking the password and a current code
+func (uc *TwoFactorUseCase) Disable(userID, password, code string) error {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return usecases.ErrUserNotFound
+	}
+	if !user.TwoFactorEnabled {
+		return usecases.ErrTwoFactorNotEnabled
+	}
+	if uc.isRequired(user) {
+		return usecases.ErrTwoFactorMandatory
+	}
+	// accounts created through OAuth have no password to re-check
+	if user.Password != "" && !uc.passwordSvc.CheckPasswordHash(password, user.Password) {
+		return usecases.ErrInvalidPassword
+	}
+	if err := uc.verifyCode(user.ID, user.TwoFactorSecret, code); err != nil {
+		return err
+	}
+	return uc.userRepo.DisableTwoFactor(user.ID)
+}
+
+// replaces every recovery code, invalidating the old ones
+func (uc *TwoFactorUseCase) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, usecases.ErrUserNotFound
+	}
+	if !user.TwoFactorEnabled {
+		return nil, usecases.ErrTwoFactorNotEnabled
+	}
+	if err := uc.verifyCode(user.ID, user.TwoFactorSecret, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (uc *TwoFactorUseCase) startEnrollment(user *models.User) (*models.TwoFactorEnrollment, error) {
+	if user.TwoFactorEnabled {
+		return nil, usecases.ErrTwoFactorAlreadyEnabled
+	}
+	secret, err := uc.totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.SetTwoFactorPending(user.ID, secret); err != nil {
+		return nil, err
+	}
+	return &models.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: uc.totp.ProvisioningURI(secret, user.Email),
+	}, nil
+}
+
+func (uc *TwoFactorUseCase) confirmEnrollment(user *models.User, code string) ([]string, error) {
+	if user.TwoFactorEnabled {
+		return nil, usecases.ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorPending == "" {
+		return nil, usecases.ErrTwoFactorNotStarted
+	}
+	if err := uc.verifyCode(user.ID, user.TwoFactorPending, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.EnableTwoFactor(user.ID, user.TwoFactorPending, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// validates a TOTP code and burns its time step so it cannot be replayed
+func (uc *TwoFactorUseCase) verifyCode(userID, secret, code string) error {
+	step, ok := uc.totp.Validate(secret, code, time.Now())
+	if !ok {
+		return usecases.ErrInvalidTwoFactorCode
+	}
+	claimed, err := uc.userRepo.ClaimTOTPStep(userID, step)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return usecases.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (uc *TwoFactorUseCase) challenge(userID, purpose, kind string, ttl time.Duration) (*models.TwoFactorChallenge, error) {
+	token, err := uc.actionTokens.Issue(purpose, userID, nil, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TwoFactorChallenge{Token: token, Kind: kind, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (uc *TwoFactorUseCase) userForChallenge(purpose, challengeToken string) (*models.User, error) {
+	claims, err := uc.actionTokens.Verify(purpose, challengeToken)
+	if err != nil {
+		return nil, usecases.ErrInvalidTwoFactorChallenge
+	}
+	user, err := uc.userRepo.GetUserByID(claims.Subject)
+	if err != nil || !user.IsActive || user.DeletedAt != nil {
+		return nil, usecases.ErrInvalidTwoFactorChallenge
+	}
+	return user, nil
+}
+
+// true when the policy forces 2FA on this user's role
+func (uc *TwoFactorUseCase) isRequired(user *models.User) bool {
+	if !uc.policy.RequireForAdmins {
+		return false
+	}
+	roleName := user.RoleID
+	if role, err := uc.roleRepo.GetRoleByID(user.RoleID); err == nil {
+		roleName = role.Role
+	}
+	for _, perm := range []string{models.PermUserManage, models.PermRoleManage} {
+		if granted, err := uc.permSvc.HasPermission(roleName, perm); err == nil && granted {
+			return true
+		}
+	}
+	return false
+}
+
+// returns plain codes for the user and their hashes for storage
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	// bytes at or above this are skipped so every character is equally likely
+	limit := 256 - 256%len(recoveryCodeAlphabet)
+	buf := make([]byte, 1)
+	for i := 0; i < recoveryCodeCount; i++ {
+		var sb strings.Builder
+		for n := 0; n < recoveryCodeLength; {
+			if _, err := rand.Read(buf); err != nil {
+				return nil, nil, err
+			}
+			if int(buf[0]) >= limit {
+				continue
+			}
+			if n == recoveryCodeLength/2 {
+				sb.WriteByte('-')
+			}
+			sb.WriteByte(recoveryCodeAlphabet[int(buf[0])%len(recoveryCodeAlphabet)])
+			n++
+		}
+		code := sb.String()
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// recovery codes are random enough that a plain SHA-256 is a safe at-rest form
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
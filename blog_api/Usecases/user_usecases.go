@@ -8,9 +8,27 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// how long an emailed verification link stays valid
+const emailVerificationTTL = 24 * time.Hour
+
+// how long the current address has to approve a change requested without a password
+const emailApprovalTTL = time.Hour
+
+// how long the previous address can undo an email change
+const emailRevertTTL = 7 * 24 * time.Hour
+
+// settings for the email verification flow
+type EmailVerificationConfig struct {
+	// minimum time between two verification emails to the same user
+	ResendCooldown time.Duration
+}
+
 type UserUseCase struct {
 	userRepo      repositories.IUserRepository
 	passwordSvc   services.IPasswordService
@@ -19,6 +37,9 @@ type UserUseCase struct {
 	emailSvc     services.IEmailService
 	tokenUseCase  usecases.ITokenUseCase
 	roleRepo      repositories.IRoleRepository
+	actionTokens  services.IActionTokenService
+	verification  EmailVerificationConfig
+	passwordPolicy models.PasswordPolicy
 }
 
 func NewUserUseCase(
@@ -29,6 +50,9 @@ func NewUserUseCase(
 	emailSvc services.IEmailService,
 	tokenUseCase usecases.ITokenUseCase,
 	roleRepo repositories.IRoleRepository,
+	actionTokens services.IActionTokenService,
+	verification EmailVerificationConfig,
+	passwordPolicy models.PasswordPolicy,
 ) *UserUseCase {
 	return &UserUseCase{
 		userRepo:      userRepo,
@@ -38,6 +62,9 @@ func NewUserUseCase(
 		emailSvc:      emailSvc,
 		tokenUseCase:  tokenUseCase,
 		roleRepo:      roleRepo,
+		actionTokens:  actionTokens,
+		verification:  verification,
+		passwordPolicy: passwordPolicy,
 	}
 }
 
@@ -46,7 +73,7 @@ func (uc *UserUseCase) RegisterUser(user *models.User) error {
 	if err := uc.validationSvc.ValidateEmail(user.Email); err != nil {
 		return err
 	}
-	if err := uc.validationSvc.ValidatePassword(user.Password); err != nil {
+	if err := uc.validationSvc.ValidatePassword(user.Password, user.Username, user.Email, user.FirstName, user.LastName); err != nil {
 		return err
 	}
 	if err := uc.validationSvc.ValidateUsername(user.Username); err != nil {
@@ -73,6 +100,8 @@ func (uc *UserUseCase) RegisterUser(user *models.User) error {
 		return err
 	}
 	user.Password = hashedPassword
+	changedAt := time.Now()
+	user.PasswordChangedAt = &changedAt
 
 	// Set default values
 	user.IsActive = true
@@ -88,7 +117,67 @@ func (uc *UserUseCase) RegisterUser(user *models.User) error {
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
-	return uc.userRepo.CreateUser(user)
+	if err := uc.userRepo.CreateUser(user); err != nil {
+		return err
+	}
+
+	// the account exists either way; the user can ask for another link
+	if err := uc.sendVerificationEmail(user); err != nil {
+		log.Printf("failed to send verification email to %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// verifies a user's email from the emailed token and returns the user
+func (uc *UserUseCase) VerifyEmail(token string) (*models.User, error) {
+	claims, err := uc.actionTokens.Verify(models.ActionEmailVerification, token)
+	if err != nil {
+		return nil, usecases.ErrInvalidVerificationToken
+	}
+
+	user, err := uc.userRepo.GetUserByID(claims.Subject)
+	if err != nil {
+		return nil, usecases.ErrInvalidVerificationToken
+	}
+	// a link sent to an old address must not verify the current one
+	if claims.Data["email"] != user.Email {
+		return nil, usecases.ErrInvalidVerificationToken
+	}
+	if user.EmailVerified {
+		return nil, usecases.ErrEmailAlreadyVerified
+	}
+
+	if err := uc.userRepo.MarkEmailVerified(user.ID); err != nil {
+		return nil, err
+	}
+	user.EmailVerified = true
+	return user, nil
+}
+
+// sends a fresh verification link, at most once per cooldown period
+func (uc *UserUseCase) ResendVerificationEmail(userID string) error {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.EmailVerified {
+		return usecases.ErrEmailAlreadyVerified
+	}
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < uc.verification.ResendCooldown {
+		return usecases.ErrVerificationThrottled
+	}
+	return uc.sendVerificationEmail(user)
+}
+
+func (uc *UserUseCase) sendVerificationEmail(user *models.User) error {
+	token, err := uc.actionTokens.Issue(models.ActionEmailVerification, user.ID, map[string]string{"email": user.Email}, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	if err := uc.emailSvc.SendVerificationEmail(user.Email, token); err != nil {
+		return err
+	}
+	return uc.userRepo.SetVerificationSentAt(user.ID, time.Now())
 }
 
 // authenticates a user
@@ -105,19 +194,78 @@ func (uc *UserUseCase) LoginUser(emailOrUsername, password string) (*models.User
 	}
 
 	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
+		return nil, usecases.ErrAccountDeactivated
 	}
 
 	if !uc.passwordSvc.CheckPasswordHash(password, user.Password) {
 		return nil, errors.New("invalid credentials")
 	}
+	if uc.passwordExpired(user) {
+		return nil, usecases.ErrPasswordExpired
+	}
 
 	return user, nil
 }
 
-// handles user logout business logic
-func (uc *UserUseCase) LogoutUser(userID string) error {
-	return uc.tokenUseCase.RevokeAllUserTokens(userID)
+// accounts from before passwords were dated count from their creation
+func (uc *UserUseCase) passwordExpired(user *models.User) bool {
+	if uc.passwordPolicy.MaxAge <= 0 {
+		return false
+	}
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return time.Since(changedAt) > uc.passwordPolicy.MaxAge
+}
+
+// hashes and stores a new password, refusing the user's last HistorySize passwords
+func (uc *UserUseCase) setPassword(user *models.User, password string) error {
+	keep := uc.passwordPolicy.HistorySize - 1
+	if uc.passwordPolicy.HistorySize > 0 {
+		recent := user.PasswordHistory
+		if len(recent) > keep {
+			recent = recent[:keep]
+		}
+		for _, hash := range append([]string{user.Password}, recent...) {
+			if hash != "" && uc.passwordSvc.CheckPasswordHash(password, hash) {
+				return usecases.ErrPasswordReused
+			}
+		}
+	}
+
+	hashedPassword, err := uc.passwordSvc.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	var history []string
+	if keep > 0 {
+		if user.Password != "" {
+			history = append(history, user.Password)
+		}
+		history = append(history, user.PasswordHistory...)
+		if len(history) > keep {
+			history = history[:keep]
+		}
+	}
+	now := time.Now()
+	user.Password = hashedPassword
+	user.PasswordHistory = history
+	user.PasswordChangedAt = &now
+	return nil
+}
+
+// signs out the session the access token belongs to; tokens from before sessions existed sign out everywhere
+func (uc *UserUseCase) LogoutUser(userID, sessionID string) error {
+	if sessionID == "" {
+		return uc.tokenUseCase.RevokeAllUserTokens(userID)
+	}
+	err := uc.tokenUseCase.RevokeSession(userID, sessionID)
+	if errors.Is(err, usecases.ErrSessionNotFound) {
+		return nil
+	}
+	return err
 }
 //initiates the password reset process
 func (uc *UserUseCase) ForgotPassword(email string) error {
@@ -133,7 +281,7 @@ func (uc *UserUseCase) ForgotPassword(email string) error {
 	}
 
 	if !user.IsActive {
-		return errors.New("account is deactivated")
+		return usecases.ErrAccountDeactivated
 	}
 
 	resetToken, err := generateResetToken()
@@ -155,117 +303,264 @@ func (uc *UserUseCase) ForgotPassword(email string) error {
 }
 
 // resets the user's password using the reset token
- func (uc *UserUseCase) ResetPassword(token, newPassword string) error {
-  // Validate new password strength
-  if err := uc.validationSvc.ValidatePassword(newPassword); err != nil {
-    return err
-  }
-
+ func (uc *UserUseCase) ResetPassword(token, newPassword string) (*models.User, error) {
   // Get user by reset token
   user, err := uc.userRepo.GetUserByResetToken(token)
   if err != nil {
-    return err
+    return nil, usecases.ErrInvalidResetToken
   }
 
   // Check if reset token is expired
   if user.ResetPasswordExpires == nil || time.Now().After(*user.ResetPasswordExpires) {
-    return errors.New("reset token has expired")
+    return nil, usecases.ErrInvalidResetToken
   }
 
-  // Hash new password
-  hashedPassword, err := uc.passwordSvc.HashPassword(newPassword)
-  if err != nil {
-    return err
+  // Validate new password against the policy and the user's recent passwords
+  if err := uc.validationSvc.ValidatePassword(newPassword, user.Username, user.Email, user.FirstName, user.LastName); err != nil {
+    return nil, err
+  }
+  if err := uc.setPassword(user, newPassword); err != nil {
+    return nil, err
   }
 
-  // Update user password and clear reset token
-  user.Password = hashedPassword
+  // Clear reset token
   user.ResetPasswordToken = ""
   user.ResetPasswordExpires = nil
   user.UpdatedAt = time.Now()
 
   // Update user in database
   if err := uc.userRepo.UpdateUser(user); err != nil {
-    return err
+    return nil, err
   }
 
   // Verify the password was updated correctly by retrieving the user
   updatedUser, err := uc.userRepo.GetUserByEmail(user.Email)
   if err != nil {
-    return errors.New("failed to verify password update")
+    return nil, errors.New("failed to verify password update")
   }
 
   // Verify the password hash matches
   if !uc.passwordSvc.CheckPasswordHash(newPassword, updatedUser.Password) {
-    return errors.New("password update verification failed")
+    return nil, errors.New("password update verification failed")
   }
 
   if err := uc.tokenUseCase.RevokeAllUserTokens(user.ID); err != nil {
-    return err
+    return nil, err
   }
 
-  return uc.emailSvc.SendPasswordChangedEmail(user.Email)
+  if err := uc.emailSvc.SendPasswordChangedEmail(user.Email); err != nil {
+    return nil, err
+  }
+  return user, nil
 }
 
-//generates a secure random token for password reset
-func generateResetToken() (string, error) {
-	bytes := make([]byte, 32) 
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
+// changes the signed-in user's password, or sets a first one on accounts created without it
+func (uc *UserUseCase) ChangePassword(userID, sessionID, currentPassword, newPassword string) (bool, error) {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return false, usecases.ErrUserNotFound
+	}
+	// OAuth and magic link accounts have no password to confirm
+	initial := user.Password == ""
+	if !initial && !uc.passwordSvc.CheckPasswordHash(currentPassword, user.Password) {
+		return false, usecases.ErrInvalidPassword
+	}
+	if err := uc.validationSvc.ValidatePassword(newPassword, user.Username, user.Email, user.FirstName, user.LastName); err != nil {
+		return false, err
+	}
+	if err := uc.setPassword(user, newPassword); err != nil {
+		return false, err
+	}
+
+	// a pending reset link would otherwise undo the change
+	user.ResetPasswordToken = ""
+	user.ResetPasswordExpires = nil
+	user.UpdatedAt = time.Now()
+	if err := uc.userRepo.UpdateUser(user); err != nil {
+		return false, err
+	}
+
+	// tokens from before sessions existed cannot tell devices apart, so they all go
+	if sessionID == "" {
+		err = uc.tokenUseCase.RevokeAllUserTokens(user.ID)
+	} else {
+		_, err = uc.tokenUseCase.RevokeOtherSessions(user.ID, sessionID)
+	}
+	if err != nil {
+		return false, err
 	}
-	return hex.EncodeToString(bytes), nil
-}
 
-// promotes a user to admin 
-func (uc *UserUseCase) PromoteUser(adminID, targetUserID string) error {
-	if adminID == targetUserID {
-		return errors.New("admin cannot promote themselves")
+	// the password is already changed; a lost notice must not report otherwise
+	if err := uc.emailSvc.SendPasswordChangedEmail(user.Email); err != nil {
+		log.Printf("failed to send password changed email to %s: %v", user.ID, err)
 	}
-	admin, err := uc.userRepo.GetUserByID(adminID)
+	return initial, nil
+}
+
+// emails a confirmation link to the new address; nothing changes until it is followed.
+// Accounts without a password first approve the change from their current address, reported by the bool.
+func (uc *UserUseCase) RequestEmailChange(userID, currentPassword, newEmail string) (bool, error) {
+	user, err := uc.userRepo.GetUserByID(userID)
 	if err != nil {
-		return errors.New("acting admin not found")
+		return false, usecases.ErrUserNotFound
+	}
+	if user.Password != "" && !uc.passwordSvc.CheckPasswordHash(currentPassword, user.Password) {
+		return false, usecases.ErrInvalidPassword
+	}
+	newEmail = strings.TrimSpace(newEmail)
+	if err := uc.validationSvc.ValidateEmail(newEmail); err != nil {
+		return false, err
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return false, usecases.ErrSameEmail
+	}
+	if err := uc.checkEmailFree(newEmail); err != nil {
+		return false, err
 	}
-	if admin.RoleID != "admin" {
-		return errors.New("only admins can promote users")
+
+	// OAuth and magic link accounts have no password, so a stolen session alone must not move them
+	if user.Password == "" {
+		token, err := uc.actionTokens.Issue(models.ActionEmailChangeApproval, user.ID, map[string]string{
+			"email":      user.Email,
+			"new_email":  newEmail,
+			"changed_at": emailChangeStamp(user),
+		}, emailApprovalTTL)
+		if err != nil {
+			return false, err
+		}
+		return true, uc.emailSvc.SendEmailChangeApprovalEmail(user.Email, newEmail, token, emailApprovalTTL)
 	}
-	user, err := uc.userRepo.GetUserByID(targetUserID)
+	return false, uc.sendEmailChangeConfirmation(user, newEmail)
+}
+
+// turns an approval from the current address into a confirmation link for the new one
+func (uc *UserUseCase) ApproveEmailChange(token string) (*models.EmailChange, error) {
+	claims, err := uc.actionTokens.Verify(models.ActionEmailChangeApproval, token)
 	if err != nil {
-		return errors.New("target user not found")
+		return nil, usecases.ErrInvalidEmailChangeToken
 	}
-	if user.RoleID == "admin" {
-		return errors.New("user is already an admin")
+	change := &models.EmailChange{UserID: claims.Subject, OldEmail: claims.Data["email"], NewEmail: claims.Data["new_email"]}
+	user, err := uc.userRepo.GetUserByID(change.UserID)
+	if err != nil || user.Email != change.OldEmail || emailChangeStamp(user) != claims.Data["changed_at"] {
+		return nil, usecases.ErrInvalidEmailChangeToken
+	}
+	if err := uc.checkEmailFree(change.NewEmail); err != nil {
+		return nil, err
+	}
+	if err := uc.sendEmailChangeConfirmation(user, change.NewEmail); err != nil {
+		return nil, err
 	}
-	return uc.userRepo.UpdateUserRole(targetUserID, "admin")
+	return change, nil
 }
 
-// demotes an admin to user
-func (uc *UserUseCase) DemoteUser(adminID, targetUserID string) error {
-	if adminID == targetUserID {
-		return errors.New("admin cannot demote themselves")
+func (uc *UserUseCase) sendEmailChangeConfirmation(user *models.User, newEmail string) error {
+	token, err := uc.actionTokens.Issue(models.ActionEmailChange, user.ID, map[string]string{
+		"email":      user.Email,
+		"new_email":  newEmail,
+		"changed_at": emailChangeStamp(user),
+	}, emailVerificationTTL)
+	if err != nil {
+		return err
 	}
-	admin, err := uc.userRepo.GetUserByID(adminID)
+	return uc.emailSvc.SendEmailChangeConfirmEmail(newEmail, token)
+}
+
+// moves the user to the confirmed address and sends the old one a link to undo it
+func (uc *UserUseCase) ConfirmEmailChange(token string) (*models.EmailChange, error) {
+	claims, err := uc.actionTokens.Verify(models.ActionEmailChange, token)
 	if err != nil {
-		return errors.New("acting admin not found")
+		return nil, usecases.ErrInvalidEmailChangeToken
 	}
-	if admin.RoleID != "admin" {
-		return errors.New("only admins can demote users")
+	change := &models.EmailChange{UserID: claims.Subject, OldEmail: claims.Data["email"], NewEmail: claims.Data["new_email"]}
+	if err := uc.switchEmail(change.UserID, change.OldEmail, change.NewEmail, claims.Data["changed_at"]); err != nil {
+		return nil, err
 	}
-	user, err := uc.userRepo.GetUserByID(targetUserID)
+
+	user, err := uc.userRepo.GetUserByID(change.UserID)
 	if err != nil {
-		return errors.New("target user not found")
+		return nil, err
 	}
-	if user.RoleID != "admin" {
-		return errors.New("user is not an admin")
+	revertToken, err := uc.actionTokens.Issue(models.ActionEmailRevert, user.ID, map[string]string{
+		"email":      change.NewEmail,
+		"old_email":  change.OldEmail,
+		"changed_at": emailChangeStamp(user),
+	}, emailRevertTTL)
+	if err == nil {
+		err = uc.emailSvc.SendEmailChangedEmail(change.OldEmail, change.NewEmail, revertToken, emailRevertTTL)
 	}
-	adminCount, err := uc.userRepo.GetAdminCount()
 	if err != nil {
-		return errors.New("failed to count admins")
+		// the change is already made; the user can still see it in their profile
+		log.Printf("failed to send email change notice to %s: %v", user.ID, err)
 	}
-	if adminCount <= 1 {
-		return errors.New("cannot demote the last admin")
+	return change, nil
+}
+
+// puts back the address a confirmed change replaced, for owners who did not make it
+func (uc *UserUseCase) RevertEmailChange(token string) (*models.EmailChange, error) {
+	claims, err := uc.actionTokens.Verify(models.ActionEmailRevert, token)
+	if err != nil {
+		return nil, usecases.ErrInvalidEmailChangeToken
+	}
+	change := &models.EmailChange{UserID: claims.Subject, OldEmail: claims.Data["email"], NewEmail: claims.Data["old_email"]}
+	if err := uc.switchEmail(change.UserID, change.OldEmail, change.NewEmail, claims.Data["changed_at"]); err != nil {
+		return nil, err
+	}
+	// whoever made the change may still be signed in
+	if err := uc.tokenUseCase.RevokeAllUserTokens(change.UserID); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// changes the address if the user still has fromEmail and no change happened since the link was issued
+func (uc *UserUseCase) switchEmail(userID, fromEmail, toEmail, stamp string) error {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil || user.Email != fromEmail || emailChangeStamp(user) != stamp {
+		return usecases.ErrInvalidEmailChangeToken
 	}
-	return uc.userRepo.UpdateUserRole(targetUserID, "user")
+	// the address may have been taken since the link was sent
+	if err := uc.checkEmailFree(toEmail); err != nil {
+		return err
+	}
+	changed, err := uc.userRepo.ChangeEmail(user.ID, fromEmail, toEmail, time.Now())
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return usecases.ErrInvalidEmailChangeToken
+	}
+	return nil
 }
+
+func (uc *UserUseCase) checkEmailFree(email string) error {
+	exists, err := uc.userRepo.CheckEmailExists(email)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return usecases.ErrEmailTaken
+	}
+	return nil
+}
+
+// identifies the user's last email change, so each link works once and only until the next change
+func emailChangeStamp(user *models.User) string {
+	if user.EmailChangedAt == nil {
+		return ""
+	}
+	// milliseconds, the precision the time survives storage with
+	return strconv.FormatInt(user.EmailChangedAt.UnixMilli(), 10)
+}
+
+//generates a secure random token for password reset
+func generateResetToken() (string, error) {
+	bytes := make([]byte, 32) 
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
 func (uc *UserUseCase) UpdateUserProfile(userID string, update *models.UserProfileUpdate) (*models.User, error) {
 	// Validate user exists
 	user, err := uc.userRepo.GetUserByID(userID)